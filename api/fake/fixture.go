@@ -0,0 +1,355 @@
+package fake
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Fixture is the PagerDuty account served by the fake server, loaded from a YAML or JSON file.
+type Fixture struct {
+	// AuthToken, when set, is the only token accepted by the server
+	AuthToken          string                    `yaml:"authToken"`
+	PageSize           uint                      `yaml:"pageSize"`
+	Teams              []FixtureTeam             `yaml:"teams"`
+	Users              []FixtureUser             `yaml:"users"`
+	EscalationPolicies []FixtureEscalationPolicy `yaml:"escalationPolicies"`
+	Services           []FixtureService          `yaml:"services"`
+	Schedules          []FixtureSchedule         `yaml:"schedules"`
+	Incidents          []FixtureIncident         `yaml:"incidents"`
+}
+
+type FixtureTeam struct {
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+}
+
+type FixtureUser struct {
+	ID       string   `yaml:"id"`
+	Name     string   `yaml:"name"`
+	Email    string   `yaml:"email"`
+	Timezone string   `yaml:"timezone"`
+	Teams    []string `yaml:"teams"`
+}
+
+// FixtureEscalationPolicy pages the given schedules.
+type FixtureEscalationPolicy struct {
+	ID        string   `yaml:"id"`
+	Name      string   `yaml:"name"`
+	Schedules []string `yaml:"schedules"`
+}
+
+type FixtureService struct {
+	ID               string   `yaml:"id"`
+	Name             string   `yaml:"name"`
+	Teams            []string `yaml:"teams"`
+	EscalationPolicy string   `yaml:"escalationPolicy"`
+}
+
+// FixtureSchedule is rendered from its rotation, repeated forever, and its explicit entries.
+// Overrides replace whoever is on call during their period in the final schedule.
+type FixtureSchedule struct {
+	ID        string                 `yaml:"id"`
+	Name      string                 `yaml:"name"`
+	Timezone  string                 `yaml:"timezone"`
+	Teams     []string               `yaml:"teams"`
+	Rotation  *FixtureRotation       `yaml:"rotation"`
+	Entries   []FixtureScheduleEntry `yaml:"entries"`
+	Overrides []FixtureScheduleEntry `yaml:"overrides"`
+
+	location  *time.Location
+	entries   []scheduleEntry
+	overrides []scheduleEntry
+}
+
+// FixtureRotation hands over to the next user every TurnLength (a Go duration such as 168h) from Start.
+type FixtureRotation struct {
+	Start      string   `yaml:"start"`
+	TurnLength string   `yaml:"turnLength"`
+	Users      []string `yaml:"users"`
+
+	start      time.Time
+	turnLength time.Duration
+}
+
+// FixtureScheduleEntry is an on-call period, dates are RFC3339.
+type FixtureScheduleEntry struct {
+	User  string `yaml:"user"`
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+// FixtureIncident is an incident of a service, created when its first log entry happened.
+type FixtureIncident struct {
+	ID         string            `yaml:"id"`
+	Number     uint              `yaml:"number"`
+	Title      string            `yaml:"title"`
+	Service    string            `yaml:"service"`
+	Urgency    string            `yaml:"urgency"`
+	LogEntries []FixtureLogEntry `yaml:"logEntries"`
+
+	createdAt time.Time
+}
+
+// FixtureLogEntry is an event of an incident: trigger, notify, acknowledge, escalate or resolve.
+// User is the user notified, or the user acknowledging or resolving the incident.
+type FixtureLogEntry struct {
+	Type string `yaml:"type"`
+	At   string `yaml:"at"`
+	User string `yaml:"user"`
+
+	at time.Time
+}
+
+type scheduleEntry struct {
+	user  string
+	start time.Time
+	end   time.Time
+}
+
+// LoadFixture reads and checks a fixture file.
+func LoadFixture(path string) (*Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture file: %w", err)
+	}
+
+	fixture := &Fixture{}
+	if err := yaml.Unmarshal(content, fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture file '%s': %w", path, err)
+	}
+	if err := fixture.prepare(); err != nil {
+		return nil, fmt.Errorf("invalid fixture file '%s': %w", path, err)
+	}
+
+	return fixture, nil
+}
+
+func (f *Fixture) prepare() error {
+	if f.PageSize == 0 {
+		f.PageSize = 25
+	}
+
+	users := make(map[string]bool)
+	for _, user := range f.Users {
+		users[user.ID] = true
+	}
+
+	schedules := make(map[string]bool)
+	for _, schedule := range f.Schedules {
+		schedules[schedule.ID] = true
+	}
+	escalationPolicies := make(map[string]bool)
+	for _, escalationPolicy := range f.EscalationPolicies {
+		escalationPolicies[escalationPolicy.ID] = true
+		for _, schedule := range escalationPolicy.Schedules {
+			if !schedules[schedule] {
+				return fmt.Errorf("escalation policy %s has unknown schedule %s", escalationPolicy.ID, schedule)
+			}
+		}
+	}
+
+	services := make(map[string]bool)
+	for _, service := range f.Services {
+		services[service.ID] = true
+		if service.EscalationPolicy != "" && !escalationPolicies[service.EscalationPolicy] {
+			return fmt.Errorf("service %s has unknown escalation policy %s", service.ID, service.EscalationPolicy)
+		}
+	}
+	for i := range f.Incidents {
+		if err := f.Incidents[i].prepare(users, services); err != nil {
+			return err
+		}
+	}
+
+	for i := range f.Schedules {
+		schedule := &f.Schedules[i]
+		if schedule.Timezone == "" {
+			schedule.Timezone = "UTC"
+		}
+		location, err := time.LoadLocation(schedule.Timezone)
+		if err != nil {
+			return fmt.Errorf("schedule %s: %w", schedule.ID, err)
+		}
+		schedule.location = location
+
+		if rotation := schedule.Rotation; rotation != nil {
+			if rotation.start, err = time.Parse(time.RFC3339, rotation.Start); err != nil {
+				return fmt.Errorf("schedule %s rotation start: %w", schedule.ID, err)
+			}
+			if rotation.turnLength, err = time.ParseDuration(rotation.TurnLength); err != nil || rotation.turnLength <= 0 {
+				return fmt.Errorf("schedule %s rotation turnLength '%s' is not a positive duration", schedule.ID, rotation.TurnLength)
+			}
+			if len(rotation.Users) == 0 {
+				return fmt.Errorf("schedule %s rotation has no users", schedule.ID)
+			}
+			for _, user := range rotation.Users {
+				if !users[user] {
+					return fmt.Errorf("schedule %s rotation has unknown user %s", schedule.ID, user)
+				}
+			}
+		}
+
+		if schedule.entries, err = parseEntries(schedule.ID, "entries", schedule.Entries, users); err != nil {
+			return err
+		}
+		if schedule.overrides, err = parseEntries(schedule.ID, "overrides", schedule.Overrides, users); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (incident *FixtureIncident) prepare(users, services map[string]bool) error {
+	if !services[incident.Service] {
+		return fmt.Errorf("incident %s has unknown service %s", incident.ID, incident.Service)
+	}
+	if incident.Urgency == "" {
+		incident.Urgency = "high"
+	}
+	if len(incident.LogEntries) == 0 {
+		return fmt.Errorf("incident %s has no log entries", incident.ID)
+	}
+
+	for j := range incident.LogEntries {
+		logEntry := &incident.LogEntries[j]
+		switch logEntry.Type {
+		case "trigger", "notify", "acknowledge", "escalate", "resolve":
+		default:
+			return fmt.Errorf("incident %s logEntries[%d] has unknown type '%s'", incident.ID, j, logEntry.Type)
+		}
+		if logEntry.User != "" && !users[logEntry.User] {
+			return fmt.Errorf("incident %s logEntries[%d] has unknown user %s", incident.ID, j, logEntry.User)
+		}
+		at, err := time.Parse(time.RFC3339, logEntry.At)
+		if err != nil {
+			return fmt.Errorf("incident %s logEntries[%d].at: %w", incident.ID, j, err)
+		}
+		logEntry.at = at
+		if j == 0 || at.Before(incident.createdAt) {
+			incident.createdAt = at
+		}
+	}
+
+	sort.SliceStable(incident.LogEntries, func(i, j int) bool {
+		return incident.LogEntries[i].at.Before(incident.LogEntries[j].at)
+	})
+	return nil
+}
+
+// status is the status of the incident after all its log entries.
+func (incident *FixtureIncident) status() string {
+	status := "triggered"
+	for _, logEntry := range incident.LogEntries {
+		switch logEntry.Type {
+		case "acknowledge":
+			status = "acknowledged"
+		case "resolve":
+			return "resolved"
+		}
+	}
+	return status
+}
+
+func parseEntries(scheduleID, field string, fixtureEntries []FixtureScheduleEntry, users map[string]bool) ([]scheduleEntry, error) {
+	entries := make([]scheduleEntry, 0, len(fixtureEntries))
+	for j, entry := range fixtureEntries {
+		if !users[entry.User] {
+			return nil, fmt.Errorf("schedule %s %s[%d] has unknown user %s", scheduleID, field, j, entry.User)
+		}
+		start, err := time.Parse(time.RFC3339, entry.Start)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s %s[%d].start: %w", scheduleID, field, j, err)
+		}
+		end, err := time.Parse(time.RFC3339, entry.End)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s %s[%d].end: %w", scheduleID, field, j, err)
+		}
+		entries = append(entries, scheduleEntry{user: entry.User, start: start, end: end})
+	}
+	return entries, nil
+}
+
+// render returns the on-call periods of the layer overlapping [since, until), cut to that range.
+func (s *FixtureSchedule) render(since, until time.Time) []scheduleEntry {
+	var rendered []scheduleEntry
+	if rotation := s.Rotation; rotation != nil {
+		turn := int64(0)
+		if since.After(rotation.start) {
+			turn = int64(since.Sub(rotation.start) / rotation.turnLength)
+		}
+		for ; ; turn++ {
+			start := rotation.start.Add(time.Duration(turn) * rotation.turnLength)
+			if !start.Before(until) {
+				break
+			}
+			rendered = append(rendered, scheduleEntry{
+				user:  rotation.Users[turn%int64(len(rotation.Users))],
+				start: start,
+				end:   start.Add(rotation.turnLength),
+			})
+		}
+	}
+	rendered = append(rendered, s.entries...)
+
+	return clip(rendered, since, until)
+}
+
+// renderFinal returns the rendered layer with the overrides applied over it.
+func (s *FixtureSchedule) renderFinal(since, until time.Time) []scheduleEntry {
+	final := make([]scheduleEntry, 0)
+	for _, entry := range s.render(since, until) {
+		pieces := []scheduleEntry{entry}
+		for _, override := range s.overrides {
+			pieces = cut(pieces, override.start, override.end)
+		}
+		final = append(final, pieces...)
+	}
+	final = append(final, s.overrides...)
+
+	return clip(final, since, until)
+}
+
+// cut removes [start, end) from the entries.
+func cut(entries []scheduleEntry, start, end time.Time) []scheduleEntry {
+	result := make([]scheduleEntry, 0, len(entries)+1)
+	for _, entry := range entries {
+		if !entry.start.Before(end) || !start.Before(entry.end) {
+			result = append(result, entry)
+			continue
+		}
+		if entry.start.Before(start) {
+			result = append(result, scheduleEntry{user: entry.user, start: entry.start, end: start})
+		}
+		if end.Before(entry.end) {
+			result = append(result, scheduleEntry{user: entry.user, start: end, end: entry.end})
+		}
+	}
+	return result
+}
+
+// clip keeps the entries overlapping [since, until), cut to that range and sorted by start.
+func clip(entries []scheduleEntry, since, until time.Time) []scheduleEntry {
+	result := make([]scheduleEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.start.Before(until) || !since.Before(entry.end) {
+			continue
+		}
+		if entry.start.Before(since) {
+			entry.start = since
+		}
+		if entry.end.After(until) {
+			entry.end = until
+		}
+		result = append(result, entry)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].start.Before(result[j].start)
+	})
+
+	return result
+}
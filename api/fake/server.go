@@ -0,0 +1,497 @@
+// Package fake serves the subset of the PagerDuty REST API used by the api package from a fixture,
+// to run the tool without a PagerDuty account.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+const maxPageSize = 100
+
+// Server is an http.Handler answering like the PagerDuty REST API.
+type Server struct {
+	fixture *Fixture
+	mux     *http.ServeMux
+}
+
+func NewServer(fixture *Fixture) *Server {
+	s := &Server{
+		fixture: fixture,
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/teams", s.listTeams)
+	s.mux.HandleFunc("/users", s.listUsers)
+	s.mux.HandleFunc("/users/", s.getUser)
+	s.mux.HandleFunc("/services", s.listServices)
+	s.mux.HandleFunc("/schedules", s.listSchedules)
+	s.mux.HandleFunc("/schedules/", s.getSchedule)
+	s.mux.HandleFunc("/incidents", s.listIncidents)
+	s.mux.HandleFunc("/incidents/", s.listIncidentLogEntries)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Token token=")
+	if token == "" || (s.fixture.AuthToken != "" && token != s.fixture.AuthToken) {
+		writeError(w, http.StatusUnauthorized, 2006, "Unauthorized")
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, 2000, "Method not allowed")
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) listTeams(w http.ResponseWriter, r *http.Request) {
+	teams := make([]pagerduty.Team, 0, len(s.fixture.Teams))
+	for _, team := range s.fixture.Teams {
+		teams = append(teams, s.team(team.ID))
+	}
+
+	offset, end, ok := s.page(w, r, len(teams))
+	if ok {
+		writeList(w, "teams", teams[offset:end], offset, end-offset, len(teams))
+	}
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	users := make([]pagerduty.User, 0, len(s.fixture.Users))
+	for _, user := range s.fixture.Users {
+		users = append(users, s.user(user))
+	}
+
+	offset, end, ok := s.page(w, r, len(users))
+	if ok {
+		writeList(w, "users", users[offset:end], offset, end-offset, len(users))
+	}
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/users/")
+	for _, user := range s.fixture.Users {
+		if user.ID == id {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"user": s.user(user)})
+			return
+		}
+	}
+
+	writeError(w, http.StatusNotFound, 2100, "Not Found")
+}
+
+func (s *Server) listServices(w http.ResponseWriter, r *http.Request) {
+	teamIDs := r.URL.Query()["team_ids[]"]
+
+	services := make([]pagerduty.Service, 0, len(s.fixture.Services))
+	for _, service := range s.fixture.Services {
+		if len(teamIDs) > 0 && !inAny(service.Teams, teamIDs) {
+			continue
+		}
+		apiService := pagerduty.Service{
+			APIObject: apiObject(service.ID, "service", service.Name),
+			Name:      service.Name,
+			Teams:     s.teamList(service.Teams),
+		}
+		if service.EscalationPolicy != "" {
+			apiService.EscalationPolicy = pagerduty.EscalationPolicy{
+				APIObject: apiObject(service.EscalationPolicy, "escalation_policy_reference", s.escalationPolicyName(service.EscalationPolicy)),
+			}
+		}
+		services = append(services, apiService)
+	}
+
+	offset, end, ok := s.page(w, r, len(services))
+	if ok {
+		writeList(w, "services", services[offset:end], offset, end-offset, len(services))
+	}
+}
+
+func (s *Server) listSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules := make([]pagerduty.Schedule, 0, len(s.fixture.Schedules))
+	for i := range s.fixture.Schedules {
+		schedules = append(schedules, s.schedule(&s.fixture.Schedules[i]))
+	}
+
+	offset, end, ok := s.page(w, r, len(schedules))
+	if ok {
+		writeList(w, "schedules", schedules[offset:end], offset, end-offset, len(schedules))
+	}
+}
+
+func (s *Server) getSchedule(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/schedules/")
+	if strings.HasSuffix(id, "/overrides") {
+		s.listOverrides(w, r, strings.TrimSuffix(id, "/overrides"))
+		return
+	}
+
+	for i := range s.fixture.Schedules {
+		fixtureSchedule := &s.fixture.Schedules[i]
+		if fixtureSchedule.ID != id {
+			continue
+		}
+
+		schedule := s.schedule(fixtureSchedule)
+		query := r.URL.Query()
+		if query.Get("since") != "" && query.Get("until") != "" {
+			since, err := parseQueryTime(query.Get("since"), fixtureSchedule.location)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, 2001, "Invalid since: "+err.Error())
+				return
+			}
+			until, err := parseQueryTime(query.Get("until"), fixtureSchedule.location)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, 2001, "Invalid until: "+err.Error())
+				return
+			}
+			schedule.FinalSchedule = s.renderedLayer("Final Schedule", fixtureSchedule.location, fixtureSchedule.renderFinal(since, until), since, until)
+			schedule.ScheduleLayers = []pagerduty.ScheduleLayer{
+				s.renderedLayer("Layer 1", fixtureSchedule.location, fixtureSchedule.render(since, until), since, until),
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"schedule": schedule})
+		return
+	}
+
+	writeError(w, http.StatusNotFound, 2100, "Not Found")
+}
+
+// listOverrides answers with the overrides of the schedule overlapping the since and until parameters.
+func (s *Server) listOverrides(w http.ResponseWriter, r *http.Request, id string) {
+	for i := range s.fixture.Schedules {
+		fixtureSchedule := &s.fixture.Schedules[i]
+		if fixtureSchedule.ID != id {
+			continue
+		}
+
+		query := r.URL.Query()
+		since, err := parseQueryTime(query.Get("since"), fixtureSchedule.location)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 2001, "Invalid since: "+err.Error())
+			return
+		}
+		until, err := parseQueryTime(query.Get("until"), fixtureSchedule.location)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, 2001, "Invalid until: "+err.Error())
+			return
+		}
+
+		overrides := make([]pagerduty.Override, 0)
+		for j, override := range fixtureSchedule.overrides {
+			if !override.start.Before(until) || !since.Before(override.end) {
+				continue
+			}
+			overrides = append(overrides, pagerduty.Override{
+				ID:    fmt.Sprintf("%s-OVERRIDE%d", fixtureSchedule.ID, j+1),
+				Type:  "override",
+				Start: override.start.In(fixtureSchedule.location).Format(time.RFC3339),
+				End:   override.end.In(fixtureSchedule.location).Format(time.RFC3339),
+				User:  apiObject(override.user, "user_reference", s.userName(override.user)),
+			})
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"overrides": overrides})
+		return
+	}
+
+	writeError(w, http.StatusNotFound, 2100, "Not Found")
+}
+
+func (s *Server) renderedLayer(name string, location *time.Location, entries []scheduleEntry, since, until time.Time) pagerduty.ScheduleLayer {
+	rendered := make([]pagerduty.RenderedScheduleEntry, 0, len(entries))
+	var covered time.Duration
+	for _, entry := range entries {
+		rendered = append(rendered, pagerduty.RenderedScheduleEntry{
+			Start: entry.start.In(location).Format(time.RFC3339),
+			End:   entry.end.In(location).Format(time.RFC3339),
+			User:  apiObject(entry.user, "user_reference", s.userName(entry.user)),
+		})
+		covered += entry.end.Sub(entry.start)
+	}
+
+	layer := pagerduty.ScheduleLayer{
+		Name:                    name,
+		RenderedScheduleEntries: rendered,
+	}
+	if until.After(since) {
+		layer.RenderedCoveragePercentage = 100 * float64(covered) / float64(until.Sub(since))
+	}
+	return layer
+}
+
+// listIncidents answers with the incidents created between the since and until parameters.
+func (s *Server) listIncidents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	since, err := parseQueryTime(query.Get("since"), time.UTC)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 2001, "Invalid since: "+err.Error())
+		return
+	}
+	until, err := parseQueryTime(query.Get("until"), time.UTC)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 2001, "Invalid until: "+err.Error())
+		return
+	}
+
+	serviceIDs := query["service_ids[]"]
+
+	incidents := make([]pagerduty.Incident, 0, len(s.fixture.Incidents))
+	for i := range s.fixture.Incidents {
+		incident := &s.fixture.Incidents[i]
+		if incident.createdAt.Before(since) || !incident.createdAt.Before(until) {
+			continue
+		}
+		if len(serviceIDs) > 0 && !inAny([]string{incident.Service}, serviceIDs) {
+			continue
+		}
+		incidents = append(incidents, pagerduty.Incident{
+			APIObject:      apiObject(incident.ID, "incident", incident.Title),
+			IncidentNumber: incident.Number,
+			Title:          incident.Title,
+			CreatedAt:      incident.createdAt.Format(time.RFC3339),
+			Service:        apiObject(incident.Service, "service_reference", s.serviceName(incident.Service)),
+			Urgency:        incident.Urgency,
+			Status:         incident.status(),
+		})
+	}
+
+	offset, end, ok := s.page(w, r, len(incidents))
+	if ok {
+		writeList(w, "incidents", incidents[offset:end], offset, end-offset, len(incidents))
+	}
+}
+
+// listIncidentLogEntries answers with the log entries of an incident, newest first like PagerDuty.
+func (s *Server) listIncidentLogEntries(w http.ResponseWriter, r *http.Request) {
+	id, found := cutSuffix(strings.TrimPrefix(r.URL.Path, "/incidents/"), "/log_entries")
+	if !found {
+		writeError(w, http.StatusNotFound, 2100, "Not Found")
+		return
+	}
+
+	for i := range s.fixture.Incidents {
+		incident := &s.fixture.Incidents[i]
+		if incident.ID != id {
+			continue
+		}
+
+		logEntries := make([]pagerduty.LogEntry, 0, len(incident.LogEntries))
+		for j := len(incident.LogEntries) - 1; j >= 0; j-- {
+			logEntries = append(logEntries, s.logEntry(incident, j))
+		}
+
+		offset, end, ok := s.page(w, r, len(logEntries))
+		if ok {
+			writeList(w, "log_entries", logEntries[offset:end], offset, end-offset, len(logEntries))
+		}
+		return
+	}
+
+	writeError(w, http.StatusNotFound, 2100, "Not Found")
+}
+
+func (s *Server) logEntry(incident *FixtureIncident, index int) pagerduty.LogEntry {
+	fixtureLogEntry := incident.LogEntries[index]
+	logEntry := pagerduty.LogEntry{
+		CommonLogEntryField: pagerduty.CommonLogEntryField{
+			APIObject: apiObject(fmt.Sprintf("%s-LOG%d", incident.ID, index+1), fixtureLogEntry.Type+"_log_entry", ""),
+			CreatedAt: fixtureLogEntry.at.Format(time.RFC3339),
+			Agent:     pagerduty.Agent(apiObject(incident.Service, "service_reference", s.serviceName(incident.Service))),
+		},
+		Incident: pagerduty.Incident{APIObject: apiObject(incident.ID, "incident_reference", incident.Title)},
+		Service:  apiObject(incident.Service, "service_reference", s.serviceName(incident.Service)),
+	}
+
+	if fixtureLogEntry.User != "" {
+		user := apiObject(fixtureLogEntry.User, "user_reference", s.userName(fixtureLogEntry.User))
+		if fixtureLogEntry.Type == "notify" {
+			logEntry.User = user
+		} else {
+			logEntry.Agent = pagerduty.Agent(user)
+		}
+	}
+	return logEntry
+}
+
+// page reads the offset and limit query parameters, returning the bounds of the requested page.
+func (s *Server) page(w http.ResponseWriter, r *http.Request, total int) (int, int, bool) {
+	offset, err := queryUint(r, "offset", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, 2001, "Invalid offset")
+		return 0, 0, false
+	}
+	limit, err := queryUint(r, "limit", s.fixture.PageSize)
+	if err != nil || limit == 0 {
+		writeError(w, http.StatusBadRequest, 2001, "Invalid limit")
+		return 0, 0, false
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	start := int(offset)
+	if start > total {
+		start = total
+	}
+	end := start + int(limit)
+	if end > total {
+		end = total
+	}
+	return start, end, true
+}
+
+func (s *Server) user(user FixtureUser) pagerduty.User {
+	return pagerduty.User{
+		APIObject: apiObject(user.ID, "user", user.Name),
+		Name:      user.Name,
+		Email:     user.Email,
+		Timezone:  user.Timezone,
+		Teams:     s.teamList(user.Teams),
+	}
+}
+
+func (s *Server) userName(id string) string {
+	for _, user := range s.fixture.Users {
+		if user.ID == id {
+			return user.Name
+		}
+	}
+	return id
+}
+
+func (s *Server) serviceName(id string) string {
+	for _, service := range s.fixture.Services {
+		if service.ID == id {
+			return service.Name
+		}
+	}
+	return id
+}
+
+func (s *Server) escalationPolicyName(id string) string {
+	for _, escalationPolicy := range s.fixture.EscalationPolicies {
+		if escalationPolicy.ID == id {
+			return escalationPolicy.Name
+		}
+	}
+	return id
+}
+
+func (s *Server) schedule(schedule *FixtureSchedule) pagerduty.Schedule {
+	escalationPolicies := make([]pagerduty.APIObject, 0)
+	for _, escalationPolicy := range s.fixture.EscalationPolicies {
+		if inAny(escalationPolicy.Schedules, []string{schedule.ID}) {
+			escalationPolicies = append(escalationPolicies, apiObject(escalationPolicy.ID, "escalation_policy_reference", escalationPolicy.Name))
+		}
+	}
+
+	return pagerduty.Schedule{
+		APIObject:          apiObject(schedule.ID, "schedule", schedule.Name),
+		Name:               schedule.Name,
+		TimeZone:           schedule.Timezone,
+		Teams:              s.teamReferences(schedule.Teams),
+		EscalationPolicies: escalationPolicies,
+	}
+}
+
+func (s *Server) team(id string) pagerduty.Team {
+	for _, team := range s.fixture.Teams {
+		if team.ID == id {
+			return pagerduty.Team{APIObject: apiObject(team.ID, "team", team.Name), Name: team.Name}
+		}
+	}
+	return pagerduty.Team{APIObject: apiObject(id, "team_reference", id), Name: id}
+}
+
+func (s *Server) teamReferences(ids []string) []pagerduty.APIObject {
+	teams := make([]pagerduty.APIObject, 0, len(ids))
+	for _, id := range ids {
+		teams = append(teams, s.team(id).APIObject)
+	}
+	return teams
+}
+
+func (s *Server) teamList(ids []string) []pagerduty.Team {
+	teams := make([]pagerduty.Team, 0, len(ids))
+	for _, id := range ids {
+		teams = append(teams, s.team(id))
+	}
+	return teams
+}
+
+func apiObject(id, objectType, summary string) pagerduty.APIObject {
+	return pagerduty.APIObject{
+		ID:      id,
+		Type:    objectType,
+		Summary: summary,
+	}
+}
+
+func parseQueryTime(value string, location *time.Location) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04:05", value, location)
+}
+
+func cutSuffix(value, suffix string) (string, bool) {
+	if !strings.HasSuffix(value, suffix) {
+		return value, false
+	}
+	return strings.TrimSuffix(value, suffix), true
+}
+
+func queryUint(r *http.Request, name string, defaultValue uint) (uint, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	number, err := strconv.ParseUint(value, 10, 32)
+	return uint(number), err
+}
+
+func inAny(values, wanted []string) bool {
+	for _, value := range values {
+		for _, w := range wanted {
+			if value == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeList(w http.ResponseWriter, key string, items interface{}, offset, limit, total int) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		key:      items,
+		"offset": offset,
+		"limit":  limit,
+		"more":   offset+limit < total,
+		"total":  total,
+	})
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
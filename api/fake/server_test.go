@@ -0,0 +1,185 @@
+package fake
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, token string) *api.PagerDutyClient {
+	fixture, err := LoadFixture("testdata/fixture.yml")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewServer(fixture))
+	t.Cleanup(server.Close)
+
+	pdClient, err := api.NewPagerDutyAPIClient(token, api.ClientOptions{BaseURL: server.URL})
+	require.NoError(t, err)
+	return pdClient
+}
+
+func TestServer_lists(t *testing.T) {
+	pdClient := newTestClient(t, "fake-token")
+
+	users, err := pdClient.ListUsers(context.Background())
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	assert.Equal(t, "USER3", users[2].ID)
+	assert.Equal(t, "Australia/Sydney", users[2].Timezone)
+	assert.Equal(t, []api.Team{{ID: "TEAM2", Name: "Platform"}}, users[2].Teams)
+
+	user, err := pdClient.GetUserById(context.Background(), "USER2")
+	require.NoError(t, err)
+	assert.Equal(t, "mary.jane@example.com", user.Email)
+
+	teams, err := pdClient.ListTeams(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, teams, 2)
+
+	services, err := pdClient.ListServices(context.Background(), "TEAM2")
+	require.NoError(t, err)
+	assert.Equal(t, []*api.Service{{ID: "SERVICE2", Name: "Kubernetes", EscalationPolicyID: "POLICY2"}}, services)
+
+	schedules, err := pdClient.ListSchedules(context.Background())
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	assert.Equal(t, []api.Team{{ID: "TEAM1", Name: "Payments"}}, schedules[0].Teams)
+	assert.Equal(t, []string{"POLICY1"}, schedules[0].EscalationPolicyIDs)
+}
+
+func TestServer_GetSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		scheduleID  string
+		since       string
+		until       string
+		wantEntries []api.RenderedScheduleEntry
+		wantErr     bool
+	}{
+		{
+			name:       "Rotation is rendered and cut to the requested range",
+			scheduleID: "SCHED1",
+			since:      "2026-09-01T00:00:00",
+			until:      "2026-09-15T00:00:00",
+			wantEntries: []api.RenderedScheduleEntry{
+				{Start: "2026-09-01T00:00:00+01:00", End: "2026-09-07T09:00:00+01:00", User: api.User{ID: "USER1", Summary: "John Doe"}},
+				{Start: "2026-09-07T09:00:00+01:00", End: "2026-09-14T09:00:00+01:00", User: api.User{ID: "USER2", Summary: "Mary Jane"}},
+				{Start: "2026-09-14T09:00:00+01:00", End: "2026-09-15T00:00:00+01:00", User: api.User{ID: "USER1", Summary: "John Doe"}},
+			},
+		},
+		{
+			name:       "Overrides replace the rotation in the final schedule",
+			scheduleID: "SCHED1",
+			since:      "2026-09-14T09:00:00+01:00",
+			until:      "2026-09-28T09:00:00+01:00",
+			wantEntries: []api.RenderedScheduleEntry{
+				{Start: "2026-09-14T09:00:00+01:00", End: "2026-09-19T09:00:00+01:00", User: api.User{ID: "USER1", Summary: "John Doe"}},
+				{Start: "2026-09-19T09:00:00+01:00", End: "2026-09-21T09:00:00+01:00", User: api.User{ID: "USER2", Summary: "Mary Jane"}},
+				{Start: "2026-09-21T09:00:00+01:00", End: "2026-09-28T09:00:00+01:00", User: api.User{ID: "USER2", Summary: "Mary Jane"}},
+			},
+		},
+		{
+			name:       "Explicit entries are rendered in the schedule timezone",
+			scheduleID: "SCHED2",
+			since:      "2026-09-10T00:00:00Z",
+			until:      "2026-10-01T00:00:00Z",
+			wantEntries: []api.RenderedScheduleEntry{
+				{Start: "2026-09-10T10:00:00+10:00", End: "2026-09-15T00:00:00+10:00", User: api.User{ID: "USER3", Summary: "Bruce Wayne"}},
+			},
+		},
+		{
+			name:       "Unknown schedule fails",
+			scheduleID: "SCHED3",
+			since:      "2026-09-01T00:00:00",
+			until:      "2026-09-15T00:00:00",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdClient := newTestClient(t, "fake-token")
+
+			schedule, err := pdClient.GetSchedule(context.Background(), tt.scheduleID, tt.since, tt.until)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEntries, schedule.FinalSchedule.RenderedScheduleEntries)
+		})
+	}
+}
+
+func TestServer_ListOverrides(t *testing.T) {
+	pdClient := newTestClient(t, "fake-token")
+
+	overrides, err := pdClient.ListOverrides(context.Background(), "SCHED1", "2026-09-01T00:00:00", "2026-10-01T00:00:00")
+	require.NoError(t, err)
+	assert.Equal(t, []*api.Override{{
+		ID:    "SCHED1-OVERRIDE1",
+		Start: "2026-09-19T09:00:00+01:00",
+		End:   "2026-09-21T09:00:00+01:00",
+		User:  api.User{ID: "USER2", Summary: "Mary Jane"},
+	}}, overrides)
+
+	overrides, err = pdClient.ListOverrides(context.Background(), "SCHED1", "2026-10-01T00:00:00", "2026-11-01T00:00:00")
+	require.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	schedule, err := pdClient.GetSchedule(context.Background(), "SCHED1", "2026-09-19T00:00:00", "2026-09-20T00:00:00")
+	require.NoError(t, err)
+	require.Len(t, schedule.Layers, 1)
+	assert.Equal(t, "USER1", schedule.Layers[0].RenderedScheduleEntries[0].User.ID)
+}
+
+func TestServer_incidents(t *testing.T) {
+	pdClient := newTestClient(t, "fake-token")
+
+	incidents, err := pdClient.ListIncidents(context.Background(), "2026-09-01T00:00:00Z", "2026-10-01T00:00:00Z", nil)
+	require.NoError(t, err)
+	require.Len(t, incidents, 3)
+
+	incidents, err = pdClient.ListIncidents(context.Background(), "2026-09-01T00:00:00Z", "2026-10-01T00:00:00Z", []string{"SERVICE1"})
+	require.NoError(t, err)
+	require.Len(t, incidents, 2)
+	assert.Equal(t, &api.Incident{
+		ID:        "INC1",
+		Number:    1,
+		Title:     "Payments API down",
+		Urgency:   "high",
+		Status:    "resolved",
+		CreatedAt: "2026-09-02T22:10:00Z",
+		Service:   api.Service{ID: "SERVICE1", Name: "Payments API"},
+	}, incidents[0])
+
+	incidents, err = pdClient.ListIncidents(context.Background(), "2026-09-10T00:00:00Z", "2026-10-01T00:00:00Z", nil)
+	require.NoError(t, err)
+	require.Len(t, incidents, 1)
+	assert.Equal(t, "INC2", incidents[0].ID)
+
+	logEntries, err := pdClient.ListIncidentLogEntries(context.Background(), "INC1")
+	require.NoError(t, err)
+	assert.Equal(t, []*api.LogEntry{
+		{ID: "INC1-LOG4", Type: "resolve_log_entry", CreatedAt: "2026-09-02T23:40:00Z", Agent: api.User{ID: "USER1", Summary: "John Doe"}},
+		{ID: "INC1-LOG3", Type: "acknowledge_log_entry", CreatedAt: "2026-09-02T22:14:00Z", Agent: api.User{ID: "USER1", Summary: "John Doe"}},
+		{ID: "INC1-LOG2", Type: "notify_log_entry", CreatedAt: "2026-09-02T22:10:00Z", User: api.User{ID: "USER1", Summary: "John Doe"}},
+		{ID: "INC1-LOG1", Type: "trigger_log_entry", CreatedAt: "2026-09-02T22:10:00Z"},
+	}, logEntries)
+
+	_, err = pdClient.ListIncidentLogEntries(context.Background(), "INC9")
+	require.Error(t, err)
+}
+
+func TestServer_unauthorized(t *testing.T) {
+	pdClient := newTestClient(t, "wrong-token")
+
+	_, err := pdClient.ListUsers(context.Background())
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "wrong-token")
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// Incident is an incident of a service, CreatedAt is when it was triggered.
+type Incident struct {
+	ID        string
+	Number    uint
+	Title     string
+	Urgency   string
+	Status    string
+	CreatedAt string
+	Service   Service
+}
+
+// LogEntry is an event in the timeline of an incident. Type is the PagerDuty log entry type, such as
+// notify_log_entry, Agent is the user who caused it (empty for services and integrations) and User
+// the user notified, for notifications.
+type LogEntry struct {
+	ID        string
+	Type      string
+	CreatedAt string
+	Agent     User
+	User      User
+}
+
+// ListIncidents lists the incidents of the services triggered in the range.
+func (p *PagerDutyClient) ListIncidents(ctx context.Context, startDate, endDate string, serviceIDs []string) ([]*Incident, error) {
+	var opts pagerduty.ListIncidentsOptions
+	opts.Since = startDate
+	opts.Until = endDate
+	opts.ServiceIDs = serviceIDs
+	opts.Statuses = []string{"triggered", "acknowledged", "resolved"}
+	var incidentList []*Incident
+
+	more := true
+	for more {
+		listIncidentsResponse, err := p.ApiClient.ListIncidentsWithContext(ctx, opts)
+		if err != nil {
+			return nil, p.apiError(ctx, err)
+		}
+
+		for _, incident := range listIncidentsResponse.Incidents {
+			incidentList = append(incidentList, &Incident{
+				ID:        incident.ID,
+				Number:    incident.IncidentNumber,
+				Title:     incident.Title,
+				Urgency:   incident.Urgency,
+				Status:    incident.Status,
+				CreatedAt: incident.CreatedAt,
+				Service: Service{
+					ID:   incident.Service.ID,
+					Name: incident.Service.Summary,
+				},
+			})
+		}
+		more = listIncidentsResponse.More
+		opts.Offset += listIncidentsResponse.Limit
+	}
+
+	return incidentList, nil
+}
+
+func (p *PagerDutyClient) ListIncidentLogEntries(ctx context.Context, incidentID string) ([]*LogEntry, error) {
+	var opts pagerduty.ListIncidentLogEntriesOptions
+	var logEntryList []*LogEntry
+
+	more := true
+	for more {
+		listLogEntriesResponse, err := p.ApiClient.ListIncidentLogEntriesWithContext(ctx, incidentID, opts)
+		if err != nil {
+			return nil, p.apiError(ctx, err)
+		}
+
+		for _, logEntry := range listLogEntriesResponse.LogEntries {
+			entry := &LogEntry{
+				ID:        logEntry.ID,
+				Type:      logEntry.Type,
+				CreatedAt: logEntry.CreatedAt,
+				User: User{
+					ID:      logEntry.User.ID,
+					Summary: logEntry.User.Summary,
+				},
+			}
+			if strings.HasPrefix(logEntry.Agent.Type, "user") {
+				entry.Agent = User{
+					ID:      logEntry.Agent.ID,
+					Summary: logEntry.Agent.Summary,
+				}
+			}
+			logEntryList = append(logEntryList, entry)
+		}
+		more = listLogEntriesResponse.More
+		opts.Offset += listLogEntriesResponse.Limit
+	}
+
+	return logEntryList, nil
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ListIncidents(t *testing.T) {
+	tests := []struct {
+		name        string
+		clientSetup func(*clientMock)
+		want        []*Incident
+		wantErr     bool
+	}{
+		{
+			name: "Failed to get list of incidents",
+			clientSetup: func(clientMock *clientMock) {
+				clientMock.On("ListIncidentsWithContext", mock.Anything, mock.Anything).Once().Return(
+					nil, errors.New("failed to get list of incidents"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Successfully get all the pages of incidents",
+			clientSetup: func(clientMock *clientMock) {
+				clientMock.On("ListIncidentsWithContext", mock.Anything, mock.MatchedBy(func(o pagerduty.ListIncidentsOptions) bool {
+					return o.Offset == 0 && o.Since == "2026-09-01T00:00:00Z" && o.Until == "2026-10-01T00:00:00Z" &&
+						len(o.ServiceIDs) == 1 && o.ServiceIDs[0] == "SERVICE1"
+				})).Once().Return(
+					&pagerduty.ListIncidentsResponse{
+						APIListObject: pagerduty.APIListObject{Limit: 1, More: true},
+						Incidents: []pagerduty.Incident{
+							{
+								APIObject:      pagerduty.APIObject{ID: "INC1"},
+								IncidentNumber: 1,
+								Title:          "Payments API down",
+								Urgency:        "high",
+								Status:         "resolved",
+								CreatedAt:      "2026-09-02T22:10:00Z",
+								Service:        pagerduty.APIObject{ID: "SERVICE1", Summary: "Payments API"},
+							},
+						},
+					}, nil)
+				clientMock.On("ListIncidentsWithContext", mock.Anything, mock.MatchedBy(func(o pagerduty.ListIncidentsOptions) bool {
+					return o.Offset == 1
+				})).Once().Return(
+					&pagerduty.ListIncidentsResponse{
+						APIListObject: pagerduty.APIListObject{Limit: 1},
+						Incidents: []pagerduty.Incident{
+							{
+								APIObject:      pagerduty.APIObject{ID: "INC2"},
+								IncidentNumber: 2,
+								Urgency:        "low",
+							},
+						},
+					}, nil)
+			},
+			want: []*Incident{
+				{
+					ID:        "INC1",
+					Number:    1,
+					Title:     "Payments API down",
+					Urgency:   "high",
+					Status:    "resolved",
+					CreatedAt: "2026-09-02T22:10:00Z",
+					Service:   Service{ID: "SERVICE1", Name: "Payments API"},
+				},
+				{
+					ID:      "INC2",
+					Number:  2,
+					Urgency: "low",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedClient := &clientMock{}
+			if tt.clientSetup != nil {
+				tt.clientSetup(mockedClient)
+			}
+
+			pdClient := PagerDutyClient{ApiClient: mockedClient}
+			incidents, err := pdClient.ListIncidents(context.Background(), "2026-09-01T00:00:00Z", "2026-10-01T00:00:00Z", []string{"SERVICE1"})
+			mockedClient.AssertExpectations(t)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, incidents)
+		})
+	}
+}
+
+func Test_ListIncidentLogEntries(t *testing.T) {
+	tests := []struct {
+		name        string
+		clientSetup func(*clientMock)
+		want        []*LogEntry
+		wantErr     bool
+	}{
+		{
+			name: "Failed to get the log entries",
+			clientSetup: func(clientMock *clientMock) {
+				clientMock.On("ListIncidentLogEntriesWithContext", mock.Anything, "INC1", mock.Anything).Once().Return(
+					nil, errors.New("failed to get the log entries"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Successfully get the log entries",
+			clientSetup: func(clientMock *clientMock) {
+				clientMock.On("ListIncidentLogEntriesWithContext", mock.Anything, "INC1", mock.Anything).Once().Return(
+					&pagerduty.ListIncidentLogEntriesResponse{
+						LogEntries: []pagerduty.LogEntry{
+							{
+								CommonLogEntryField: pagerduty.CommonLogEntryField{
+									APIObject: pagerduty.APIObject{ID: "LOG3", Type: "resolve_log_entry"},
+									CreatedAt: "2026-09-02T23:00:00Z",
+									Agent:     pagerduty.Agent{ID: "SERVICE1", Type: "service_reference", Summary: "Payments API"},
+								},
+							},
+							{
+								CommonLogEntryField: pagerduty.CommonLogEntryField{
+									APIObject: pagerduty.APIObject{ID: "LOG2", Type: "acknowledge_log_entry"},
+									CreatedAt: "2026-09-02T22:15:00Z",
+									Agent:     pagerduty.Agent{ID: "USER1", Type: "user_reference", Summary: "John Doe"},
+								},
+							},
+							{
+								CommonLogEntryField: pagerduty.CommonLogEntryField{
+									APIObject: pagerduty.APIObject{ID: "LOG1", Type: "notify_log_entry"},
+									CreatedAt: "2026-09-02T22:10:00Z",
+								},
+								User: pagerduty.APIObject{ID: "USER1", Summary: "John Doe"},
+							},
+						},
+					}, nil)
+			},
+			want: []*LogEntry{
+				{ID: "LOG3", Type: "resolve_log_entry", CreatedAt: "2026-09-02T23:00:00Z"},
+				{ID: "LOG2", Type: "acknowledge_log_entry", CreatedAt: "2026-09-02T22:15:00Z", Agent: User{ID: "USER1", Summary: "John Doe"}},
+				{ID: "LOG1", Type: "notify_log_entry", CreatedAt: "2026-09-02T22:10:00Z", User: User{ID: "USER1", Summary: "John Doe"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedClient := &clientMock{}
+			if tt.clientSetup != nil {
+				tt.clientSetup(mockedClient)
+			}
+
+			pdClient := PagerDutyClient{ApiClient: mockedClient}
+			logEntries, err := pdClient.ListIncidentLogEntries(context.Background(), "INC1")
+			mockedClient.AssertExpectations(t)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, logEntries)
+		})
+	}
+}
@@ -3,6 +3,8 @@
 package api
 
 import (
+	context "context"
+
 	pagerduty "github.com/PagerDuty/go-pagerduty"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,13 +14,13 @@ type clientMock struct {
 	mock.Mock
 }
 
-// GetSchedule provides a mock function with given fields: id, o
-func (_m *clientMock) GetSchedule(id string, o pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error) {
-	ret := _m.Called(id, o)
+// GetScheduleWithContext provides a mock function with given fields: ctx, id, o
+func (_m *clientMock) GetScheduleWithContext(ctx context.Context, id string, o pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error) {
+	ret := _m.Called(ctx, id, o)
 
 	var r0 *pagerduty.Schedule
-	if rf, ok := ret.Get(0).(func(string, pagerduty.GetScheduleOptions) *pagerduty.Schedule); ok {
-		r0 = rf(id, o)
+	if rf, ok := ret.Get(0).(func(context.Context, string, pagerduty.GetScheduleOptions) *pagerduty.Schedule); ok {
+		r0 = rf(ctx, id, o)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*pagerduty.Schedule)
@@ -26,8 +28,8 @@ func (_m *clientMock) GetSchedule(id string, o pagerduty.GetScheduleOptions) (*p
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, pagerduty.GetScheduleOptions) error); ok {
-		r1 = rf(id, o)
+	if rf, ok := ret.Get(1).(func(context.Context, string, pagerduty.GetScheduleOptions) error); ok {
+		r1 = rf(ctx, id, o)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,13 +37,13 @@ func (_m *clientMock) GetSchedule(id string, o pagerduty.GetScheduleOptions) (*p
 	return r0, r1
 }
 
-// GetUser provides a mock function with given fields: id, o
-func (_m *clientMock) GetUser(id string, o pagerduty.GetUserOptions) (*pagerduty.User, error) {
-	ret := _m.Called(id, o)
+// GetUserWithContext provides a mock function with given fields: ctx, id, o
+func (_m *clientMock) GetUserWithContext(ctx context.Context, id string, o pagerduty.GetUserOptions) (*pagerduty.User, error) {
+	ret := _m.Called(ctx, id, o)
 
 	var r0 *pagerduty.User
-	if rf, ok := ret.Get(0).(func(string, pagerduty.GetUserOptions) *pagerduty.User); ok {
-		r0 = rf(id, o)
+	if rf, ok := ret.Get(0).(func(context.Context, string, pagerduty.GetUserOptions) *pagerduty.User); ok {
+		r0 = rf(ctx, id, o)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*pagerduty.User)
@@ -49,8 +51,8 @@ func (_m *clientMock) GetUser(id string, o pagerduty.GetUserOptions) (*pagerduty
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, pagerduty.GetUserOptions) error); ok {
-		r1 = rf(id, o)
+	if rf, ok := ret.Get(1).(func(context.Context, string, pagerduty.GetUserOptions) error); ok {
+		r1 = rf(ctx, id, o)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -58,13 +60,13 @@ func (_m *clientMock) GetUser(id string, o pagerduty.GetUserOptions) (*pagerduty
 	return r0, r1
 }
 
-// ListSchedules provides a mock function with given fields: o
-func (_m *clientMock) ListSchedules(o pagerduty.ListSchedulesOptions) (*pagerduty.ListSchedulesResponse, error) {
-	ret := _m.Called(o)
+// ListSchedulesWithContext provides a mock function with given fields: ctx, o
+func (_m *clientMock) ListSchedulesWithContext(ctx context.Context, o pagerduty.ListSchedulesOptions) (*pagerduty.ListSchedulesResponse, error) {
+	ret := _m.Called(ctx, o)
 
 	var r0 *pagerduty.ListSchedulesResponse
-	if rf, ok := ret.Get(0).(func(pagerduty.ListSchedulesOptions) *pagerduty.ListSchedulesResponse); ok {
-		r0 = rf(o)
+	if rf, ok := ret.Get(0).(func(context.Context, pagerduty.ListSchedulesOptions) *pagerduty.ListSchedulesResponse); ok {
+		r0 = rf(ctx, o)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*pagerduty.ListSchedulesResponse)
@@ -72,8 +74,8 @@ func (_m *clientMock) ListSchedules(o pagerduty.ListSchedulesOptions) (*pagerdut
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(pagerduty.ListSchedulesOptions) error); ok {
-		r1 = rf(o)
+	if rf, ok := ret.Get(1).(func(context.Context, pagerduty.ListSchedulesOptions) error); ok {
+		r1 = rf(ctx, o)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -81,13 +83,13 @@ func (_m *clientMock) ListSchedules(o pagerduty.ListSchedulesOptions) (*pagerdut
 	return r0, r1
 }
 
-// ListServices provides a mock function with given fields: o
-func (_m *clientMock) ListServices(o pagerduty.ListServiceOptions) (*pagerduty.ListServiceResponse, error) {
-	ret := _m.Called(o)
+// ListServicesWithContext provides a mock function with given fields: ctx, o
+func (_m *clientMock) ListServicesWithContext(ctx context.Context, o pagerduty.ListServiceOptions) (*pagerduty.ListServiceResponse, error) {
+	ret := _m.Called(ctx, o)
 
 	var r0 *pagerduty.ListServiceResponse
-	if rf, ok := ret.Get(0).(func(pagerduty.ListServiceOptions) *pagerduty.ListServiceResponse); ok {
-		r0 = rf(o)
+	if rf, ok := ret.Get(0).(func(context.Context, pagerduty.ListServiceOptions) *pagerduty.ListServiceResponse); ok {
+		r0 = rf(ctx, o)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*pagerduty.ListServiceResponse)
@@ -95,8 +97,8 @@ func (_m *clientMock) ListServices(o pagerduty.ListServiceOptions) (*pagerduty.L
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(pagerduty.ListServiceOptions) error); ok {
-		r1 = rf(o)
+	if rf, ok := ret.Get(1).(func(context.Context, pagerduty.ListServiceOptions) error); ok {
+		r1 = rf(ctx, o)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -104,13 +106,13 @@ func (_m *clientMock) ListServices(o pagerduty.ListServiceOptions) (*pagerduty.L
 	return r0, r1
 }
 
-// ListTeams provides a mock function with given fields: o
-func (_m *clientMock) ListTeams(o pagerduty.ListTeamOptions) (*pagerduty.ListTeamResponse, error) {
-	ret := _m.Called(o)
+// ListTeamsWithContext provides a mock function with given fields: ctx, o
+func (_m *clientMock) ListTeamsWithContext(ctx context.Context, o pagerduty.ListTeamOptions) (*pagerduty.ListTeamResponse, error) {
+	ret := _m.Called(ctx, o)
 
 	var r0 *pagerduty.ListTeamResponse
-	if rf, ok := ret.Get(0).(func(pagerduty.ListTeamOptions) *pagerduty.ListTeamResponse); ok {
-		r0 = rf(o)
+	if rf, ok := ret.Get(0).(func(context.Context, pagerduty.ListTeamOptions) *pagerduty.ListTeamResponse); ok {
+		r0 = rf(ctx, o)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*pagerduty.ListTeamResponse)
@@ -118,8 +120,8 @@ func (_m *clientMock) ListTeams(o pagerduty.ListTeamOptions) (*pagerduty.ListTea
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(pagerduty.ListTeamOptions) error); ok {
-		r1 = rf(o)
+	if rf, ok := ret.Get(1).(func(context.Context, pagerduty.ListTeamOptions) error); ok {
+		r1 = rf(ctx, o)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -127,13 +129,13 @@ func (_m *clientMock) ListTeams(o pagerduty.ListTeamOptions) (*pagerduty.ListTea
 	return r0, r1
 }
 
-// ListUsers provides a mock function with given fields: o
-func (_m *clientMock) ListUsers(o pagerduty.ListUsersOptions) (*pagerduty.ListUsersResponse, error) {
-	ret := _m.Called(o)
+// ListUsersWithContext provides a mock function with given fields: ctx, o
+func (_m *clientMock) ListUsersWithContext(ctx context.Context, o pagerduty.ListUsersOptions) (*pagerduty.ListUsersResponse, error) {
+	ret := _m.Called(ctx, o)
 
 	var r0 *pagerduty.ListUsersResponse
-	if rf, ok := ret.Get(0).(func(pagerduty.ListUsersOptions) *pagerduty.ListUsersResponse); ok {
-		r0 = rf(o)
+	if rf, ok := ret.Get(0).(func(context.Context, pagerduty.ListUsersOptions) *pagerduty.ListUsersResponse); ok {
+		r0 = rf(ctx, o)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*pagerduty.ListUsersResponse)
@@ -141,8 +143,77 @@ func (_m *clientMock) ListUsers(o pagerduty.ListUsersOptions) (*pagerduty.ListUs
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(pagerduty.ListUsersOptions) error); ok {
-		r1 = rf(o)
+	if rf, ok := ret.Get(1).(func(context.Context, pagerduty.ListUsersOptions) error); ok {
+		r1 = rf(ctx, o)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOverridesWithContext provides a mock function with given fields: ctx, id, o
+func (_m *clientMock) ListOverridesWithContext(ctx context.Context, id string, o pagerduty.ListOverridesOptions) (*pagerduty.ListOverridesResponse, error) {
+	ret := _m.Called(ctx, id, o)
+
+	var r0 *pagerduty.ListOverridesResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, pagerduty.ListOverridesOptions) *pagerduty.ListOverridesResponse); ok {
+		r0 = rf(ctx, id, o)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pagerduty.ListOverridesResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, pagerduty.ListOverridesOptions) error); ok {
+		r1 = rf(ctx, id, o)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListIncidentsWithContext provides a mock function with given fields: ctx, o
+func (_m *clientMock) ListIncidentsWithContext(ctx context.Context, o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error) {
+	ret := _m.Called(ctx, o)
+
+	var r0 *pagerduty.ListIncidentsResponse
+	if rf, ok := ret.Get(0).(func(context.Context, pagerduty.ListIncidentsOptions) *pagerduty.ListIncidentsResponse); ok {
+		r0 = rf(ctx, o)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pagerduty.ListIncidentsResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, pagerduty.ListIncidentsOptions) error); ok {
+		r1 = rf(ctx, o)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListIncidentLogEntriesWithContext provides a mock function with given fields: ctx, id, o
+func (_m *clientMock) ListIncidentLogEntriesWithContext(ctx context.Context, id string, o pagerduty.ListIncidentLogEntriesOptions) (*pagerduty.ListIncidentLogEntriesResponse, error) {
+	ret := _m.Called(ctx, id, o)
+
+	var r0 *pagerduty.ListIncidentLogEntriesResponse
+	if rf, ok := ret.Get(0).(func(context.Context, string, pagerduty.ListIncidentLogEntriesOptions) *pagerduty.ListIncidentLogEntriesResponse); ok {
+		r0 = rf(ctx, id, o)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*pagerduty.ListIncidentLogEntriesResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, pagerduty.ListIncidentLogEntriesOptions) error); ok {
+		r1 = rf(ctx, id, o)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// Override is a period where a user replaces whoever the schedule layers put on call.
+type Override struct {
+	ID    string
+	Start string
+	End   string
+	User  User
+}
+
+func (p *PagerDutyClient) ListOverrides(ctx context.Context, scheduleID, startDate, endDate string) ([]*Override, error) {
+	var opts pagerduty.ListOverridesOptions
+	opts.Since = startDate
+	opts.Until = endDate
+	listOverridesResponse, err := p.ApiClient.ListOverridesWithContext(ctx, scheduleID, opts)
+	if err != nil {
+		return nil, p.apiError(ctx, err)
+	}
+
+	var overrideList []*Override
+	for _, override := range listOverridesResponse.Overrides {
+		overrideList = append(overrideList, &Override{
+			ID:    override.ID,
+			Start: override.Start,
+			End:   override.End,
+			User: User{
+				ID:      override.User.ID,
+				Summary: override.User.Summary,
+			},
+		})
+	}
+
+	return overrideList, nil
+}
@@ -1,27 +1,41 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
 )
 
 type PdClient interface {
-	ListSchedules(o pagerduty.ListSchedulesOptions) (*pagerduty.ListSchedulesResponse, error)
-	ListServices(o pagerduty.ListServiceOptions) (*pagerduty.ListServiceResponse, error)
-	ListTeams(o pagerduty.ListTeamOptions) (*pagerduty.ListTeamResponse, error)
-	ListUsers(o pagerduty.ListUsersOptions) (*pagerduty.ListUsersResponse, error)
-	GetUser(id string, o pagerduty.GetUserOptions) (*pagerduty.User, error)
-	GetSchedule(id string, o pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error)
+	ListSchedulesWithContext(ctx context.Context, o pagerduty.ListSchedulesOptions) (*pagerduty.ListSchedulesResponse, error)
+	ListServicesWithContext(ctx context.Context, o pagerduty.ListServiceOptions) (*pagerduty.ListServiceResponse, error)
+	ListTeamsWithContext(ctx context.Context, o pagerduty.ListTeamOptions) (*pagerduty.ListTeamResponse, error)
+	ListUsersWithContext(ctx context.Context, o pagerduty.ListUsersOptions) (*pagerduty.ListUsersResponse, error)
+	GetUserWithContext(ctx context.Context, id string, o pagerduty.GetUserOptions) (*pagerduty.User, error)
+	GetScheduleWithContext(ctx context.Context, id string, o pagerduty.GetScheduleOptions) (*pagerduty.Schedule, error)
+	ListOverridesWithContext(ctx context.Context, id string, o pagerduty.ListOverridesOptions) (*pagerduty.ListOverridesResponse, error)
+	ListIncidentsWithContext(ctx context.Context, o pagerduty.ListIncidentsOptions) (*pagerduty.ListIncidentsResponse, error)
+	ListIncidentLogEntriesWithContext(ctx context.Context, id string, o pagerduty.ListIncidentLogEntriesOptions) (*pagerduty.ListIncidentLogEntriesResponse, error)
 }
 
 type PagerDutyClient struct {
 	ApiClient PdClient
+
+	// tokenRedactor hides the auth token, a pointer so the token isn't printed with the client
+	tokenRedactor *strings.Replacer
 }
 
 type UserRotaPeriod struct {
 	Start time.Time
 	End   time.Time
+	// Override is set when the period comes from a schedule override, CoveredFor then lists
+	// the names of the users the schedule layers had on call
+	Override   bool
+	CoveredFor []string
 }
 
 type UserRotaInfo struct {
@@ -32,8 +46,61 @@ type UserRotaInfo struct {
 
 type ScheduleUserRotationData map[string]*UserRotaInfo
 
-func NewPagerDutyAPIClient(authToken string) *PagerDutyClient {
-	return &PagerDutyClient{
-		ApiClient: pagerduty.NewClient(authToken),
+func NewPagerDutyAPIClient(authToken string, options ClientOptions) (*PagerDutyClient, error) {
+	pdOptions, err := options.pagerDutyOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	pdClient := &PagerDutyClient{ApiClient: pagerduty.NewClient(authToken, pdOptions...)}
+	// an empty token would be "found" between every character of the messages
+	if authToken != "" {
+		pdClient.tokenRedactor = strings.NewReplacer(authToken, "[REDACTED]")
+	}
+	return pdClient, nil
+}
+
+// redactedError hides the auth token in the message of an error returned by the PagerDuty API client, the
+// original error stays in the chain so callers can still reach the pagerduty.APIError. ctxErr is set when the
+// request was cancelled or timed out without the client reporting it.
+type redactedError struct {
+	err      error
+	ctxErr   error
+	redactor *strings.Replacer
+}
+
+func (e *redactedError) Error() string {
+	message := e.err.Error()
+	if e.redactor != nil {
+		message = e.redactor.Replace(message)
+	}
+	if e.ctxErr != nil {
+		return fmt.Sprintf("%s: %s", e.ctxErr, message)
+	}
+	return message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+func (e *redactedError) Is(target error) bool {
+	return e.ctxErr != nil && errors.Is(e.ctxErr, target)
+}
+
+// apiError removes the auth token from the errors returned by the PagerDuty API client, and keeps the
+// context error in the chain when the request was cancelled or timed out.
+func (p *PagerDutyClient) apiError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	redacted := &redactedError{err: err, redactor: p.tokenRedactor}
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		redacted.ctxErr = ctxErr
+	}
+	if redacted.ctxErr == nil && redacted.Error() == err.Error() {
+		return err
 	}
+	return redacted
 }
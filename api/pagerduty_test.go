@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/PagerDuty/go-pagerduty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewPagerDutyAPIClient_redactsToken(t *testing.T) {
+	pdClient, err := NewPagerDutyAPIClient("s3cr3t-t0k3n", ClientOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, fmt.Sprintf("%+v", pdClient), "s3cr3t-t0k3n")
+
+	mockedClient := &clientMock{}
+	mockedClient.On("ListTeamsWithContext", mock.Anything, mock.Anything).Once().Return(
+		(*pagerduty.ListTeamResponse)(nil), errors.New("invalid token s3cr3t-t0k3n"))
+	pdClient.ApiClient = mockedClient
+
+	_, err = pdClient.ListTeams(context.Background())
+	mockedClient.AssertExpectations(t)
+
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "s3cr3t-t0k3n")
+	assert.Contains(t, err.Error(), "[REDACTED]")
+}
+
+func Test_NewPagerDutyAPIClient_emptyToken(t *testing.T) {
+	pdClient, err := NewPagerDutyAPIClient("", ClientOptions{})
+	require.NoError(t, err)
+
+	mockedClient := &clientMock{}
+	mockedClient.On("ListTeamsWithContext", mock.Anything, mock.Anything).Once().Return(
+		(*pagerduty.ListTeamResponse)(nil), errors.New("invalid token"))
+	pdClient.ApiClient = mockedClient
+
+	_, err = pdClient.ListTeams(context.Background())
+	mockedClient.AssertExpectations(t)
+
+	require.Error(t, err)
+	assert.Equal(t, "invalid token", err.Error())
+}
+
+func Test_PagerDutyClient_apiError(t *testing.T) {
+	pdClient, err := NewPagerDutyAPIClient("s3cr3t-t0k3n", ClientOptions{})
+	require.NoError(t, err)
+	apiErr := pagerduty.APIError{StatusCode: 429, APIError: pagerduty.NullAPIErrorObject{
+		Valid:       true,
+		ErrorObject: pagerduty.APIErrorObject{Message: "rate limited s3cr3t-t0k3n"},
+	}}
+
+	err = pdClient.apiError(context.Background(), apiErr)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "s3cr3t-t0k3n")
+	var gotAPIErr pagerduty.APIError
+	require.True(t, errors.As(err, &gotAPIErr))
+	assert.True(t, gotAPIErr.RateLimited())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = pdClient.apiError(ctx, apiErr)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, errors.As(err, &gotAPIErr))
+	assert.NotContains(t, err.Error(), "s3cr3t-t0k3n")
+	assert.Contains(t, err.Error(), "context canceled: ")
+}
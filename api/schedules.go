@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"time"
 
 	"github.com/PagerDuty/go-pagerduty"
@@ -10,7 +11,12 @@ type Schedule struct {
 	ID            string
 	Name          string
 	TimeZone      string
+	Teams         []Team
 	FinalSchedule ScheduleLayer
+	// Layers are rendered without overrides, highest priority first
+	Layers []ScheduleLayer
+	// EscalationPolicyIDs are the escalation policies paging the schedule
+	EscalationPolicyIDs []string
 }
 
 type ScheduleLayer struct {
@@ -29,18 +35,23 @@ type ScheduleInfo struct {
 	Location      *time.Location
 	Start         time.Time
 	End           time.Time
+	Teams         []Team
 	FinalSchedule ScheduleLayer
+	Layers        []ScheduleLayer
+	Overrides     []*Override
+
+	EscalationPolicyIDs []string
 }
 
-func (p *PagerDutyClient) ListSchedules() ([]*Schedule, error) {
+func (p *PagerDutyClient) ListSchedules(ctx context.Context) ([]*Schedule, error) {
 	var opts pagerduty.ListSchedulesOptions
 	var scheduleList []*Schedule
 
 	more := true
 	for more {
-		listSchedulesResponse, err := p.ApiClient.ListSchedules(opts)
+		listSchedulesResponse, err := p.ApiClient.ListSchedulesWithContext(ctx, opts)
 		if err != nil {
-			return nil, err
+			return nil, p.apiError(ctx, err)
 		}
 		for _, schedule := range listSchedulesResponse.Schedules {
 			scheduleList = append(scheduleList, convertSchedule(&schedule))
@@ -52,24 +63,46 @@ func (p *PagerDutyClient) ListSchedules() ([]*Schedule, error) {
 	return scheduleList, nil
 }
 
-func (p *PagerDutyClient) GetSchedule(scheduleID, startDate, endDate string) (*Schedule, error) {
+func (p *PagerDutyClient) GetSchedule(ctx context.Context, scheduleID, startDate, endDate string) (*Schedule, error) {
 	var opts pagerduty.GetScheduleOptions
 	opts.Since = startDate
 	opts.Until = endDate
-	scheduleResponse, err := p.ApiClient.GetSchedule(scheduleID, opts)
+	scheduleResponse, err := p.ApiClient.GetScheduleWithContext(ctx, scheduleID, opts)
 	if err != nil {
-		return nil, err
+		return nil, p.apiError(ctx, err)
 	}
 
 	return convertSchedule(scheduleResponse), nil
 }
 
 func convertSchedule(schedule *pagerduty.Schedule) *Schedule {
+	var scheduleTeams []Team
+	for _, team := range schedule.Teams {
+		scheduleTeams = append(scheduleTeams, Team{
+			ID:   team.ID,
+			Name: team.Summary,
+		})
+	}
+
+	var escalationPolicyIDs []string
+	for _, escalationPolicy := range schedule.EscalationPolicies {
+		escalationPolicyIDs = append(escalationPolicyIDs, escalationPolicy.ID)
+	}
+
+	var scheduleLayers []ScheduleLayer
+	for _, layer := range schedule.ScheduleLayers {
+		scheduleLayers = append(scheduleLayers, convertScheduleLayer(layer))
+	}
+
 	return &Schedule{
 		ID:            schedule.ID,
 		Name:          schedule.Name,
 		TimeZone:      schedule.TimeZone,
+		Teams:         scheduleTeams,
 		FinalSchedule: convertScheduleLayer(schedule.FinalSchedule),
+		Layers:        scheduleLayers,
+
+		EscalationPolicyIDs: escalationPolicyIDs,
 	}
 }
 
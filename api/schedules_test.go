@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -20,7 +21,7 @@ func Test_ListSchedules(t *testing.T) {
 		{
 			name: "Failed to get list of schedules",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("ListSchedules", mock.Anything).Once().Return(
+				clientMock.On("ListSchedulesWithContext", mock.Anything, mock.Anything).Once().Return(
 					nil, errors.New("failed to get list of schedules"))
 			},
 			wantErr: true,
@@ -28,7 +29,7 @@ func Test_ListSchedules(t *testing.T) {
 		{
 			name: "Successfully get list of schedules",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("ListSchedules", mock.Anything).Once().Return(
+				clientMock.On("ListSchedulesWithContext", mock.Anything, mock.Anything).Once().Return(
 					&pagerduty.ListSchedulesResponse{
 						APIListObject: pagerduty.APIListObject{},
 						Schedules: []pagerduty.Schedule{
@@ -39,6 +40,12 @@ func Test_ListSchedules(t *testing.T) {
 								Name:        "Schedule 1",
 								TimeZone:    "Europe/London",
 								Description: "This is the schedule 1",
+								Teams: []pagerduty.APIObject{
+									{
+										ID:      "TEAM1",
+										Summary: "Team 1",
+									},
+								},
 								FinalSchedule: pagerduty.ScheduleLayer{
 									APIObject: pagerduty.APIObject{
 										ID: "QWERTY1",
@@ -60,6 +67,12 @@ func Test_ListSchedules(t *testing.T) {
 					ID:       "QWERTY",
 					Name:     "Schedule 1",
 					TimeZone: "Europe/London",
+					Teams: []Team{
+						{
+							ID:   "TEAM1",
+							Name: "Team 1",
+						},
+					},
 					FinalSchedule: ScheduleLayer{
 						RenderedScheduleEntries: []RenderedScheduleEntry{
 							{
@@ -80,7 +93,7 @@ func Test_ListSchedules(t *testing.T) {
 			}
 
 			pdClient := PagerDutyClient{ApiClient: mockedClient}
-			scheduleList, err := pdClient.ListSchedules()
+			scheduleList, err := pdClient.ListSchedules(context.Background())
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -95,6 +108,7 @@ func Test_ListSchedules(t *testing.T) {
 				assert.Equal(t, wantSchedule.ID, scheduleList[i].ID)
 				assert.Equal(t, wantSchedule.Name, scheduleList[i].Name)
 				assert.Equal(t, wantSchedule.TimeZone, scheduleList[i].TimeZone)
+				assert.Equal(t, wantSchedule.Teams, scheduleList[i].Teams)
 
 				assert.IsType(t, ScheduleLayer{}, scheduleList[i].FinalSchedule)
 				assert.IsType(t, []RenderedScheduleEntry{}, scheduleList[i].FinalSchedule.RenderedScheduleEntries)
@@ -125,7 +139,7 @@ func Test_GetSchedule(t *testing.T) {
 				},
 			},
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("GetSchedule", mock.Anything, mock.Anything).Once().Return(
+				clientMock.On("GetScheduleWithContext", mock.Anything, mock.Anything, mock.Anything).Once().Return(
 					&pagerduty.Schedule{
 						APIObject: pagerduty.APIObject{
 							ID: "QWERTY",
@@ -152,7 +166,7 @@ func Test_GetSchedule(t *testing.T) {
 		{
 			name: "Failed get schedule by ID",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("GetSchedule", mock.Anything, mock.Anything).Once().Return(
+				clientMock.On("GetScheduleWithContext", mock.Anything, mock.Anything, mock.Anything).Once().Return(
 					nil, errors.New("failed to get schedule by id"))
 			},
 			wantErr: true,
@@ -167,7 +181,7 @@ func Test_GetSchedule(t *testing.T) {
 			}
 
 			pdClient := PagerDutyClient{ApiClient: mockedClient}
-			schedule, err := pdClient.GetSchedule("randomID", "randomStartDate", "randomEndDate")
+			schedule, err := pdClient.GetSchedule(context.Background(), "randomID", "randomStartDate", "randomEndDate")
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -1,26 +1,41 @@
 package api
 
-import "github.com/PagerDuty/go-pagerduty"
+import (
+	"context"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
 
 type Service struct {
-	ID   string
-	Name string
+	ID                 string
+	Name               string
+	EscalationPolicyID string
 }
 
-func (p *PagerDutyClient) ListServices(teamID string) ([]*Service, error) {
+// ListServices lists the services of the team, or of the whole account when teamID is empty.
+func (p *PagerDutyClient) ListServices(ctx context.Context, teamID string) ([]*Service, error) {
 	var opts pagerduty.ListServiceOptions
-	opts.TeamIDs = []string{teamID}
-	listServicesResponse, err := p.ApiClient.ListServices(opts)
-	if err != nil {
-		return nil, err
+	if teamID != "" {
+		opts.TeamIDs = []string{teamID}
 	}
-
 	var serviceList []*Service
-	for _, service := range listServicesResponse.Services {
-		serviceList = append(serviceList, &Service{
-			ID:   service.ID,
-			Name: service.Name,
-		})
+
+	more := true
+	for more {
+		listServicesResponse, err := p.ApiClient.ListServicesWithContext(ctx, opts)
+		if err != nil {
+			return nil, p.apiError(ctx, err)
+		}
+
+		for _, service := range listServicesResponse.Services {
+			serviceList = append(serviceList, &Service{
+				ID:                 service.ID,
+				Name:               service.Name,
+				EscalationPolicyID: service.EscalationPolicy.ID,
+			})
+		}
+		more = listServicesResponse.More
+		opts.Offset += listServicesResponse.Limit
 	}
 
 	return serviceList, nil
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -20,7 +21,7 @@ func Test_ListServices(t *testing.T) {
 		{
 			name: "Failed to get list of services",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("ListServices", mock.Anything).Once().Return(
+				clientMock.On("ListServicesWithContext", mock.Anything, mock.Anything).Once().Return(
 					nil, errors.New("failed to get list of services"))
 			},
 			wantErr: true,
@@ -28,7 +29,7 @@ func Test_ListServices(t *testing.T) {
 		{
 			name: "Successfully get list of services",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("ListServices", mock.Anything).Once().Return(
+				clientMock.On("ListServicesWithContext", mock.Anything, mock.Anything).Once().Return(
 					&pagerduty.ListServiceResponse{
 						Services: []pagerduty.Service{
 							{
@@ -58,7 +59,7 @@ func Test_ListServices(t *testing.T) {
 			}
 
 			pdClient := PagerDutyClient{ApiClient: mockedClient}
-			serviceList, err := pdClient.ListServices("QWERTY")
+			serviceList, err := pdClient.ListServices(context.Background(), "QWERTY")
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -1,25 +1,36 @@
 package api
 
-import "github.com/PagerDuty/go-pagerduty"
+import (
+	"context"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
 
 type Team struct {
 	ID   string
 	Name string
 }
 
-func (p *PagerDutyClient) ListTeams() ([]*Team, error) {
+func (p *PagerDutyClient) ListTeams(ctx context.Context) ([]*Team, error) {
 	var opts pagerduty.ListTeamOptions
-	listTeamsResponse, err := p.ApiClient.ListTeams(opts)
-	if err != nil {
-		return nil, err
-	}
-
 	var teamList []*Team
-	for _, team := range listTeamsResponse.Teams {
-		teamList = append(teamList, &Team{
-			ID:   team.ID,
-			Name: team.Name,
-		})
+
+	more := true
+	for more {
+		listTeamsResponse, err := p.ApiClient.ListTeamsWithContext(ctx, opts)
+		if err != nil {
+			return nil, p.apiError(ctx, err)
+		}
+
+		for _, team := range listTeamsResponse.Teams {
+			teamList = append(teamList, &Team{
+				ID:   team.ID,
+				Name: team.Name,
+			})
+		}
+		more = listTeamsResponse.More
+		opts.Offset += listTeamsResponse.Limit
 	}
+
 	return teamList, nil
 }
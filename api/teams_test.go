@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -20,7 +21,7 @@ func Test_ListTeams(t *testing.T) {
 		{
 			name: "Failed to get list of teams",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("ListTeams", mock.Anything).Once().Return(
+				clientMock.On("ListTeamsWithContext", mock.Anything, mock.Anything).Once().Return(
 					nil, errors.New("failed to get list of teams"))
 			},
 			wantErr: true,
@@ -28,7 +29,7 @@ func Test_ListTeams(t *testing.T) {
 		{
 			name: "Successfully get list of teams",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("ListTeams", mock.Anything).Once().Return(
+				clientMock.On("ListTeamsWithContext", mock.Anything, mock.Anything).Once().Return(
 					&pagerduty.ListTeamResponse{
 						Teams: []pagerduty.Team{
 							{
@@ -58,7 +59,7 @@ func Test_ListTeams(t *testing.T) {
 			}
 
 			pdClient := PagerDutyClient{ApiClient: mockedClient}
-			teamList, err := pdClient.ListTeams()
+			teamList, err := pdClient.ListTeams(context.Background())
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/PagerDuty/go-pagerduty"
+)
+
+// ClientOptions configures how the PagerDuty API is reached, zero values keep the go-pagerduty defaults.
+type ClientOptions struct {
+	BaseURL   string
+	Proxy     string
+	CABundle  string
+	Timeout   time.Duration
+	UserAgent string
+}
+
+func (o ClientOptions) pagerDutyOptions() ([]pagerduty.ClientOptions, error) {
+	var pdOptions []pagerduty.ClientOptions
+	if o.BaseURL != "" {
+		if _, err := url.ParseRequestURI(o.BaseURL); err != nil {
+			return nil, fmt.Errorf("invalid PagerDuty API base URL: %w", err)
+		}
+		pdOptions = append(pdOptions, pagerduty.WithAPIEndpoint(o.BaseURL))
+	}
+
+	if o.Proxy == "" && o.CABundle == "" && o.Timeout == 0 && o.UserAgent == "" {
+		return pdOptions, nil
+	}
+
+	httpClient, err := o.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	return append(pdOptions, func(client *pagerduty.Client) {
+		client.HTTPClient = httpClient
+	}), nil
+}
+
+func (o ClientOptions) httpClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if o.Proxy != "" {
+		proxyURL, err := url.Parse(o.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if o.CABundle != "" {
+		certPool, err := loadCABundle(o.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    certPool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	var roundTripper http.RoundTripper = transport
+	if o.UserAgent != "" {
+		roundTripper = &userAgentTransport{userAgent: o.UserAgent, next: transport}
+	}
+
+	return &http.Client{
+		Transport: roundTripper,
+		Timeout:   o.Timeout,
+	}, nil
+}
+
+// loadCABundle adds the PEM certificates of the file to the system ones.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pemCerts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	certPool, err := x509.SystemCertPool()
+	if err != nil {
+		certPool = x509.NewCertPool()
+	}
+	if !certPool.AppendCertsFromPEM(pemCerts) {
+		return nil, fmt.Errorf("no certificates found in CA bundle '%s'", path)
+	}
+	return certPool, nil
+}
+
+// userAgentTransport replaces the go-pagerduty user agent, which is set on every request.
+type userAgentTransport struct {
+	userAgent string
+	next      http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+	return t.next.RoundTrip(req)
+}
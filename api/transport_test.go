@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewPagerDutyAPIClient_options(t *testing.T) {
+	var userAgent, path string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"teams": [{"id": "TEAM1", "name": "Team 1"}], "more": false}`))
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}), 0600))
+
+	tests := []struct {
+		name          string
+		options       ClientOptions
+		wantUserAgent string
+		wantErr       bool
+	}{
+		{
+			name: "Successfully reach the API with a custom CA bundle and user agent",
+			options: ClientOptions{
+				BaseURL:   server.URL,
+				CABundle:  caBundle,
+				Timeout:   5 * time.Second,
+				UserAgent: "pd-report-test",
+			},
+			wantUserAgent: "pd-report-test",
+		},
+		{
+			name: "Unknown certificate authority fails",
+			options: ClientOptions{
+				BaseURL: server.URL,
+				Timeout: 5 * time.Second,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userAgent, path = "", ""
+
+			pdClient, err := NewPagerDutyAPIClient("token", tt.options)
+			require.NoError(t, err)
+
+			teams, err := pdClient.ListTeams(context.Background())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, teams, 1)
+			assert.Equal(t, "/teams", path)
+			assert.Equal(t, tt.wantUserAgent, userAgent)
+		})
+	}
+}
+
+func Test_NewPagerDutyAPIClient_invalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ClientOptions
+	}{
+		{name: "Invalid base URL", options: ClientOptions{BaseURL: "not a url"}},
+		{name: "Missing CA bundle", options: ClientOptions{CABundle: filepath.Join(t.TempDir(), "missing.pem")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPagerDutyAPIClient("token", tt.options)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/PagerDuty/go-pagerduty"
@@ -15,15 +16,15 @@ type User struct {
 	Teams    []Team
 }
 
-func (p *PagerDutyClient) ListUsers() ([]*User, error) {
+func (p *PagerDutyClient) ListUsers(ctx context.Context) ([]*User, error) {
 	var opts pagerduty.ListUsersOptions
 	var userList []*User
 
 	more := true
 	for more {
-		listUsersResponse, err := p.ApiClient.ListUsers(opts)
+		listUsersResponse, err := p.ApiClient.ListUsersWithContext(ctx, opts)
 		if err != nil {
-			return nil, err
+			return nil, p.apiError(ctx, err)
 		}
 
 		for _, user := range listUsersResponse.Users {
@@ -36,10 +37,10 @@ func (p *PagerDutyClient) ListUsers() ([]*User, error) {
 	return userList, nil
 }
 
-func (p *PagerDutyClient) GetUserById(id string) (*User, error) {
-	pdUser, err := p.ApiClient.GetUser(id, pagerduty.GetUserOptions{})
+func (p *PagerDutyClient) GetUserById(ctx context.Context, id string) (*User, error) {
+	pdUser, err := p.ApiClient.GetUserWithContext(ctx, id, pagerduty.GetUserOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch user by id (%s): %w", id, err)
+		return nil, fmt.Errorf("failed to fetch user by id (%s): %w", id, p.apiError(ctx, err))
 	}
 
 	return convertUser(pdUser), nil
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -31,7 +32,7 @@ func Test_GetUserById(t *testing.T) {
 				},
 			},
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("GetUser", mock.Anything, mock.Anything).Once().Return(&pagerduty.User{
+				clientMock.On("GetUserWithContext", mock.Anything, mock.Anything, mock.Anything).Once().Return(&pagerduty.User{
 					APIObject: pagerduty.APIObject{
 						ID: "QWERTY",
 					},
@@ -57,7 +58,7 @@ func Test_GetUserById(t *testing.T) {
 				Email: "john.doe@email.com",
 			},
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("GetUser", mock.Anything, mock.Anything).Once().Return(
+				clientMock.On("GetUserWithContext", mock.Anything, mock.Anything, mock.Anything).Once().Return(
 					nil, errors.New("failed to get user by id"))
 			},
 			wantErr: true,
@@ -72,7 +73,7 @@ func Test_GetUserById(t *testing.T) {
 			}
 
 			pdClient := PagerDutyClient{ApiClient: mockedClient}
-			user, err := pdClient.GetUserById("randomID")
+			user, err := pdClient.GetUserById(context.Background(), "randomID")
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -102,7 +103,7 @@ func Test_ListUsers(t *testing.T) {
 		{
 			name: "Failed to get list of users",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("ListUsers", mock.Anything).Once().Return(
+				clientMock.On("ListUsersWithContext", mock.Anything, mock.Anything).Once().Return(
 					nil, errors.New("failed to get list of users"))
 			},
 			wantErr: true,
@@ -110,7 +111,7 @@ func Test_ListUsers(t *testing.T) {
 		{
 			name: "Successfully get list of users",
 			clientSetup: func(clientMock *clientMock) {
-				clientMock.On("ListUsers", mock.Anything).Once().Return(
+				clientMock.On("ListUsersWithContext", mock.Anything, mock.Anything).Once().Return(
 					&pagerduty.ListUsersResponse{
 						Users: []pagerduty.User{
 							{
@@ -181,7 +182,7 @@ func Test_ListUsers(t *testing.T) {
 			}
 
 			pdClient := PagerDutyClient{ApiClient: mockedClient}
-			userList, err := pdClient.ListUsers()
+			userList, err := pdClient.ListUsers(context.Background())
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
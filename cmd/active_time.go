@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+// priceActiveTime adds the time the user engaged in the incident within the reported range of the schedule to
+// the user's data, split by day type and paid at the hourly active rate of each day type.
+func (pd *pagerDutyClient) priceActiveTime(ctx context.Context, incident *api.Incident, response *incidentResponse,
+	rotation *scheduleRotation, scheduleUserData *report.ScheduleUser, calendars *userCalendars) error {
+
+	if response.engagedFrom.IsZero() {
+		return nil
+	}
+	engagedFrom, resolvedAt := response.engagedFrom, response.resolvedAt
+	if engagedFrom.Before(rotation.data.StartDate) {
+		engagedFrom = rotation.data.StartDate
+	}
+	if resolvedAt.After(rotation.data.EndDate) {
+		resolvedAt = rotation.data.EndDate
+	}
+	if !engagedFrom.Before(resolvedAt) {
+		return nil
+	}
+
+	engagedFrom, err := pd.convertToUserLocalTimezone(ctx, engagedFrom, response.userID)
+	if err != nil {
+		return fmt.Errorf("aborted due to failed to convert to user local timezone: %w", err)
+	}
+	// the time is split by rota day, to pay each of them on its day with the calendar of its year
+	for from := engagedFrom; from.Before(resolvedAt); {
+		day := rotaDate(from)
+		to := time.Date(day.Year(), day.Month(), day.Day()+1, Config.RotationInfo.DailyRotationStartsAt, 0, 0, 0, from.Location())
+		if to.After(resolvedAt) {
+			to = resolvedAt
+		}
+		userCalendar, err := calendars.forYear(day.Year())
+		if err != nil {
+			return fmt.Errorf("aborted due to %w for user '%s'", err, response.userID)
+		}
+		amount := priceActiveHours(incident, scheduleUserData, dayTypeHours(userCalendar, from, to))
+		rotation.addPay(scheduleUserData.Name, day, amount)
+		from = to
+	}
+
+	return nil
+}
+
+// priceActiveHours adds the hours engaged in the incident per day type to the user's data and returns their pay.
+func priceActiveHours(incident *api.Incident, scheduleUserData *report.ScheduleUser, dayTypes map[string]float32) float32 {
+	var amount float32
+	for _, dayType := range []string{"weekday", "weekend", "bankholiday"} {
+		hours := dayTypes[dayType]
+		if hours == 0 {
+			continue
+		}
+		price, found := Config.FindActiveRate(dayType)
+		if !found {
+			log.Printf("No active rate for %s, the time engaged in incident #%d is not paid", dayType, incident.Number)
+		}
+
+		switch dayType {
+		case "bankholiday":
+			scheduleUserData.NumActiveBankHolidaysHours += hours
+		case "weekend":
+			scheduleUserData.NumActiveWeekendHours += hours
+		default:
+			scheduleUserData.NumActiveWorkHours += hours
+		}
+		scheduleUserData.TotalAmountActive += hours * float32(price)
+		scheduleUserData.TotalAmount += hours * float32(price)
+		amount += hours * float32(price)
+	}
+	return amount
+}
+
+// dayTypeHours splits the hours from start to end by rota day type. The day type only changes on the hour or at
+// the minutes bank holidays start or end, so the time between those is counted at once.
+func dayTypeHours(calendar *configuration.BHCalendar, start, end time.Time) map[string]float32 {
+	changeMinutes := map[int]bool{0: true}
+	for _, bankHoliday := range calendar.DaysMaps {
+		for _, hourMinute := range []configuration.HourMinute{bankHoliday.Start, bankHoliday.End} {
+			if hourMinute.Time != nil {
+				changeMinutes[hourMinute.Time.Minute()] = true
+			}
+		}
+	}
+	minutes := make([]int, 0, len(changeMinutes))
+	for minute := range changeMinutes {
+		minutes = append(minutes, minute)
+	}
+	sort.Ints(minutes)
+
+	hours := make(map[string]float32)
+	for from := start; from.Before(end); {
+		to := nextDayTypeChange(from, minutes)
+		if to.After(end) {
+			to = end
+		}
+		hours[rotaDayType(calendar, from)] += float32(to.Sub(from).Hours())
+		from = to
+	}
+	return hours
+}
+
+// nextDayTypeChange is the first of the given minutes of the hour after the date, or the next hour.
+func nextDayTypeChange(date time.Time, minutes []int) time.Time {
+	hour := date.Add(-time.Duration(date.Minute())*time.Minute - time.Duration(date.Second())*time.Second -
+		time.Duration(date.Nanosecond()))
+	for _, minute := range minutes {
+		if change := hour.Add(time.Duration(minute) * time.Minute); change.After(date) {
+			return change
+		}
+	}
+	return hour.Add(time.Hour)
+}
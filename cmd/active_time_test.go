@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dayTypeHours(t *testing.T) {
+	newTestConfig(t)
+	halfDay := func(start, end string) configuration.BankHoliday {
+		startsAt, _ := time.Parse("15:04", start)
+		endsAt, _ := time.Parse("15:04", end)
+		return configuration.BankHoliday{Start: configuration.HourMinute{Time: &startsAt}, End: configuration.HourMinute{Time: &endsAt}}
+	}
+	calendar := &configuration.BHCalendar{DaysMaps: map[string]configuration.BankHoliday{
+		"24/12/2026": halfDay("12:30", "23:45"),
+	}}
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  map[string]float32
+	}{
+		{
+			name:  "within an hour",
+			start: time.Date(2026, time.September, 9, 23, 14, 0, 0, london),
+			end:   time.Date(2026, time.September, 9, 23, 44, 0, 0, london),
+			want:  map[string]float32{"weekday": 0.5},
+		},
+		{
+			name:  "the early hours belong to the previous rota day",
+			start: time.Date(2026, time.September, 11, 20, 0, 0, 0, london),
+			end:   time.Date(2026, time.September, 14, 10, 0, 0, 0, london),
+			want:  map[string]float32{"weekday": 14, "weekend": 48},
+		},
+		{
+			name:  "bank holidays starting and ending within an hour",
+			start: time.Date(2026, time.December, 24, 12, 0, 0, 0, london),
+			end:   time.Date(2026, time.December, 25, 0, 0, 0, 0, london),
+			want:  map[string]float32{"weekday": 0.75, "bankholiday": 11.25},
+		},
+		{
+			name:  "over a clock change",
+			start: time.Date(2026, time.October, 24, 8, 0, 0, 0, london),
+			end:   time.Date(2026, time.October, 26, 8, 0, 0, 0, london),
+			want:  map[string]float32{"weekend": 49},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dayTypeHours(calendar, tt.start, tt.end)
+
+			for _, dayType := range []string{"weekday", "weekend", "bankholiday"} {
+				assert.InDelta(t, tt.want[dayType], got[dayType], 0.0001, dayType)
+			}
+		})
+	}
+}
+
+func Test_pagerDutyClient_priceActiveTime(t *testing.T) {
+	newTestConfig(t)
+	Config.ActiveRates = []configuration.ActiveRate{{Day: "weekday", Price: 30}, {Day: "weekend", Price: 45}}
+	pd := &pagerDutyClient{cachedUsers: []*api.User{{ID: "USER1", Timezone: "UTC"}}}
+
+	tests := []struct {
+		name        string
+		engagedFrom time.Time
+		resolvedAt  time.Time
+		wantWeekday float32
+		wantWeekend float32
+		wantPay     dailyPay
+	}{
+		{
+			name:        "not engaged",
+			engagedFrom: time.Time{},
+		},
+		{
+			name:        "engaged on a weekday night",
+			engagedFrom: time.Date(2026, time.September, 9, 23, 0, 0, 0, time.UTC),
+			resolvedAt:  time.Date(2026, time.September, 10, 1, 30, 0, 0, time.UTC),
+			wantWeekday: 2.5,
+			wantPay:     dailyPay{"2026-09-09": 75},
+		},
+		{
+			name:        "engaged over the end of the range",
+			engagedFrom: time.Date(2026, time.September, 30, 22, 0, 0, 0, time.UTC),
+			resolvedAt:  time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC),
+			wantWeekday: 2,
+			wantPay:     dailyPay{"2026-09-30": 60},
+		},
+		{
+			name:        "engaged before the range",
+			engagedFrom: time.Date(2026, time.August, 20, 0, 0, 0, 0, time.UTC),
+			resolvedAt:  time.Date(2026, time.August, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "engaged since before the range",
+			engagedFrom: time.Date(2026, time.August, 31, 0, 0, 0, 0, time.UTC),
+			resolvedAt:  time.Date(2026, time.September, 1, 1, 0, 0, 0, time.UTC),
+			wantWeekday: 1,
+			wantPay:     dailyPay{"2026-08-31": 30},
+		},
+		{
+			name:        "engaged on a weekend",
+			engagedFrom: time.Date(2026, time.September, 19, 11, 5, 0, 0, time.UTC),
+			resolvedAt:  time.Date(2026, time.September, 19, 12, 5, 0, 0, time.UTC),
+			wantWeekend: 1,
+			wantPay:     dailyPay{"2026-09-19": 45},
+		},
+		{
+			name:        "engaged over two rota days",
+			engagedFrom: time.Date(2026, time.September, 18, 20, 0, 0, 0, time.UTC),
+			resolvedAt:  time.Date(2026, time.September, 19, 10, 0, 0, 0, time.UTC),
+			wantWeekday: 12,
+			wantWeekend: 2,
+			wantPay:     dailyPay{"2026-09-18": 360, "2026-09-19": 90},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &incidentResponse{userID: "USER1", engagedFrom: tt.engagedFrom, resolvedAt: tt.resolvedAt}
+			rotation := &scheduleRotation{data: &report.ScheduleData{
+				StartDate: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC),
+			}}
+			scheduleUserData := &report.ScheduleUser{Name: "John Doe"}
+
+			err := pd.priceActiveTime(context.Background(), &api.Incident{Number: 1}, response, rotation, scheduleUserData,
+				&userCalendars{years: map[int]*configuration.BHCalendar{2026: {}}})
+			require.NoError(t, err)
+
+			assert.InDelta(t, tt.wantWeekday, scheduleUserData.NumActiveWorkHours, 0.0001)
+			assert.InDelta(t, tt.wantWeekend, scheduleUserData.NumActiveWeekendHours, 0.0001)
+			wantAmount := tt.wantWeekday*30 + tt.wantWeekend*45
+			assert.InDelta(t, wantAmount, scheduleUserData.TotalAmountActive, 0.0001)
+			assert.InDelta(t, wantAmount, scheduleUserData.TotalAmount, 0.0001)
+			assert.InDeltaMapValues(t, tt.wantPay, rotation.pay["John Doe"], 0.0001)
+		})
+	}
+}
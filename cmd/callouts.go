@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+const (
+	triggerLogEntry     = "trigger_log_entry"
+	notifyLogEntry      = "notify_log_entry"
+	acknowledgeLogEntry = "acknowledge_log_entry"
+	resolveLogEntry     = "resolve_log_entry"
+)
+
+// scheduleRotation is a reported schedule with the on-call periods of its users and their report data.
+type scheduleRotation struct {
+	info  *api.ScheduleInfo
+	users api.ScheduleUserRotationData
+	data  *report.ScheduleData
+	// pay is the pay of each user per day, by name like the report data
+	pay map[string]dailyPay
+}
+
+// addPay adds the amount paid to the user on the day.
+func (r *scheduleRotation) addPay(userName string, day time.Time, amount float32) {
+	if r.pay == nil {
+		r.pay = make(map[string]dailyPay)
+	}
+	if r.pay[userName] == nil {
+		r.pay[userName] = dailyPay{}
+	}
+	r.pay[userName].add(day, amount)
+}
+
+// incidentLog is an incident of the report range with its log entries.
+type incidentLog struct {
+	incident   *api.Incident
+	logEntries []*api.LogEntry
+}
+
+// getIncidentLogs loads the incidents of the range of the services paging the schedules, through their
+// escalation policies, with their log entries.
+func (pd *pagerDutyClient) getIncidentLogs(ctx context.Context, startDate, endDate time.Time, rotations []*scheduleRotation) ([]*incidentLog, error) {
+	serviceIDs, err := pd.rotationServiceIDs(ctx, rotations)
+	if err != nil {
+		return nil, err
+	}
+	incidentLogs := make([]*incidentLog, 0)
+	if len(serviceIDs) == 0 {
+		log.Printf("No service pages the reported schedules, no incidents are loaded")
+		return incidentLogs, nil
+	}
+
+	incidents, err := pd.client.ListIncidents(ctx, startDate.Format(time.RFC3339), endDate.Format(time.RFC3339), serviceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the incidents: %w", err)
+	}
+	log.Printf("Loading the log entries of %d incident(s)", len(incidents))
+
+	for _, incident := range incidents {
+		if !contains(serviceIDs, incident.Service.ID) {
+			continue
+		}
+		logEntries, err := pd.client.ListIncidentLogEntries(ctx, incident.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the log entries of incident %s: %w", incident.ID, err)
+		}
+		incidentLogs = append(incidentLogs, &incidentLog{incident: incident, logEntries: logEntries})
+	}
+	return incidentLogs, nil
+}
+
+// rotationServiceIDs returns the services whose escalation policies page the schedules.
+func (pd *pagerDutyClient) rotationServiceIDs(ctx context.Context, rotations []*scheduleRotation) ([]string, error) {
+	escalationPolicyIDs := make([]string, 0)
+	for _, rotation := range rotations {
+		escalationPolicyIDs = append(escalationPolicyIDs, rotation.info.EscalationPolicyIDs...)
+	}
+	if len(escalationPolicyIDs) == 0 {
+		return []string{}, nil
+	}
+
+	services, err := pd.client.ListServices(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the services: %w", err)
+	}
+	serviceIDs := make([]string, 0)
+	for _, service := range services {
+		if contains(escalationPolicyIDs, service.EscalationPolicyID) {
+			serviceIDs = append(serviceIDs, service.ID)
+		}
+	}
+	sort.Strings(serviceIDs)
+	return serviceIDs, nil
+}
+
+// getIncidentPayments pays the incidents to the users on call in the schedules, adding them to the users
+// report data: a callout when callout prices are configured and the time engaged in the incident when
+// active rates are.
+func (pd *pagerDutyClient) getIncidentPayments(ctx context.Context, incidentLogs []*incidentLog, rotations []*scheduleRotation) ([]*report.Callout, error) {
+	callouts := make([]*report.Callout, 0)
+	if len(Config.CalloutPrices) == 0 && len(Config.ActiveRates) == 0 {
+		return callouts, nil
+	}
+
+	for _, loggedIncident := range incidentLogs {
+		incident := loggedIncident.incident
+		response, err := incidentResponder(loggedIncident.logEntries)
+		if err != nil {
+			return nil, fmt.Errorf("incident %s: %w", incident.ID, err)
+		}
+		if response.userID == "" {
+			continue
+		}
+
+		rotation, userRotaInfo := findOnCallRotation(rotations, response.userID, response.pagedAt)
+		if rotation == nil {
+			log.Printf("Incident #%d paged user %s while not on call in the reported schedules, ignoring it", incident.Number, response.userID)
+			continue
+		}
+		scheduleUserData := findScheduleUser(rotation.data, userRotaInfo.Name)
+		if scheduleUserData == nil {
+			continue // the user's rota data couldn't be computed
+		}
+
+		rotationUserConfig, err := pd.findRotationUser(ctx, userRotaInfo)
+		if err != nil {
+			return nil, err
+		}
+		calendars := &userCalendars{rotationUser: rotationUserConfig, years: make(map[int]*configuration.BHCalendar)}
+
+		if len(Config.CalloutPrices) > 0 {
+			callout, err := pd.priceCallout(ctx, incident, response, rotation, scheduleUserData, calendars)
+			if err != nil {
+				return nil, err
+			}
+			callouts = append(callouts, callout)
+		}
+		if len(Config.ActiveRates) > 0 {
+			if err := pd.priceActiveTime(ctx, incident, response, rotation, scheduleUserData, calendars); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(callouts, func(i, j int) bool {
+		return callouts[i].Time.Before(callouts[j].Time)
+	})
+	return callouts, nil
+}
+
+// incidentResponse is how the user an incident is attributed to handled it.
+type incidentResponse struct {
+	userID string
+	// pagedAt is when the user acknowledged the incident or, when nobody did, was notified
+	pagedAt time.Time
+	// engagedFrom and resolvedAt bound the time engaged in the incident, zero when the user didn't engage
+	engagedFrom time.Time
+	resolvedAt  time.Time
+}
+
+// incidentResponder returns the user an incident is attributed to, the first one acknowledging it or,
+// when nobody did, the first one notified. The user engaged in the incident from the acknowledgement,
+// or from the trigger when resolving it without acknowledging, until it was resolved.
+func incidentResponder(logEntries []*api.LogEntry) (*incidentResponse, error) {
+	var acknowledgedBy, notified, resolvedBy string
+	var acknowledgedAt, notifiedAt, triggeredAt, resolvedAt time.Time
+	for _, logEntry := range logEntries {
+		switch logEntry.Type {
+		case triggerLogEntry, notifyLogEntry, acknowledgeLogEntry, resolveLogEntry:
+		default:
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339, logEntry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case logEntry.Type == triggerLogEntry && (triggeredAt.IsZero() || createdAt.Before(triggeredAt)):
+			triggeredAt = createdAt
+		case logEntry.Type == acknowledgeLogEntry && logEntry.Agent.ID != "" &&
+			(acknowledgedBy == "" || createdAt.Before(acknowledgedAt)):
+			acknowledgedBy, acknowledgedAt = logEntry.Agent.ID, createdAt
+		case logEntry.Type == notifyLogEntry && logEntry.User.ID != "" &&
+			(notified == "" || createdAt.Before(notifiedAt)):
+			notified, notifiedAt = logEntry.User.ID, createdAt
+		case logEntry.Type == resolveLogEntry && (resolvedAt.IsZero() || createdAt.Before(resolvedAt)):
+			resolvedBy, resolvedAt = logEntry.Agent.ID, createdAt
+		}
+	}
+
+	if acknowledgedBy != "" {
+		response := &incidentResponse{userID: acknowledgedBy, pagedAt: acknowledgedAt}
+		if !resolvedAt.IsZero() {
+			response.engagedFrom, response.resolvedAt = acknowledgedAt, resolvedAt
+		}
+		return response, nil
+	}
+
+	response := &incidentResponse{userID: notified, pagedAt: notifiedAt}
+	if notified != "" && resolvedBy == notified && !triggeredAt.IsZero() {
+		response.engagedFrom, response.resolvedAt = triggeredAt, resolvedAt
+	}
+	return response, nil
+}
+
+// priceCallout adds the callout to the user's data, priced with the user's calendar of the year of the rota day.
+func (pd *pagerDutyClient) priceCallout(ctx context.Context, incident *api.Incident, response *incidentResponse,
+	rotation *scheduleRotation, scheduleUserData *report.ScheduleUser, calendars *userCalendars) (*report.Callout, error) {
+
+	localPagedAt, err := pd.convertToUserLocalTimezone(ctx, response.pagedAt, response.userID)
+	if err != nil {
+		return nil, fmt.Errorf("aborted due to failed to convert to user local timezone: %w", err)
+	}
+	userCalendar, err := calendars.forYear(rotaDate(localPagedAt).Year())
+	if err != nil {
+		return nil, fmt.Errorf("aborted due to %w for user '%s'", err, response.userID)
+	}
+
+	dayType := rotaDayType(userCalendar, localPagedAt)
+	price, found := Config.FindCalloutPrice(dayType, incident.Urgency)
+	if !found {
+		log.Printf("No callout price for %s %s urgency incidents, incident #%d is not paid", dayType, incident.Urgency, incident.Number)
+	}
+
+	scheduleUserData.NumCallouts++
+	scheduleUserData.TotalAmountCallouts += float32(price)
+	scheduleUserData.TotalAmount += float32(price)
+	rotation.addPay(scheduleUserData.Name, rotaDate(localPagedAt), float32(price))
+
+	return &report.Callout{
+		ScheduleID:     rotation.info.ID,
+		ScheduleName:   rotation.info.Name,
+		UserName:       scheduleUserData.Name,
+		IncidentID:     incident.ID,
+		IncidentNumber: incident.Number,
+		IncidentTitle:  incident.Title,
+		Urgency:        incident.Urgency,
+		Time:           localPagedAt,
+		DayType:        dayType,
+		Amount:         float32(price),
+	}, nil
+}
+
+func findOnCallRotation(rotations []*scheduleRotation, userID string, date time.Time) (*scheduleRotation, *api.UserRotaInfo) {
+	for _, rotation := range rotations {
+		userRotaInfo, ok := rotation.users[userID]
+		if !ok {
+			continue
+		}
+		for _, period := range userRotaInfo.Periods {
+			if !date.Before(period.Start) && date.Before(period.End) {
+				return rotation, userRotaInfo
+			}
+		}
+	}
+	return nil, nil
+}
+
+func findScheduleUser(scheduleData *report.ScheduleData, name string) *report.ScheduleUser {
+	for _, scheduleUser := range scheduleData.RotaUsers {
+		if scheduleUser.Name == name {
+			return scheduleUser
+		}
+	}
+	return nil
+}
+
+// rotaDayType classifies the date like updateDataForDate: hours before the daily rotation start
+// belong to the previous day.
+func rotaDayType(calendar *configuration.BHCalendar, date time.Time) string {
+	if date.Hour() < Config.RotationInfo.DailyRotationStartsAt {
+		date = date.Add(time.Hour * time.Duration(-(date.Hour() + 1)))
+	}
+
+	switch {
+	case calendar.IsDateBankHoliday(date):
+		return "bankholiday"
+	case calendar.IsWeekend(date):
+		return "weekend"
+	default:
+		return "weekday"
+	}
+}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pagerDutyClient_getIncidentLogs(t *testing.T) {
+	startDate := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	services := []*api.Service{
+		{ID: "SERVICE1", EscalationPolicyID: "POLICY1"},
+		{ID: "SERVICE2", EscalationPolicyID: "POLICY2"},
+		{ID: "SERVICE3", EscalationPolicyID: "POLICY1"},
+	}
+
+	tests := []struct {
+		name      string
+		rotations []*scheduleRotation
+		mockSetup func(*clientMock)
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "Schedules without escalation policies have no incidents",
+			rotations: []*scheduleRotation{{info: &api.ScheduleInfo{ID: "SCHED1"}}},
+			want:      []string{},
+		},
+		{
+			name:      "Only the incidents of the services paging the schedules are loaded",
+			rotations: []*scheduleRotation{{info: &api.ScheduleInfo{ID: "SCHED1", EscalationPolicyIDs: []string{"POLICY1"}}}},
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListServices", mock.Anything, "").Once().Return(services, nil)
+				clientMock.On("ListIncidents", mock.Anything, "2026-09-01T00:00:00Z", "2026-10-01T00:00:00Z", []string{"SERVICE1", "SERVICE3"}).
+					Once().Return([]*api.Incident{
+					{ID: "INC1", Service: api.Service{ID: "SERVICE1"}},
+					{ID: "INC2", Service: api.Service{ID: "SERVICE2"}},
+				}, nil)
+				clientMock.On("ListIncidentLogEntries", mock.Anything, "INC1").Once().Return([]*api.LogEntry{}, nil)
+			},
+			want: []string{"INC1"},
+		},
+		{
+			name:      "Failed to list services",
+			rotations: []*scheduleRotation{{info: &api.ScheduleInfo{ID: "SCHED1", EscalationPolicyIDs: []string{"POLICY1"}}}},
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListServices", mock.Anything, "").Once().Return(nil, errors.New("failed"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedClient := &clientMock{}
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockedClient)
+			}
+
+			pd := pagerDutyClient{client: mockedClient}
+			got, err := pd.getIncidentLogs(context.Background(), startDate, endDate, tt.rotations)
+			mockedClient.AssertExpectations(t)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			incidentIDs := make([]string, 0, len(got))
+			for _, incidentLog := range got {
+				incidentIDs = append(incidentIDs, incidentLog.incident.ID)
+			}
+			assert.Equal(t, tt.want, incidentIDs)
+		})
+	}
+}
+
+func Test_incidentResponder(t *testing.T) {
+	notify := func(userID, at string) *api.LogEntry {
+		return &api.LogEntry{Type: notifyLogEntry, CreatedAt: at, User: api.User{ID: userID}}
+	}
+	acknowledge := func(userID, at string) *api.LogEntry {
+		return &api.LogEntry{Type: acknowledgeLogEntry, CreatedAt: at, Agent: api.User{ID: userID}}
+	}
+	resolve := func(userID, at string) *api.LogEntry {
+		return &api.LogEntry{Type: resolveLogEntry, CreatedAt: at, Agent: api.User{ID: userID}}
+	}
+	trigger := func(at string) *api.LogEntry {
+		return &api.LogEntry{Type: triggerLogEntry, CreatedAt: at}
+	}
+
+	tests := []struct {
+		name            string
+		logEntries      []*api.LogEntry
+		wantUserID      string
+		wantPagedAt     string
+		wantEngagedFrom string
+		wantResolvedAt  string
+		wantErr         bool
+	}{
+		{
+			name: "Incident is attributed to the first user acknowledging it",
+			logEntries: []*api.LogEntry{
+				resolve("USER1", "2026-09-02T23:40:00Z"),
+				acknowledge("USER2", "2026-09-02T22:30:00Z"),
+				notify("USER2", "2026-09-02T22:25:00Z"),
+				notify("USER1", "2026-09-02T22:10:00Z"),
+				trigger("2026-09-02T22:10:00Z"),
+			},
+			wantUserID:      "USER2",
+			wantPagedAt:     "2026-09-02T22:30:00Z",
+			wantEngagedFrom: "2026-09-02T22:30:00Z",
+			wantResolvedAt:  "2026-09-02T23:40:00Z",
+		},
+		{
+			name: "Unresolved incident has no engaged time",
+			logEntries: []*api.LogEntry{
+				acknowledge("USER1", "2026-09-02T22:30:00Z"),
+				notify("USER1", "2026-09-02T22:10:00Z"),
+				trigger("2026-09-02T22:10:00Z"),
+			},
+			wantUserID:  "USER1",
+			wantPagedAt: "2026-09-02T22:30:00Z",
+		},
+		{
+			name: "Unacknowledged incident is attributed to the first user notified",
+			logEntries: []*api.LogEntry{
+				notify("USER2", "2026-09-02T22:25:00Z"),
+				notify("USER1", "2026-09-02T22:10:00Z"),
+				{Type: acknowledgeLogEntry, CreatedAt: "2026-09-02T22:40:00Z"},
+			},
+			wantUserID:  "USER1",
+			wantPagedAt: "2026-09-02T22:10:00Z",
+		},
+		{
+			name: "Unacknowledged incident resolved by the user notified is engaged from the trigger",
+			logEntries: []*api.LogEntry{
+				resolve("USER1", "2026-09-02T22:50:00Z"),
+				notify("USER1", "2026-09-02T22:10:00Z"),
+				trigger("2026-09-02T22:05:00Z"),
+			},
+			wantUserID:      "USER1",
+			wantPagedAt:     "2026-09-02T22:10:00Z",
+			wantEngagedFrom: "2026-09-02T22:05:00Z",
+			wantResolvedAt:  "2026-09-02T22:50:00Z",
+		},
+		{
+			name: "Unacknowledged incident resolved automatically has no engaged time",
+			logEntries: []*api.LogEntry{
+				{Type: resolveLogEntry, CreatedAt: "2026-09-02T22:50:00Z"},
+				notify("USER1", "2026-09-02T22:10:00Z"),
+				trigger("2026-09-02T22:05:00Z"),
+			},
+			wantUserID:  "USER1",
+			wantPagedAt: "2026-09-02T22:10:00Z",
+		},
+		{
+			name: "Incident without notifications is not attributed",
+			logEntries: []*api.LogEntry{
+				trigger("2026-09-02T22:10:00Z"),
+			},
+		},
+		{
+			name: "Invalid log entry date fails",
+			logEntries: []*api.LogEntry{
+				notify("USER1", "02/09/2026"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := incidentResponder(tt.logEntries)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUserID, response.userID)
+			assertTime(t, tt.wantPagedAt, response.pagedAt)
+			assertTime(t, tt.wantEngagedFrom, response.engagedFrom)
+			assertTime(t, tt.wantResolvedAt, response.resolvedAt)
+		})
+	}
+}
+
+func assertTime(t *testing.T, want string, got time.Time) {
+	t.Helper()
+	if want == "" {
+		assert.True(t, got.IsZero(), "got %s", got)
+		return
+	}
+	wantTime, err := time.Parse(time.RFC3339, want)
+	require.NoError(t, err)
+	assert.True(t, wantTime.Equal(got), "want %s, got %s", want, got)
+}
+
+func Test_pagerDutyClient_priceCallout(t *testing.T) {
+	newTestConfig(t)
+	Config.CalloutPrices = []configuration.CalloutPrice{
+		{Day: "weekday", Price: 50},
+		{Day: "weekend", Price: 75},
+		{Day: "weekend", Urgency: "low", Price: 30},
+		{Day: "bankholiday", Price: 100},
+	}
+	pd := &pagerDutyClient{cachedUsers: []*api.User{{ID: "USER1", Timezone: "Europe/London"}}}
+	calendars := &userCalendars{years: map[int]*configuration.BHCalendar{
+		2026: {DaysMaps: map[string]configuration.BankHoliday{}},
+		2027: {DaysMaps: map[string]configuration.BankHoliday{"01/01/2027": {}}},
+	}}
+
+	tests := []struct {
+		name        string
+		urgency     string
+		pagedAt     string
+		wantDayType string
+		wantAmount  float32
+		wantPayDay  string
+	}{
+		{
+			name:        "paged on a weekday night",
+			urgency:     "high",
+			pagedAt:     "2026-09-02T22:14:00Z",
+			wantDayType: "weekday",
+			wantAmount:  50,
+			wantPayDay:  "2026-09-02",
+		},
+		{
+			name:        "paged on a weekend for a low urgency incident",
+			urgency:     "low",
+			pagedAt:     "2026-09-19T10:05:00Z",
+			wantDayType: "weekend",
+			wantAmount:  30,
+			wantPayDay:  "2026-09-19",
+		},
+		{
+			name:        "paged in the early hours of a monday, on the rota day of the sunday",
+			urgency:     "high",
+			pagedAt:     "2026-09-21T05:00:00Z",
+			wantDayType: "weekend",
+			wantAmount:  75,
+			wantPayDay:  "2026-09-20",
+		},
+		{
+			name:        "paged on new year's day, with the calendar of the new year",
+			urgency:     "high",
+			pagedAt:     "2027-01-01T12:00:00Z",
+			wantDayType: "bankholiday",
+			wantAmount:  100,
+			wantPayDay:  "2027-01-01",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rotation := &scheduleRotation{info: &api.ScheduleInfo{ID: "SCHED1", Name: "Payments primary"}}
+			scheduleUserData := &report.ScheduleUser{Name: "John Doe", TotalAmount: 10}
+			pagedAt, err := time.Parse(time.RFC3339, tt.pagedAt)
+			require.NoError(t, err)
+			incident := &api.Incident{ID: "INC1", Number: 1, Title: "Payments API down", Urgency: tt.urgency}
+
+			callout, err := pd.priceCallout(context.Background(), incident, &incidentResponse{userID: "USER1", pagedAt: pagedAt},
+				rotation, scheduleUserData, calendars)
+			require.NoError(t, err)
+
+			assert.Equal(t, "SCHED1", callout.ScheduleID)
+			assert.Equal(t, "John Doe", callout.UserName)
+			assert.Equal(t, "INC1", callout.IncidentID)
+			assert.Equal(t, "Europe/London", callout.Time.Location().String())
+			assert.Equal(t, tt.wantDayType, callout.DayType)
+			assert.InDelta(t, tt.wantAmount, callout.Amount, 0.0001)
+			assert.Equal(t, 1, scheduleUserData.NumCallouts)
+			assert.InDelta(t, tt.wantAmount, scheduleUserData.TotalAmountCallouts, 0.0001)
+			assert.InDelta(t, 10+tt.wantAmount, scheduleUserData.TotalAmount, 0.0001)
+			assert.InDeltaMapValues(t, dailyPay{tt.wantPayDay: tt.wantAmount}, rotation.pay["John Doe"], 0.0001)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+// shift is an uninterrupted on-call time of a user, possibly over several schedules.
+type shift struct {
+	start time.Time
+	end   time.Time
+}
+
+// checkCompliance evaluates the compliance rules against each user's on-call periods across all the
+// schedules, where overlapping or back to back periods form a single shift. On-call days are the days
+// of the user's timezone with some time on call.
+func (pd *pagerDutyClient) checkCompliance(ctx context.Context, rotations []*scheduleRotation) ([]*report.ComplianceViolation, error) {
+	violations := make([]*report.ComplianceViolation, 0)
+	rules := Config.Compliance
+	if rules == (configuration.ComplianceRules{}) {
+		return violations, nil
+	}
+
+	userNames := make(map[string]string)
+	userPeriods := make(map[string][]*api.UserRotaPeriod)
+	for _, rotation := range rotations {
+		for userID, userRotaInfo := range rotation.users {
+			userNames[userID] = userRotaInfo.Name
+			userPeriods[userID] = append(userPeriods[userID], userRotaInfo.Periods...)
+		}
+	}
+
+	for userID, periods := range userPeriods {
+		userName := userNames[userID]
+		shifts := mergeShifts(periods)
+
+		if rules.MaxConsecutiveHours > 0 {
+			maxConsecutive := time.Hour * time.Duration(rules.MaxConsecutiveHours)
+			for _, shift := range shifts {
+				if onCall := shift.end.Sub(shift.start); onCall > maxConsecutive {
+					violations = append(violations, &report.ComplianceViolation{
+						UserName: userName,
+						Rule:     "maxConsecutiveHours",
+						Start:    shift.start,
+						End:      shift.end,
+						Detail:   fmt.Sprintf("on call %vh in a row, maximum %dh", onCall.Hours(), rules.MaxConsecutiveHours),
+					})
+				}
+			}
+		}
+
+		if rules.MinRestHours > 0 {
+			minRest := time.Hour * time.Duration(rules.MinRestHours)
+			for i := 1; i < len(shifts); i++ {
+				if rest := shifts[i].start.Sub(shifts[i-1].end); rest < minRest {
+					violations = append(violations, &report.ComplianceViolation{
+						UserName: userName,
+						Rule:     "minRestHours",
+						Start:    shifts[i-1].end,
+						End:      shifts[i].start,
+						Detail:   fmt.Sprintf("rest of %vh between shifts, minimum %dh", rest.Hours(), rules.MinRestHours),
+					})
+				}
+			}
+		}
+
+		if rules.MaxDaysPer7Days > 0 || rules.MaxDaysPer30Days > 0 {
+			timezone, err := pd.getUserTimezone(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			location, err := time.LoadLocation(timezone)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load location by timezone: %w", err)
+			}
+			days := onCallDays(shifts, location)
+			violations = append(violations, rollingDaysViolations(userName, days, 7, rules.MaxDaysPer7Days)...)
+			violations = append(violations, rollingDaysViolations(userName, days, 30, rules.MaxDaysPer30Days)...)
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		if !violations[i].Start.Equal(violations[j].Start) {
+			return violations[i].Start.Before(violations[j].Start)
+		}
+		if violations[i].UserName != violations[j].UserName {
+			return violations[i].UserName < violations[j].UserName
+		}
+		return violations[i].Rule < violations[j].Rule
+	})
+	return violations, nil
+}
+
+// mergeShifts returns the periods sorted by start, joining the overlapping or back to back ones.
+func mergeShifts(periods []*api.UserRotaPeriod) []shift {
+	sorted := append([]*api.UserRotaPeriod(nil), periods...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	shifts := make([]shift, 0, len(sorted))
+	for _, period := range sorted {
+		last := len(shifts) - 1
+		if last >= 0 && !period.Start.After(shifts[last].end) {
+			if period.End.After(shifts[last].end) {
+				shifts[last].end = period.End
+			}
+			continue
+		}
+		shifts = append(shifts, shift{start: period.Start, end: period.End})
+	}
+	return shifts
+}
+
+// onCallDays returns the sorted starts of the days in the location with some time on call.
+func onCallDays(shifts []shift, location *time.Location) []time.Time {
+	days := make([]time.Time, 0)
+	for _, shift := range shifts {
+		start := shift.start.In(location)
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+		for ; day.Before(shift.end); day = day.AddDate(0, 0, 1) {
+			if len(days) == 0 || day.After(days[len(days)-1]) {
+				days = append(days, day)
+			}
+		}
+	}
+	return days
+}
+
+// rollingDaysViolations reports the windows of the given days holding more on-call days than allowed,
+// each on-call day being reported in one window at most.
+func rollingDaysViolations(userName string, days []time.Time, windowDays, maxDays int) []*report.ComplianceViolation {
+	violations := make([]*report.ComplianceViolation, 0)
+	if maxDays <= 0 {
+		return violations
+	}
+
+	for i := 0; i < len(days); {
+		windowEnd := days[i].AddDate(0, 0, windowDays)
+		j := i
+		for j < len(days) && days[j].Before(windowEnd) {
+			j++
+		}
+		if j-i <= maxDays {
+			i++
+			continue
+		}
+
+		violations = append(violations, &report.ComplianceViolation{
+			UserName: userName,
+			Rule:     fmt.Sprintf("maxDaysPer%dDays", windowDays),
+			Start:    days[i],
+			End:      windowEnd,
+			Detail:   fmt.Sprintf("on call %d days in %d days, maximum %d", j-i, windowDays, maxDays),
+		})
+		i = j
+	}
+	return violations
+}
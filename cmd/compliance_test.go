@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pagerDutyClient_checkCompliance(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return parsed
+	}
+	period := func(start, end string) *api.UserRotaPeriod {
+		return &api.UserRotaPeriod{Start: date(start), End: date(end)}
+	}
+	rotation := func(scheduleID string, periods ...*api.UserRotaPeriod) *scheduleRotation {
+		return &scheduleRotation{
+			info: &api.ScheduleInfo{ID: scheduleID},
+			users: api.ScheduleUserRotationData{
+				"USER1": {ID: "USER1", Name: "John Doe", Periods: periods},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		rules     configuration.ComplianceRules
+		rotations []*scheduleRotation
+		want      []*report.ComplianceViolation
+	}{
+		{
+			name:  "Back to back periods over schedules form a single shift",
+			rules: configuration.ComplianceRules{MaxConsecutiveHours: 48},
+			rotations: []*scheduleRotation{
+				rotation("SCHED1", period("2026-09-07T08:00:00Z", "2026-09-08T08:00:00Z")),
+				rotation("SCHED2", period("2026-09-08T08:00:00Z", "2026-09-09T20:00:00Z")),
+			},
+			want: []*report.ComplianceViolation{
+				{UserName: "John Doe", Rule: "maxConsecutiveHours", Start: date("2026-09-07T08:00:00Z"), End: date("2026-09-09T20:00:00Z"),
+					Detail: "on call 60h in a row, maximum 48h"},
+			},
+		},
+		{
+			name:  "Short rest between shifts of different schedules",
+			rules: configuration.ComplianceRules{MaxConsecutiveHours: 48, MinRestHours: 12},
+			rotations: []*scheduleRotation{
+				rotation("SCHED1", period("2026-09-07T08:00:00Z", "2026-09-08T08:00:00Z"),
+					period("2026-09-10T08:00:00Z", "2026-09-11T08:00:00Z")),
+				rotation("SCHED2", period("2026-09-08T14:00:00Z", "2026-09-08T20:00:00Z")),
+			},
+			want: []*report.ComplianceViolation{
+				{UserName: "John Doe", Rule: "minRestHours", Start: date("2026-09-08T08:00:00Z"), End: date("2026-09-08T14:00:00Z"),
+					Detail: "rest of 6h between shifts, minimum 12h"},
+			},
+		},
+		{
+			name:  "Too many on-call days in rolling windows",
+			rules: configuration.ComplianceRules{MaxDaysPer7Days: 3, MaxDaysPer30Days: 4},
+			rotations: []*scheduleRotation{
+				// 1st to 3rd and 5th to 6th of September in London, the first period ends at 01:00 there
+				rotation("SCHED1", period("2026-09-01T08:00:00Z", "2026-09-03T00:00:00Z"),
+					period("2026-09-05T08:00:00Z", "2026-09-06T12:00:00Z")),
+			},
+			want: []*report.ComplianceViolation{
+				{UserName: "John Doe", Rule: "maxDaysPer30Days", Start: date("2026-08-31T23:00:00Z"), End: date("2026-09-30T23:00:00Z"),
+					Detail: "on call 5 days in 30 days, maximum 4"},
+				{UserName: "John Doe", Rule: "maxDaysPer7Days", Start: date("2026-08-31T23:00:00Z"), End: date("2026-09-07T23:00:00Z"),
+					Detail: "on call 5 days in 7 days, maximum 3"},
+			},
+		},
+		{
+			name: "Rules are disabled by default",
+			rotations: []*scheduleRotation{
+				rotation("SCHED1", period("2026-09-01T08:00:00Z", "2026-09-30T08:00:00Z")),
+			},
+			want: []*report.ComplianceViolation{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = configuration.New()
+			Config.Compliance = tt.rules
+			t.Cleanup(func() {
+				Config = nil
+			})
+			pd := &pagerDutyClient{
+				cachedUsers: []*api.User{{ID: "USER1", Name: "John Doe", Timezone: "Europe/London"}},
+			}
+
+			got, err := pd.checkCompliance(context.Background(), tt.rotations)
+
+			require.NoError(t, err)
+			require.Len(t, got, len(tt.want))
+			for i, violation := range got {
+				assert.Equal(t, tt.want[i].UserName, violation.UserName)
+				assert.Equal(t, tt.want[i].Rule, violation.Rule)
+				assert.True(t, tt.want[i].Start.Equal(violation.Start), "start %s", violation.Start)
+				assert.True(t, tt.want[i].End.Equal(violation.End), "end %s", violation.End)
+				assert.Equal(t, tt.want[i].Detail, violation.Detail)
+			}
+		})
+	}
+}
+
+func Test_pagerDutyClient_generateReport_failOnCompliance(t *testing.T) {
+	pd := newFakeServerReport(t)
+	Config.Compliance = configuration.ComplianceRules{MaxConsecutiveHours: 120}
+	failOnCompliance = true
+	t.Cleanup(func() {
+		failOnCompliance = false
+	})
+
+	err := pd.generateReport(context.Background())
+	require.EqualError(t, err, "found 3 compliance rule violation(s)")
+
+	violations, err := os.ReadFile(filepath.Join(directory, "pagerduty_oncall_report.9-2026-Compliance.csv"))
+	require.NoError(t, err)
+	// the override of John Doe's week runs into Mary Jane's own week
+	assert.Contains(t, string(violations), "Mary Jane,maxConsecutiveHours,2026-09-19T09:00:00+01:00,2026-09-28T09:00:00+01:00,\"on call 216h in a row, maximum 120h\"\n")
+}
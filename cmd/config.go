@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "manage the report configuration",
+	Long:  "Validate and manage the report configuration file",
+	// config subcommands load the configuration themselves, as it may be invalid or missing
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		applyTimeout(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
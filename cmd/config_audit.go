@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configAuditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "compares the configuration with PagerDuty",
+		Long: "Lists users on call without configuration, configured users and ignored schedules " +
+			"missing from PagerDuty and users whose name differs from PagerDuty",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			initConfig()
+			if !contains([]string{"text", "json"}, auditOutputFormat) {
+				return fmt.Errorf("output format %s not supported, use text or json", auditOutputFormat)
+			}
+
+			apiClient, err := newAPIClient(Config)
+			if err != nil {
+				return err
+			}
+			pd := &pagerDutyClient{
+				client:              apiClient,
+				scheduleWindowDays:  Config.PdAPI.ScheduleWindowDays,
+				scheduleConcurrency: Config.PdAPI.ScheduleConcurrency,
+			}
+			startDate, endDate, err := defaultReportTimeRange()
+			if err != nil {
+				return err
+			}
+			audit, err := pd.auditConfig(cmd.Context(), startDate, endDate)
+			if err != nil {
+				return err
+			}
+
+			if err := printConfigAudit(audit, auditOutputFormat); err != nil {
+				return err
+			}
+			if audit.hasDrift() {
+				return fmt.Errorf("configuration has drifted from PagerDuty")
+			}
+			return nil
+		},
+	}
+
+	auditOutputFormat string
+)
+
+func init() {
+	configAuditCmd.Flags().StringVarP(&auditOutputFormat, "output-format", "o", "text", "text, json")
+	configCmd.AddCommand(configAuditCmd)
+}
+
+type auditUser struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Schedules []string `json:"schedules,omitempty"`
+}
+
+type auditNameMismatch struct {
+	ID             string `json:"id"`
+	ConfiguredName string `json:"configuredName"`
+	PagerDutyName  string `json:"pagerDutyName"`
+}
+
+type configAudit struct {
+	Start                   time.Time           `json:"start"`
+	End                     time.Time           `json:"end"`
+	UnconfiguredUsers       []auditUser         `json:"unconfiguredUsers"`
+	MissingUsers            []auditUser         `json:"missingUsers"`
+	MissingIgnoredSchedules []string            `json:"missingIgnoredSchedules"`
+	NameMismatches          []auditNameMismatch `json:"nameMismatches"`
+}
+
+func (a *configAudit) hasDrift() bool {
+	return len(a.UnconfiguredUsers) > 0 || len(a.MissingUsers) > 0 ||
+		len(a.MissingIgnoredSchedules) > 0 || len(a.NameMismatches) > 0
+}
+
+func (pd *pagerDutyClient) auditConfig(ctx context.Context, startDate, endDate time.Time) (*configAudit, error) {
+	users, err := pd.client.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user list: %w", err)
+	}
+	schedules, err := pd.client.ListSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schedule list: %w", err)
+	}
+
+	audit := &configAudit{
+		Start:                   startDate,
+		End:                     endDate,
+		UnconfiguredUsers:       make([]auditUser, 0),
+		MissingUsers:            make([]auditUser, 0),
+		MissingIgnoredSchedules: make([]string, 0),
+		NameMismatches:          make([]auditNameMismatch, 0),
+	}
+
+	configuredUsers := make(map[string]bool)
+	pagerDutyUsers := make(map[string]*api.User)
+	for _, user := range users {
+		pagerDutyUsers[user.ID] = user
+	}
+	for _, rotationUser := range Config.RotationUsers {
+		configuredUsers[rotationUser.UserID] = true
+
+		user, ok := pagerDutyUsers[rotationUser.UserID]
+		if !ok {
+			audit.MissingUsers = append(audit.MissingUsers, auditUser{ID: rotationUser.UserID, Name: rotationUser.Name})
+			continue
+		}
+		if rotationUser.Name != "" && rotationUser.Name != user.Name {
+			audit.NameMismatches = append(audit.NameMismatches, auditNameMismatch{
+				ID:             rotationUser.UserID,
+				ConfiguredName: rotationUser.Name,
+				PagerDutyName:  user.Name,
+			})
+		}
+	}
+
+	pagerDutySchedules := make(map[string]bool)
+	unconfiguredUsers := make(map[string]*auditUser)
+	for _, schedule := range schedules {
+		pagerDutySchedules[schedule.ID] = true
+		if Config.IsScheduleIDToIgnore(schedule.ID) {
+			continue
+		}
+
+		scheduleInfo, err := pd.getScheduleInformation(ctx, schedule.ID, startDate, endDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch schedule %s: %w", schedule.ID, err)
+		}
+		for _, entry := range scheduleInfo.FinalSchedule.RenderedScheduleEntries {
+			if configuredUsers[entry.User.ID] {
+				continue
+			}
+			user, ok := unconfiguredUsers[entry.User.ID]
+			if !ok {
+				user = &auditUser{ID: entry.User.ID, Name: entry.User.Summary}
+				unconfiguredUsers[entry.User.ID] = user
+			}
+			if !contains(user.Schedules, schedule.ID) {
+				user.Schedules = append(user.Schedules, schedule.ID)
+			}
+		}
+	}
+	for _, user := range unconfiguredUsers {
+		audit.UnconfiguredUsers = append(audit.UnconfiguredUsers, *user)
+	}
+	sort.Slice(audit.UnconfiguredUsers, func(i, j int) bool {
+		return audit.UnconfiguredUsers[i].ID < audit.UnconfiguredUsers[j].ID
+	})
+
+	for _, scheduleID := range Config.SchedulesToIgnore {
+		if !pagerDutySchedules[scheduleID] {
+			audit.MissingIgnoredSchedules = append(audit.MissingIgnoredSchedules, scheduleID)
+		}
+	}
+
+	return audit, nil
+}
+
+func printConfigAudit(audit *configAudit, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(audit)
+	}
+
+	log.Printf("Audited on-call users from %s to %s", audit.Start.Format(time.RFC822), audit.End.Format(time.RFC822))
+
+	fmt.Println(fmt.Sprintf("==== Found %d user(s) on call without configuration ====", len(audit.UnconfiguredUsers)))
+	for _, user := range audit.UnconfiguredUsers {
+		fmt.Println(fmt.Sprintf("[%s] %-30s in schedules: %s", user.ID, user.Name, strings.Join(user.Schedules, " ")))
+	}
+
+	fmt.Println(fmt.Sprintf("==== Found %d configured user(s) missing from PagerDuty ====", len(audit.MissingUsers)))
+	for _, user := range audit.MissingUsers {
+		fmt.Println(fmt.Sprintf("[%s] %s", user.ID, user.Name))
+	}
+
+	fmt.Println(fmt.Sprintf("==== Found %d ignored schedule(s) missing from PagerDuty ====", len(audit.MissingIgnoredSchedules)))
+	for _, scheduleID := range audit.MissingIgnoredSchedules {
+		fmt.Println(fmt.Sprintf("[%s]", scheduleID))
+	}
+
+	fmt.Println(fmt.Sprintf("==== Found %d user name mismatch(es) ====", len(audit.NameMismatches)))
+	for _, mismatch := range audit.NameMismatches {
+		fmt.Println(fmt.Sprintf("[%s] configured as %q, named %q in PagerDuty", mismatch.ID, mismatch.ConfiguredName, mismatch.PagerDutyName))
+	}
+
+	return nil
+}
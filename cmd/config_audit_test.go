@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pagerDutyClient_auditConfig(t *testing.T) {
+	Config = configuration.New()
+	Config.RotationUsers = []configuration.RotationUser{
+		{UserID: "USER1", Name: "John Doe"},
+		{UserID: "USER2", Name: "Mary Jane"},
+		{UserID: "GONE", Name: "Former Employee"},
+	}
+	Config.SchedulesToIgnore = []string{"SCHED2", "DELETED"}
+
+	startDate := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		mockSetup func(*clientMock)
+		want      *configAudit
+		wantErr   bool
+	}{
+		{
+			name: "Successfully detect configuration drift",
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return([]*api.User{
+					{ID: "USER1", Name: "John Doe"},
+					{ID: "USER2", Name: "Mary J. Watson"},
+					{ID: "USER3", Name: "New Joiner"},
+				}, nil)
+				clientMock.On("ListSchedules", mock.Anything).Once().Return([]*api.Schedule{
+					{ID: "SCHED1"},
+					{ID: "SCHED2"},
+				}, nil)
+				clientMock.On("GetSchedule", mock.Anything, "SCHED1", "2026-09-01T00:00:00Z", "2026-10-01T00:00:00Z").Once().Return(&api.Schedule{
+					ID: "SCHED1",
+					FinalSchedule: api.ScheduleLayer{
+						RenderedScheduleEntries: []api.RenderedScheduleEntry{
+							{User: api.User{ID: "USER1", Summary: "John Doe"}},
+							{User: api.User{ID: "USER3", Summary: "New Joiner"}},
+							{User: api.User{ID: "USER3", Summary: "New Joiner"}},
+						},
+					},
+				}, nil)
+			},
+			want: &configAudit{
+				Start:                   startDate,
+				End:                     endDate,
+				UnconfiguredUsers:       []auditUser{{ID: "USER3", Name: "New Joiner", Schedules: []string{"SCHED1"}}},
+				MissingUsers:            []auditUser{{ID: "GONE", Name: "Former Employee"}},
+				MissingIgnoredSchedules: []string{"DELETED"},
+				NameMismatches:          []auditNameMismatch{{ID: "USER2", ConfiguredName: "Mary Jane", PagerDutyName: "Mary J. Watson"}},
+			},
+		},
+		{
+			name: "Failed to get schedule",
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return([]*api.User{}, nil)
+				clientMock.On("ListSchedules", mock.Anything).Once().Return([]*api.Schedule{{ID: "SCHED1"}}, nil)
+				clientMock.On("GetSchedule", mock.Anything, "SCHED1", mock.Anything, mock.Anything).Once().Return(nil, errors.New("failed"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedClient := &clientMock{}
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockedClient)
+			}
+
+			pd := pagerDutyClient{client: mockedClient}
+			got, err := pd.auditConfig(context.Background(), startDate, endDate)
+			mockedClient.AssertExpectations(t)
+
+			if tt.wantErr == true {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, got)
+			assert.True(t, got.hasDrift())
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configInitCmd = &cobra.Command{
+		Use:   "init",
+		Short: "creates a starter configuration file from PagerDuty",
+		Long:  "Writes a starter configuration file with the users and schedules of the given PagerDuty team",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filename, err := starterConfigFilename()
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(filename); err == nil && !overwriteConfig {
+				return fmt.Errorf("configuration file %s already exists, use --force to overwrite it", filename)
+			}
+
+			if initDefaultCalendar != "" {
+				calendars, err := configuration.AvailableCalendars()
+				if err != nil {
+					return err
+				}
+				if !contains(calendars, initDefaultCalendar) {
+					return fmt.Errorf("unknown calendar '%s'", initDefaultCalendar)
+				}
+			}
+
+			apiClient, err := newAPIClient(&configuration.Configuration{PdAuthToken: os.Getenv("PD_AUTH_TOKEN")})
+			if err != nil {
+				return err
+			}
+			pd := &pagerDutyClient{client: apiClient}
+			content, err := pd.generateStarterConfig(cmd.Context(), initTeamID, initDefaultCalendar)
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(filename, content, 0600); err != nil {
+				return fmt.Errorf("failed to write configuration file: %w", err)
+			}
+			log.Printf("Configuration successfully generated: file://%s", filename)
+			return nil
+		},
+	}
+
+	initTeamID          string
+	initDefaultCalendar string
+	overwriteConfig     bool
+)
+
+func init() {
+	configInitCmd.Flags().StringVar(&initTeamID, "team", "", "PagerDuty team id to take the users and schedules from")
+	configInitCmd.Flags().StringVar(&initDefaultCalendar, "default-calendar", "", "holidays calendar of the users whose timezone has no known calendar")
+	configInitCmd.Flags().BoolVar(&overwriteConfig, "force", false, "overwrite the configuration file if it exists")
+	_ = configInitCmd.MarkFlagRequired("team")
+	configCmd.AddCommand(configInitCmd)
+}
+
+const starterConfigTemplate = `# Generated by 'pd-report config init' for team {{ .TeamID }} on {{ .Date }}
+# Review prices, calendars and excluded hours, then check it with 'pd-report config validate'
+
+rotationInfo:
+  dailyRotationStartsAt: 8
+  checkRotationChangeEvery: 30 # minutes
+
+defaultUserTimezone: Europe/London
+{{- if .DefaultCalendar }}
+
+# Holidays calendar of the users without a rotationUsers entry
+defaultHolidayCalendar: {{ .DefaultCalendar }}
+{{- end }}
+
+# Placeholder prices per day on call, replace them with the agreed ones
+rotationPrices:
+  currency: £
+  daysInfo:
+    - day: weekday
+      price: 0
+    - day: weekend
+      price: 0
+    - day: bankholiday
+      price: 0
+
+rotationUsers:
+{{- range .Users }}
+  - name: {{ quote .Name }}
+    holidaysCalendar: {{ .Calendar }}{{ if .Defaulted }} # no calendar known for timezone {{ .Timezone }}{{ end }}
+    userId: {{ .ID }}
+{{- end }}
+
+# Schedules of the team:
+{{- range .Schedules }}
+#   {{ .ID }}: {{ .Name }}
+{{- end }}
+
+# Schedules of other teams, so that reporting 'all' schedules only covers the team
+schedulesToIgnore:
+{{- range .IgnoredSchedules }}
+  - {{ .ID }} # {{ .Name }}
+{{- end }}
+`
+
+type starterConfigUser struct {
+	ID       string
+	Name     string
+	Timezone string
+	Calendar string
+	// Defaulted is set when the timezone has no known calendar and the default one is used
+	Defaulted bool
+}
+
+type starterConfig struct {
+	TeamID           string
+	Date             string
+	DefaultCalendar  string
+	Users            []starterConfigUser
+	Schedules        []*api.Schedule
+	IgnoredSchedules []*api.Schedule
+}
+
+// generateStarterConfig writes a configuration for the team, the users whose timezone has no known calendar get
+// the default calendar, it fails listing them when there is none.
+func (pd *pagerDutyClient) generateStarterConfig(ctx context.Context, teamID, defaultCalendar string) ([]byte, error) {
+	users, err := pd.client.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user list: %w", err)
+	}
+
+	schedules, err := pd.client.ListSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schedule list: %w", err)
+	}
+
+	config := starterConfig{
+		TeamID:           teamID,
+		Date:             time.Now().Format("02/01/2006"),
+		DefaultCalendar:  defaultCalendar,
+		Users:            make([]starterConfigUser, 0),
+		Schedules:        make([]*api.Schedule, 0),
+		IgnoredSchedules: make([]*api.Schedule, 0),
+	}
+
+	withoutCalendar := make([]string, 0)
+	for _, user := range users {
+		if !inTeam(user.Teams, teamID) {
+			continue
+		}
+		configUser := starterConfigUser{
+			ID:       user.ID,
+			Name:     user.Name,
+			Timezone: user.Timezone,
+			Calendar: configuration.GuessCalendarByTimezone(user.Timezone),
+		}
+		if configUser.Calendar == "" {
+			configUser.Calendar, configUser.Defaulted = defaultCalendar, true
+			withoutCalendar = append(withoutCalendar, fmt.Sprintf("%s (%s)", user.Name, user.Timezone))
+		}
+		config.Users = append(config.Users, configUser)
+	}
+
+	for _, schedule := range schedules {
+		if inTeam(schedule.Teams, teamID) {
+			config.Schedules = append(config.Schedules, schedule)
+		} else {
+			config.IgnoredSchedules = append(config.IgnoredSchedules, schedule)
+		}
+	}
+
+	if len(config.Users) == 0 && len(config.Schedules) == 0 {
+		return nil, fmt.Errorf("no users or schedules found for team %s", teamID)
+	}
+
+	if len(withoutCalendar) > 0 && defaultCalendar == "" {
+		return nil, fmt.Errorf("no holidays calendar known for the timezone of %s, set one with --default-calendar",
+			strings.Join(withoutCalendar, ", "))
+	}
+
+	tmpl, err := template.New("config").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(starterConfigTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var content bytes.Buffer
+	if err := tmpl.Execute(&content, config); err != nil {
+		return nil, fmt.Errorf("failed to generate configuration: %w", err)
+	}
+	return content.Bytes(), nil
+}
+
+func inTeam(teams []api.Team, teamID string) bool {
+	for _, team := range teams {
+		if team.ID == teamID {
+			return true
+		}
+	}
+	return false
+}
+
+func starterConfigFilename() (string, error) {
+	if len(cfgFiles) > 1 {
+		return "", fmt.Errorf("a single configuration file can be written, got %d", len(cfgFiles))
+	}
+	if len(cfgFiles) == 1 {
+		return cfgFiles[0], nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf("can't get the homedir: %w", err)
+	}
+	return filepath.Join(home, ".pd-report-config.yml"), nil
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func Test_pagerDutyClient_generateStarterConfig(t *testing.T) {
+	users := []*api.User{
+		{ID: "USER1", Name: "John Doe", Timezone: "Europe/London", Teams: []api.Team{{ID: "TEAM1"}}},
+		{ID: "USER2", Name: "Mary \"MJ\" Jane", Timezone: "Asia/Kolkata", Teams: []api.Team{{ID: "TEAM1"}}},
+		{ID: "USER3", Name: "Someone Else", Timezone: "Europe/Madrid", Teams: []api.Team{{ID: "TEAM2"}}},
+	}
+	schedules := []*api.Schedule{
+		{ID: "SCHED1", Name: "Team 1 primary", Teams: []api.Team{{ID: "TEAM1"}}},
+		{ID: "SCHED2", Name: "Team 2 primary", Teams: []api.Team{{ID: "TEAM2"}}},
+	}
+
+	tests := []struct {
+		name            string
+		teamID          string
+		defaultCalendar string
+		mockSetup       func(*clientMock)
+		wantErr         bool
+	}{
+		{
+			name:            "Successfully generate starter config for a team",
+			teamID:          "TEAM1",
+			defaultCalendar: "uk",
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return(users, nil)
+				clientMock.On("ListSchedules", mock.Anything).Once().Return(schedules, nil)
+			},
+		},
+		{
+			name:   "Users without a known calendar fail without a default one",
+			teamID: "TEAM1",
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return(users, nil)
+				clientMock.On("ListSchedules", mock.Anything).Once().Return(schedules, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Unknown team fails",
+			teamID: "TEAM3",
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return(users, nil)
+				clientMock.On("ListSchedules", mock.Anything).Once().Return(schedules, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "Failed to list users",
+			teamID: "TEAM1",
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return(nil, errors.New("failed"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedClient := &clientMock{}
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockedClient)
+			}
+
+			pd := pagerDutyClient{client: mockedClient}
+			got, err := pd.generateStarterConfig(context.Background(), tt.teamID, tt.defaultCalendar)
+			mockedClient.AssertExpectations(t)
+
+			if tt.wantErr == true {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var config struct {
+				RotationUsers []struct {
+					Name             string `yaml:"name"`
+					HolidaysCalendar string `yaml:"holidaysCalendar"`
+					UserID           string `yaml:"userId"`
+				} `yaml:"rotationUsers"`
+				DefaultHolidayCalendar string   `yaml:"defaultHolidayCalendar"`
+				SchedulesToIgnore      []string `yaml:"schedulesToIgnore"`
+			}
+			require.NoError(t, yaml.Unmarshal(got, &config))
+
+			require.Len(t, config.RotationUsers, 2)
+			assert.Equal(t, "USER1", config.RotationUsers[0].UserID)
+			assert.Equal(t, "John Doe", config.RotationUsers[0].Name)
+			assert.Equal(t, "uk", config.RotationUsers[0].HolidaysCalendar)
+			assert.Equal(t, "Mary \"MJ\" Jane", config.RotationUsers[1].Name)
+			assert.Equal(t, "uk", config.RotationUsers[1].HolidaysCalendar)
+			assert.Contains(t, string(got), "# no calendar known for timezone Asia/Kolkata")
+			assert.Equal(t, "uk", config.DefaultHolidayCalendar)
+			assert.Equal(t, []string{"SCHED2"}, config.SchedulesToIgnore)
+			assert.Contains(t, string(got), "#   SCHED1: Team 1 primary")
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cobra"
+)
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "validates the configuration file",
+	Long:  "Loads the configuration file and reports every problem found in it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := readConfig(); err != nil {
+			return fmt.Errorf("can't read config: %w", err)
+		}
+
+		calendars, err := configuration.AvailableCalendars()
+		if err != nil {
+			return err
+		}
+
+		return validateConfig(calendars)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
+
+func validateConfig(calendars []string) error {
+	problems := make([]string, 0)
+
+	config := configuration.New()
+	if err := unmarshalConfig(config); err != nil {
+		var decodeErr *mapstructure.Error
+		if errors.As(err, &decodeErr) {
+			problems = append(problems, decodeErr.Errors...)
+		} else {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	for _, problem := range config.Validate(calendars) {
+		problems = append(problems, problem.Error())
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("Configuration is valid")
+		return nil
+	}
+
+	fmt.Println(fmt.Sprintf("==== Found %d problem(s) ====", len(problems)))
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	return fmt.Errorf("configuration is not valid")
+}
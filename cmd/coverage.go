@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+// coverageEntry is a rendered entry clipped to the schedule's report range.
+type coverageEntry struct {
+	start    time.Time
+	end      time.Time
+	userName string
+}
+
+// getCoverage finds the gaps, with nobody on call, and the overlaps, with several users on call at once,
+// in the schedule's rendered entries over its report range.
+func getCoverage(scheduleInfo *api.ScheduleInfo) (*report.Coverage, error) {
+	rangeStart, rangeEnd := scheduleInfo.Start, scheduleInfo.End
+	if scheduleInfo.Location != nil {
+		rangeStart, rangeEnd = rangeStart.In(scheduleInfo.Location), rangeEnd.In(scheduleInfo.Location)
+	}
+	coverage := &report.Coverage{
+		ScheduleID:   scheduleInfo.ID,
+		ScheduleName: scheduleInfo.Name,
+		Start:        rangeStart,
+		End:          rangeEnd,
+		Gaps:         make([]*report.CoveragePeriod, 0),
+		Overlaps:     make([]*report.CoveragePeriod, 0),
+	}
+
+	entries := make([]coverageEntry, 0, len(scheduleInfo.FinalSchedule.RenderedScheduleEntries))
+	boundaries := []time.Time{rangeStart, rangeEnd}
+	for _, entry := range scheduleInfo.FinalSchedule.RenderedScheduleEntries {
+		start, err := time.ParseInLocation(time.RFC3339, entry.Start, scheduleInfo.Location)
+		if err != nil {
+			return nil, err
+		}
+		end, err := time.ParseInLocation(time.RFC3339, entry.End, scheduleInfo.Location)
+		if err != nil {
+			return nil, err
+		}
+		if start.Before(rangeStart) {
+			start = rangeStart
+		}
+		if end.After(rangeEnd) {
+			end = rangeEnd
+		}
+		if !start.Before(end) {
+			continue
+		}
+		entries = append(entries, coverageEntry{start: start, end: end, userName: entry.User.Summary})
+		boundaries = append(boundaries, start, end)
+	}
+	sort.Slice(boundaries, func(i, j int) bool {
+		return boundaries[i].Before(boundaries[j])
+	})
+
+	var covered time.Duration
+	for i := 1; i < len(boundaries); i++ {
+		start, end := boundaries[i-1], boundaries[i]
+		if !start.Before(end) {
+			continue
+		}
+
+		userNames := make([]string, 0)
+		for _, entry := range entries {
+			if !entry.start.After(start) && !entry.end.Before(end) && !contains(userNames, entry.userName) {
+				userNames = append(userNames, entry.userName)
+			}
+		}
+		switch {
+		case len(userNames) == 0:
+			coverage.Gaps = extendCoveragePeriods(coverage.Gaps, start, end, userNames)
+		case len(userNames) > 1:
+			covered += end.Sub(start)
+			coverage.Overlaps = extendCoveragePeriods(coverage.Overlaps, start, end, userNames)
+		default:
+			covered += end.Sub(start)
+		}
+	}
+
+	coverage.CoveredHours = float32(covered.Hours())
+	if total := rangeEnd.Sub(rangeStart); total > 0 {
+		coverage.Percentage = float32(covered.Hours() / total.Hours() * 100)
+	}
+	return coverage, nil
+}
+
+// extendCoveragePeriods adds the time to the last period when they are contiguous, or as a new period.
+func extendCoveragePeriods(periods []*report.CoveragePeriod, start, end time.Time, userNames []string) []*report.CoveragePeriod {
+	if last := len(periods) - 1; last >= 0 && periods[last].End.Equal(start) {
+		periods[last].End = end
+		periods[last].Hours = float32(end.Sub(periods[last].Start).Hours())
+		for _, userName := range userNames {
+			if !contains(periods[last].UserNames, userName) {
+				periods[last].UserNames = append(periods[last].UserNames, userName)
+			}
+		}
+		sort.Strings(periods[last].UserNames)
+		return periods
+	}
+
+	sort.Strings(userNames)
+	return append(periods, &report.CoveragePeriod{
+		Start:     start,
+		End:       end,
+		Hours:     float32(end.Sub(start).Hours()),
+		UserNames: userNames,
+	})
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getCoverage(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return parsed
+	}
+	entry := func(start, end, userName string) api.RenderedScheduleEntry {
+		return api.RenderedScheduleEntry{Start: start, End: end, User: api.User{Summary: userName}}
+	}
+
+	tests := []struct {
+		name           string
+		entries        []api.RenderedScheduleEntry
+		wantPercentage float32
+		wantGaps       []*report.CoveragePeriod
+		wantOverlaps   []*report.CoveragePeriod
+		wantErr        bool
+	}{
+		{
+			name: "Entries covering the range, clipped to it",
+			entries: []api.RenderedScheduleEntry{
+				entry("2026-08-25T08:00:00Z", "2026-09-05T08:00:00Z", "John Doe"),
+				entry("2026-09-05T08:00:00Z", "2026-09-12T08:00:00Z", "Mary Jane"),
+			},
+			wantPercentage: 100,
+			wantGaps:       []*report.CoveragePeriod{},
+			wantOverlaps:   []*report.CoveragePeriod{},
+		},
+		{
+			name: "Gaps at the start, between entries and at the end",
+			entries: []api.RenderedScheduleEntry{
+				entry("2026-09-02T00:00:00Z", "2026-09-05T00:00:00Z", "John Doe"),
+				entry("2026-09-06T00:00:00Z", "2026-09-08T00:00:00Z", "Mary Jane"),
+			},
+			wantPercentage: 62.5,
+			wantGaps: []*report.CoveragePeriod{
+				{Start: date("2026-09-01T00:00:00Z"), End: date("2026-09-02T00:00:00Z"), Hours: 24, UserNames: []string{}},
+				{Start: date("2026-09-05T00:00:00Z"), End: date("2026-09-06T00:00:00Z"), Hours: 24, UserNames: []string{}},
+				{Start: date("2026-09-08T00:00:00Z"), End: date("2026-09-09T00:00:00Z"), Hours: 24, UserNames: []string{}},
+			},
+			wantOverlaps: []*report.CoveragePeriod{},
+		},
+		{
+			name: "Overlapping entries with the users on call at once",
+			entries: []api.RenderedScheduleEntry{
+				entry("2026-09-01T00:00:00Z", "2026-09-06T00:00:00Z", "John Doe"),
+				entry("2026-09-05T00:00:00Z", "2026-09-09T00:00:00Z", "Mary Jane"),
+				entry("2026-09-05T12:00:00Z", "2026-09-06T12:00:00Z", "Mary Jane"),
+			},
+			wantPercentage: 100,
+			wantGaps:       []*report.CoveragePeriod{},
+			wantOverlaps: []*report.CoveragePeriod{
+				{Start: date("2026-09-05T00:00:00Z"), End: date("2026-09-06T00:00:00Z"), Hours: 24, UserNames: []string{"John Doe", "Mary Jane"}},
+			},
+		},
+		{
+			name: "Invalid entry date fails",
+			entries: []api.RenderedScheduleEntry{
+				entry("01/09/2026", "2026-09-09T00:00:00Z", "John Doe"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleInfo := &api.ScheduleInfo{
+				ID:            "SCHED1",
+				Name:          "Payments primary",
+				Location:      time.UTC,
+				Start:         date("2026-09-01T00:00:00Z"),
+				End:           date("2026-09-09T00:00:00Z"),
+				FinalSchedule: api.ScheduleLayer{RenderedScheduleEntries: tt.entries},
+			}
+
+			got, err := getCoverage(scheduleInfo)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "SCHED1", got.ScheduleID)
+			assert.InDelta(t, tt.wantPercentage, got.Percentage, 0.001)
+			assert.Equal(t, tt.wantGaps, got.Gaps)
+			assert.Equal(t, tt.wantOverlaps, got.Overlaps)
+		})
+	}
+}
+
+func Test_pagerDutyClient_generateReport_coverage(t *testing.T) {
+	pd := newFakeServerReport(t)
+	require.NoError(t, pd.generateReport(context.Background()))
+
+	coverage, err := os.ReadFile(filepath.Join(directory, "pagerduty_oncall_report.9-2026-Coverage.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(coverage), "Payments primary,SCHED1,coverage,")
+	// the report range, in UTC, is shown in the schedule's timezone
+	assert.Contains(t, string(coverage), "Payments primary,SCHED1,coverage,2026-09-01T01:00:00+01:00,2026-10-01T01:00:00+01:00,720,100.00,\n")
+}
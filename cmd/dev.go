@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api/fake"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	devCmd = &cobra.Command{
+		Use:   "dev",
+		Short: "development tools",
+		Long:  "Tools to develop and demo the report without a PagerDuty account",
+		// dev commands don't need the report configuration
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			applyTimeout(cmd)
+		},
+	}
+
+	fakeServerCmd = &cobra.Command{
+		Use:   "fake-server",
+		Short: "serves a fake PagerDuty API",
+		Long: "Serves the PagerDuty API endpoints used by the report from a YAML or JSON fixture file, " +
+			"point pdApi.baseUrl to it",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fixture, err := fake.LoadFixture(fixtureFile)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("Serving fake PagerDuty API on http://%s", listenAddress)
+			return serve(cmd.Context(), listenAddress, fake.NewServer(fixture))
+		},
+	}
+
+	fixtureFile   string
+	listenAddress string
+)
+
+func init() {
+	fakeServerCmd.Flags().StringVar(&fixtureFile, "fixture", "", "YAML or JSON file with the teams, users, services and schedules to serve")
+	fakeServerCmd.Flags().StringVar(&listenAddress, "listen", "localhost:8080", "address to listen on")
+	_ = fakeServerCmd.MarkFlagRequired("fixture")
+
+	devCmd.AddCommand(fakeServerCmd)
+	rootCmd.AddCommand(devCmd)
+}
+
+// serve serves the handler on the address until the context is done, on Ctrl-C or after the --timeout, then shuts
+// the server down.
+func serve(ctx context.Context, address string, handler http.Handler) error {
+	server := &http.Server{Addr: address, Handler: handler}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/spf13/cobra"
+)
+
+const fairnessRowFormat = "%-30s %9v %9v %9v %9v %9v %9v %9v %9v %10v %s"
+
+var (
+	fairnessCmd = &cobra.Command{
+		Use:   "fairness",
+		Short: "analyses how the on-call burden is shared in each schedule",
+		Long: "Computes each schedule member's share of total, weekend, bank holiday and night hours over a range " +
+			"of months, its deviation from an equal split and an inequality score per schedule",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !contains([]string{"text", "json"}, fairnessOutputFormat) {
+				return fmt.Errorf("output format %s not supported, use text or json", fairnessOutputFormat)
+			}
+			if fairnessMaxHolidayShare <= 0 || fairnessMaxHolidayShare > 1 {
+				return fmt.Errorf("max holiday share %v must be between 0 and 1", fairnessMaxHolidayShare)
+			}
+
+			apiClient, err := newAPIClient(Config)
+			if err != nil {
+				return err
+			}
+			pd := &pagerDutyClient{
+				client:              apiClient,
+				defaultUserTimezone: Config.DefaultUserTimezone,
+				scheduleWindowDays:  Config.PdAPI.ScheduleWindowDays,
+				scheduleConcurrency: Config.PdAPI.ScheduleConcurrency,
+			}
+			startDate, endDate, err := fairnessTimeRange(fairnessFrom, fairnessTo)
+			if err != nil {
+				return err
+			}
+			analysis, err := pd.analyseFairness(cmd.Context(), fairnessSchedules, startDate, endDate, fairnessMaxHolidayShare)
+			if err != nil {
+				return err
+			}
+
+			return printFairness(analysis, fairnessOutputFormat)
+		},
+	}
+
+	fairnessSchedules       []string
+	fairnessFrom            string
+	fairnessTo              string
+	fairnessMaxHolidayShare float64
+	fairnessOutputFormat    string
+)
+
+func init() {
+	fairnessCmd.Flags().StringSliceVarP(&fairnessSchedules, "schedules", "s", []string{"all"}, "schedule ids to analyse (comma-separated with no spaces), or 'all'")
+	fairnessCmd.Flags().StringVar(&fairnessFrom, "from", "", "first month to analyse, e.g. 2026-04 (default is three months ago)")
+	fairnessCmd.Flags().StringVar(&fairnessTo, "to", "", "last month to analyse, e.g. 2026-06 (default is last month)")
+	fairnessCmd.Flags().Float64Var(&fairnessMaxHolidayShare, "max-holiday-share", 0.5, "share of the bank holiday hours above which a member is highlighted")
+	fairnessCmd.Flags().StringVarP(&fairnessOutputFormat, "output-format", "o", "text", "text, json")
+	rootCmd.AddCommand(fairnessCmd)
+}
+
+// fairnessMember is the on-call burden of a schedule member, the shares are fractions of the schedule's hours.
+type fairnessMember struct {
+	Name             string  `json:"name"`
+	TotalHours       float32 `json:"totalHours"`
+	WeekendHours     float32 `json:"weekendHours"`
+	BankHolidayHours float32 `json:"bankHolidayHours"`
+	NightHours       float32 `json:"nightHours"`
+	TotalShare       float64 `json:"totalShare"`
+	WeekendShare     float64 `json:"weekendShare"`
+	BankHolidayShare float64 `json:"bankHolidayShare"`
+	NightShare       float64 `json:"nightShare"`
+	// Deviation is the total share minus the equal split
+	Deviation float64 `json:"deviation"`
+	// HolidayOverload is set when the member covered more than the maximum share of bank holidays
+	HolidayOverload bool `json:"holidayOverload"`
+}
+
+// scheduleFairness is how the on-call burden is shared in a schedule. The inequality score is the Gini
+// coefficient of the members total hours: 0 when equally shared, towards 1 when one member takes it all.
+// UnknownMembers are on call without a holidays calendar, so their hours can't be classified and they are
+// left out of the shares.
+type scheduleFairness struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	EqualShare      float64           `json:"equalShare"`
+	InequalityScore float64           `json:"inequalityScore"`
+	Members         []*fairnessMember `json:"members"`
+	UnknownMembers  []string          `json:"unknownMembers"`
+}
+
+type fairnessAnalysis struct {
+	Start           time.Time           `json:"start"`
+	End             time.Time           `json:"end"`
+	MaxHolidayShare float64             `json:"maxHolidayShare"`
+	Schedules       []*scheduleFairness `json:"schedules"`
+}
+
+// fairnessTimeRange returns the range from the start of the first month to the end of the last one,
+// by default the last three complete months.
+func fairnessTimeRange(from, to string) (time.Time, time.Time, error) {
+	location, err := Config.ReportLocation()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	now := time.Now().In(location)
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+
+	startDate := thisMonth.AddDate(0, -3, 0)
+	if from != "" {
+		if startDate, err = Config.ParseReportTime(from); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from: %w", err)
+		}
+	}
+	endDate := thisMonth
+	if to != "" {
+		lastMonth, err := Config.ParseReportTime(to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to: %w", err)
+		}
+		endDate = lastMonth.AddDate(0, 1, 0)
+	}
+	if !startDate.Before(endDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the range from %s to %s is empty", startDate.Format(time.RFC822), endDate.Format(time.RFC822))
+	}
+
+	return startDate, endDate, nil
+}
+
+// analyseFairness classifies the wall-clock on-call hours of the schedules month by month, by day type like the
+// report does but without excluded hours, so that all the shares are computed on the same hours.
+func (pd *pagerDutyClient) analyseFairness(ctx context.Context, scheduleIDs []string, startDate, endDate time.Time,
+	maxHolidayShare float64) (*fairnessAnalysis, error) {
+
+	scheduleIDs, err := pd.listScheduleIDs(ctx, scheduleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := &fairnessAnalysis{
+		Start:           startDate,
+		End:             endDate,
+		MaxHolidayShare: maxHolidayShare,
+		Schedules:       make([]*scheduleFairness, 0, len(scheduleIDs)),
+	}
+	schedules := make(map[string]*scheduleFairness)
+	members := make(map[string]map[string]*fairnessMember)
+	loadedYear := 0
+	for monthStart := startDate; monthStart.Before(endDate); monthStart = monthStart.AddDate(0, 1, 0) {
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		if monthEnd.After(endDate) {
+			monthEnd = endDate
+		}
+		if monthStart.Year() != loadedYear {
+			configuration.LoadCalendars(monthStart.Year())
+			loadedYear = monthStart.Year()
+		}
+
+		for _, scheduleID := range scheduleIDs {
+			scheduleInfo, err := pd.getScheduleInformation(ctx, scheduleID, monthStart, monthEnd)
+			if err != nil {
+				return nil, err
+			}
+			if err := pd.getScheduleOverrides(ctx, scheduleInfo); err != nil {
+				return nil, err
+			}
+			usersRotationData, err := getUsersRotationData(scheduleInfo)
+			if err != nil {
+				return nil, err
+			}
+
+			schedule, ok := schedules[scheduleID]
+			if !ok {
+				schedule = &scheduleFairness{ID: scheduleID, Name: scheduleInfo.Name, UnknownMembers: make([]string, 0)}
+				schedules[scheduleID] = schedule
+				members[scheduleID] = make(map[string]*fairnessMember)
+				analysis.Schedules = append(analysis.Schedules, schedule)
+			}
+			for _, userRotaInfo := range usersRotationData {
+				member, ok := members[scheduleID][userRotaInfo.Name]
+				if !ok {
+					member = &fairnessMember{Name: userRotaInfo.Name}
+				}
+				known, err := pd.addMemberHours(ctx, member, scheduleInfo, userRotaInfo)
+				if err != nil {
+					return nil, err
+				}
+				if !known {
+					if !contains(schedule.UnknownMembers, userRotaInfo.Name) {
+						schedule.UnknownMembers = append(schedule.UnknownMembers, userRotaInfo.Name)
+					}
+					continue
+				}
+				members[scheduleID][userRotaInfo.Name] = member
+			}
+		}
+	}
+
+	for _, schedule := range analysis.Schedules {
+		sort.Strings(schedule.UnknownMembers)
+		for _, member := range members[schedule.ID] {
+			schedule.Members = append(schedule.Members, member)
+		}
+		schedule.share(maxHolidayShare)
+	}
+	return analysis, nil
+}
+
+// listScheduleIDs returns the given schedule ids or, for 'all', the ones not ignored.
+func (pd *pagerDutyClient) listScheduleIDs(ctx context.Context, scheduleIDs []string) ([]string, error) {
+	if len(scheduleIDs) != 1 || scheduleIDs[0] != "all" {
+		return scheduleIDs, nil
+	}
+
+	schedules, err := pd.client.ListSchedules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the schedules list: %w", err)
+	}
+	scheduleIDs = make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		if Config.IsScheduleIDToIgnore(schedule.ID) {
+			log.Printf("Ignoring schedule '%s'", schedule.ID)
+			continue
+		}
+		scheduleIDs = append(scheduleIDs, schedule.ID)
+	}
+	return scheduleIDs, nil
+}
+
+// addMemberHours adds the wall-clock hours the user was on call in the schedule to the member, by day type and
+// at night in the user's timezone. It returns false for users without a holidays calendar.
+func (pd *pagerDutyClient) addMemberHours(ctx context.Context, member *fairnessMember, scheduleInfo *api.ScheduleInfo,
+	userRotaInfo *api.UserRotaInfo) (bool, error) {
+
+	rotationUserConfig, err := pd.findRotationUser(ctx, userRotaInfo)
+	if isUserNotFound(err) {
+		log.Printf("[%s] %s has no holidays calendar, left out of the shares", scheduleInfo.ID, userRotaInfo.Name)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("aborted due to failed to find user '%s': %w", userRotaInfo.ID, err)
+	}
+	userCalendar, err := userCalendar(rotationUserConfig, scheduleInfo.Start.Year())
+	if err != nil {
+		return false, fmt.Errorf("aborted due to %w for user '%s'", err, userRotaInfo.ID)
+	}
+
+	for _, period := range userRotaInfo.Periods {
+		localStart, err := pd.convertToUserLocalTimezone(ctx, period.Start, userRotaInfo.ID)
+		if err != nil {
+			return false, fmt.Errorf("aborted due to failed to convert to user local timezone: %w", err)
+		}
+		dayTypes := dayTypeHours(userCalendar, localStart, period.End)
+		member.TotalHours += dayTypes["weekday"] + dayTypes["weekend"] + dayTypes["bankholiday"]
+		member.WeekendHours += dayTypes["weekend"]
+		member.BankHolidayHours += dayTypes["bankholiday"]
+		member.NightHours += nightHours(localStart, period.End)
+	}
+	return true, nil
+}
+
+// nightHours is the time from the local start to the end at night, from midnight to the end of the night.
+func nightHours(localStart, end time.Time) float32 {
+	var hours float64
+	day := time.Date(localStart.Year(), localStart.Month(), localStart.Day(), 0, 0, 0, 0, localStart.Location())
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		from, to := day, time.Date(day.Year(), day.Month(), day.Day(), nightEndHour, 0, 0, 0, day.Location())
+		if from.Before(localStart) {
+			from = localStart
+		}
+		if to.After(end) {
+			to = end
+		}
+		if from.Before(to) {
+			hours += to.Sub(from).Hours()
+		}
+	}
+	return float32(hours)
+}
+
+// share computes the members shares, sorted by name, and the schedule's inequality score.
+func (s *scheduleFairness) share(maxHolidayShare float64) {
+	sort.Slice(s.Members, func(i, j int) bool {
+		return s.Members[i].Name < s.Members[j].Name
+	})
+	if len(s.Members) == 0 {
+		return
+	}
+	s.EqualShare = 1 / float64(len(s.Members))
+
+	var total, weekend, bankHoliday, night float32
+	for _, member := range s.Members {
+		total += member.TotalHours
+		weekend += member.WeekendHours
+		bankHoliday += member.BankHolidayHours
+		night += member.NightHours
+	}
+	totals := make([]float64, 0, len(s.Members))
+	for _, member := range s.Members {
+		member.TotalShare = fraction(member.TotalHours, total)
+		member.WeekendShare = fraction(member.WeekendHours, weekend)
+		member.BankHolidayShare = fraction(member.BankHolidayHours, bankHoliday)
+		member.NightShare = fraction(member.NightHours, night)
+		if total > 0 {
+			member.Deviation = member.TotalShare - s.EqualShare
+		}
+		member.HolidayOverload = member.BankHolidayShare > maxHolidayShare
+		totals = append(totals, float64(member.TotalHours))
+	}
+	s.InequalityScore = giniCoefficient(totals)
+}
+
+func fraction(value, total float32) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total)
+}
+
+// giniCoefficient returns the mean absolute difference of the values relative to twice their mean.
+func giniCoefficient(values []float64) float64 {
+	var sum, differences float64
+	for _, value := range values {
+		sum += value
+		for _, other := range values {
+			differences += math.Abs(value - other)
+		}
+	}
+	if sum == 0 {
+		return 0
+	}
+	return differences / (2 * float64(len(values)) * sum)
+}
+
+func printFairness(analysis *fairnessAnalysis, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(analysis)
+	}
+
+	log.Printf("Analysed on-call hours from %s to %s", analysis.Start.Format(time.RFC822), analysis.End.Format(time.RFC822))
+	percent := func(value float64) string {
+		return fmt.Sprintf("%.1f%%", value*100)
+	}
+
+	for _, schedule := range analysis.Schedules {
+		fmt.Println(fmt.Sprintf("==== [%s] %s: %d member(s), equal share %s, inequality score %.2f ====",
+			schedule.ID, schedule.Name, len(schedule.Members), percent(schedule.EqualShare), schedule.InequalityScore))
+		fmt.Println(fmt.Sprintf(fairnessRowFormat, "USER", "HOURS", "SHARE", "WEEKEND", "SHARE", "B. HOL.", "SHARE", "NIGHT", "SHARE", "DEVIATION", ""))
+		for _, member := range schedule.Members {
+			highlight := ""
+			if member.HolidayOverload {
+				highlight = fmt.Sprintf("<- over %s of the bank holidays", percent(analysis.MaxHolidayShare))
+			}
+			fmt.Println(fmt.Sprintf(fairnessRowFormat, member.Name,
+				member.TotalHours, percent(member.TotalShare),
+				member.WeekendHours, percent(member.WeekendShare),
+				member.BankHolidayHours, percent(member.BankHolidayShare),
+				member.NightHours, percent(member.NightShare),
+				fmt.Sprintf("%+.1f%%", member.Deviation*100), highlight))
+		}
+		if len(schedule.UnknownMembers) > 0 {
+			fmt.Println(fmt.Sprintf("Unknown member(s) without a holidays calendar, left out of the shares: %s",
+				strings.Join(schedule.UnknownMembers, ", ")))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_scheduleFairness_share(t *testing.T) {
+	tests := []struct {
+		name                string
+		members             []*fairnessMember
+		wantTotalShares     []float64
+		wantDeviations      []float64
+		wantHolidayOverload []bool
+		wantInequalityScore float64
+	}{
+		{
+			name: "Equally shared hours",
+			members: []*fairnessMember{
+				{Name: "Mary Jane", TotalHours: 100, BankHolidayHours: 12},
+				{Name: "John Doe", TotalHours: 100, BankHolidayHours: 12},
+			},
+			wantTotalShares:     []float64{0.5, 0.5},
+			wantDeviations:      []float64{0, 0},
+			wantHolidayOverload: []bool{false, false},
+		},
+		{
+			name: "Unequally shared hours and holidays",
+			members: []*fairnessMember{
+				{Name: "John Doe", TotalHours: 300, BankHolidayHours: 24},
+				{Name: "Mary Jane", TotalHours: 100},
+			},
+			wantTotalShares:     []float64{0.75, 0.25},
+			wantDeviations:      []float64{0.25, -0.25},
+			wantHolidayOverload: []bool{true, false},
+			wantInequalityScore: 0.25,
+		},
+		{
+			name: "Schedule without hours",
+			members: []*fairnessMember{
+				{Name: "John Doe"},
+			},
+			wantTotalShares:     []float64{0},
+			wantDeviations:      []float64{0},
+			wantHolidayOverload: []bool{false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := &scheduleFairness{Members: tt.members}
+			schedule.share(0.5)
+
+			for i, member := range schedule.Members {
+				assert.InDelta(t, tt.wantTotalShares[i], member.TotalShare, 0.0001, member.Name)
+				assert.InDelta(t, tt.wantDeviations[i], member.Deviation, 0.0001, member.Name)
+				assert.Equal(t, tt.wantHolidayOverload[i], member.HolidayOverload, member.Name)
+			}
+			assert.InDelta(t, tt.wantInequalityScore, schedule.InequalityScore, 0.0001)
+		})
+	}
+}
+
+func Test_fairnessTimeRange(t *testing.T) {
+	Config = configuration.New()
+	t.Cleanup(func() {
+		Config = nil
+	})
+
+	startDate, endDate, err := fairnessTimeRange("2026-04", "2026-06")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), startDate)
+	assert.Equal(t, time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC), endDate)
+
+	startDate, endDate, err = fairnessTimeRange("", "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, endDate.Day())
+	assert.Equal(t, endDate.AddDate(0, -3, 0), startDate)
+
+	_, _, err = fairnessTimeRange("2026-06", "2026-04")
+	assert.Error(t, err)
+}
+
+func Test_pagerDutyClient_analyseFairness(t *testing.T) {
+	pd := newFakeServerReport(t)
+	startDate := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	analysis, err := pd.analyseFairness(context.Background(), []string{"SCHED1"}, startDate, startDate.AddDate(0, 1, 0), 0.5)
+	require.NoError(t, err)
+
+	require.Len(t, analysis.Schedules, 1)
+	schedule := analysis.Schedules[0]
+	assert.Equal(t, "Payments primary", schedule.Name)
+	assert.Equal(t, 0.5, schedule.EqualShare)
+	require.Len(t, schedule.Members, 2)
+
+	// all the wall-clock hours of the month are shared, the early hours of the 1st included
+	john, mary := schedule.Members[0], schedule.Members[1]
+	assert.Equal(t, "John Doe", john.Name)
+	assert.InDelta(t, 336, john.TotalHours, 0.0001)
+	assert.InDelta(t, 49, john.WeekendHours, 0.0001)
+	assert.Equal(t, "Mary Jane", mary.Name)
+	assert.InDelta(t, 384, mary.TotalHours, 0.0001)
+	assert.InDelta(t, 143, mary.WeekendHours, 0.0001)
+	assert.InDelta(t, 336.0/720, john.TotalShare, 0.0001)
+	assert.InDelta(t, 336.0/720-0.5, john.Deviation, 0.0001)
+	// 30 nights of 7 hours, John Doe's weekend nights covered by Mary Jane
+	assert.InDelta(t, 210, john.NightHours+mary.NightHours, 0.0001)
+	assert.InDelta(t, 1-john.NightShare, mary.NightShare, 0.0001)
+	assert.InDelta(t, 48.0/(2*720), schedule.InequalityScore, 0.0001)
+	assert.Empty(t, schedule.UnknownMembers)
+}
+
+func Test_pagerDutyClient_analyseFairness_unknownMembers(t *testing.T) {
+	pd := newFakeServerReport(t)
+	Config.DefaultHolidayCalendar = ""
+	Config.RotationUsers = []configuration.RotationUser{{UserID: "USER1", HolidaysCalendar: "uk"}}
+	startDate := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+
+	analysis, err := pd.analyseFairness(context.Background(), []string{"SCHED1"}, startDate, startDate.AddDate(0, 1, 0), 0.5)
+	require.NoError(t, err)
+
+	require.Len(t, analysis.Schedules, 1)
+	schedule := analysis.Schedules[0]
+	assert.Equal(t, []string{"Mary Jane"}, schedule.UnknownMembers)
+	require.Len(t, schedule.Members, 1)
+	assert.Equal(t, "John Doe", schedule.Members[0].Name)
+}
+
+func Test_nightHours(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  float32
+	}{
+		{
+			name:  "daytime",
+			start: time.Date(2026, time.September, 1, 8, 0, 0, 0, london),
+			end:   time.Date(2026, time.September, 1, 20, 0, 0, 0, london),
+		},
+		{
+			name:  "starting and ending at night",
+			start: time.Date(2026, time.September, 1, 5, 30, 0, 0, london),
+			end:   time.Date(2026, time.September, 3, 2, 0, 0, 0, london),
+			want:  1.5 + 7 + 2,
+		},
+		{
+			name:  "over a clock change",
+			start: time.Date(2026, time.October, 25, 0, 0, 0, 0, london),
+			end:   time.Date(2026, time.October, 25, 12, 0, 0, 0, london),
+			want:  8,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, nightHours(tt.start, tt.end), 0.0001)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	forecastCmd = &cobra.Command{
+		Use:   "forecast",
+		Short: "projects the on-call cost of the coming months",
+		Long: "Renders the schedules of the coming months, starting next month, and prices them with the current " +
+			"configuration and calendars to project the cost per schedule, team and user",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if forecastMonths < 1 {
+				return fmt.Errorf("months %d must be at least 1", forecastMonths)
+			}
+
+			apiClient, err := newAPIClient(Config)
+			if err != nil {
+				return err
+			}
+			pd := &pagerDutyClient{
+				client:              apiClient,
+				defaultUserTimezone: Config.DefaultUserTimezone,
+				scheduleWindowDays:  Config.PdAPI.ScheduleWindowDays,
+				scheduleConcurrency: Config.PdAPI.ScheduleConcurrency,
+			}
+			location, err := Config.ReportLocation()
+			if err != nil {
+				return err
+			}
+			now := time.Now().In(location)
+			nextMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location).AddDate(0, 1, 0)
+
+			return pd.generateForecast(cmd.Context(), nextMonth, forecastMonths)
+		},
+	}
+
+	forecastMonths int
+)
+
+func init() {
+	forecastCmd.Flags().IntVar(&forecastMonths, "months", 3, "number of months to forecast, starting next month")
+	forecastCmd.Flags().StringSliceVarP(&rawSchedules, "schedules", "s", []string{"all"}, "schedule ids to forecast (comma-separated with no spaces), or 'all'")
+	forecastCmd.Flags().StringVarP(&outputFormat, "output-format", "o", "console", "pdf, console, csv, json")
+	forecastCmd.Flags().StringVarP(&directory, "output", "d", "", "output path (default is $HOME)")
+	rootCmd.AddCommand(forecastCmd)
+}
+
+// generateForecast writes the report of the schedules over the given months, marked as a forecast. Incidents
+// are not projected, so there are no callouts nor active time.
+func (pd *pagerDutyClient) generateForecast(ctx context.Context, startDate time.Time, months int) error {
+	processOutputArguments()
+	scheduleIDs, err := pd.listScheduleIDs(ctx, rawSchedules)
+	if err != nil {
+		return err
+	}
+
+	pricesInfo, err := getPricesInfo()
+	if err != nil {
+		return err
+	}
+
+	endDate := startDate.AddDate(0, months, 0)
+	printableData := newPrintableData(startDate, endDate)
+	printableData.Forecast = &report.Forecast{Months: months, Warnings: make([]string, 0)}
+	years := make([]int, 0)
+	for year := startDate.Year(); year <= endDate.AddDate(0, 0, -1).Year(); year++ {
+		years = append(years, year)
+	}
+	configuration.LoadCalendars(years...)
+	for _, year := range years {
+		printableData.Forecast.Warnings = append(printableData.Forecast.Warnings, addMissingCalendars(year)...)
+	}
+
+	rotations, err := pd.addSchedulesData(ctx, printableData, forecastSchedules(scheduleIDs, startDate, endDate), pricesInfo)
+	if err != nil {
+		return err
+	}
+
+	printableData.ComplianceViolations, err = pd.checkCompliance(ctx, rotations)
+	if err != nil {
+		return err
+	}
+
+	printableData.Forecast.Schedules, printableData.Forecast.Teams = scheduleAndTeamCosts(rotations)
+	printableData.UsersSchedulesSummary = calculateSummaryData(printableData.SchedulesData, pricesInfo)
+	applyPayCaps(printableData.UsersSchedulesSummary, rotations)
+	printableData.InferredCalendars = pd.getInferredCalendars()
+
+	message, err := newReportWriter().GenerateReport(ctx, printableData)
+	if err != nil {
+		return err
+	}
+
+	if len(message) > 0 {
+		log.Println(message)
+	}
+	return nil
+}
+
+// forecastSchedules returns the schedules to price over the whole forecast at once, so shifts, weekends and weeks
+// across months are priced in full. Like in a monthly report, the last rota day runs until the daily rotation start.
+func forecastSchedules(scheduleIDs []string, startDate, endDate time.Time) []Schedule {
+	schedules := make([]Schedule, 0, len(scheduleIDs))
+	for _, scheduleID := range scheduleIDs {
+		schedules = append(schedules, Schedule{
+			id:        scheduleID,
+			startDate: startDate,
+			endDate:   endDate.Add(time.Hour * time.Duration(Config.RotationInfo.DailyRotationStartsAt)),
+		})
+	}
+	return schedules
+}
+
+// addMissingCalendars adds an empty calendar for each configured holidays calendar without bank holidays
+// for the year, so its days are priced as regular days, and returns a warning for each of them.
+func addMissingCalendars(year int) []string {
+	calendarNames := make([]string, 0, len(Config.RotationUsers)+len(Config.TimezoneCalendars)+1)
+	for _, rotationUser := range Config.RotationUsers {
+		calendarNames = append(calendarNames, rotationUser.HolidaysCalendar)
+	}
+	for _, timezoneCalendar := range Config.TimezoneCalendars {
+		calendarNames = append(calendarNames, timezoneCalendar.Calendar)
+	}
+	calendarNames = append(calendarNames, Config.DefaultHolidayCalendar)
+
+	warnings := make([]string, 0)
+	for _, calendarName := range calendarNames {
+		key := fmt.Sprintf("%s-%d", calendarName, year)
+		if _, present := configuration.BankHolidaysCalendars[key]; calendarName == "" || present {
+			continue
+		}
+
+		configuration.BankHolidaysCalendars[key] = configuration.BHCalendar{DaysMaps: map[string]configuration.BankHoliday{}}
+		warning := fmt.Sprintf("calendar '%s' not found, its bank holidays are priced as regular days", key)
+		log.Println(warning)
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+// scheduleAndTeamCosts sums the adjusted cost of the rotations per schedule and per team. A schedule of several teams
+// counts towards each of them.
+func scheduleAndTeamCosts(rotations []*scheduleRotation) ([]*report.ProjectedCost, []*report.ProjectedCost) {
+	schedules := make([]*report.ProjectedCost, 0)
+	teams := make([]*report.ProjectedCost, 0)
+	scheduleCosts := make(map[string]*report.ProjectedCost)
+	teamCosts := make(map[string]*report.ProjectedCost)
+	for _, rotation := range rotations {
+		var amount float32
+		for _, scheduleUser := range rotation.data.RotaUsers {
+			amount += scheduleUser.AdjustedTotalAmount()
+		}
+
+		scheduleCost, ok := scheduleCosts[rotation.info.ID]
+		if !ok {
+			scheduleCost = &report.ProjectedCost{ID: rotation.info.ID, Name: rotation.info.Name}
+			scheduleCosts[rotation.info.ID] = scheduleCost
+			schedules = append(schedules, scheduleCost)
+		}
+		scheduleCost.TotalAmount += amount
+
+		for _, team := range rotation.info.Teams {
+			teamCost, ok := teamCosts[team.ID]
+			if !ok {
+				teamCost = &report.ProjectedCost{ID: team.ID, Name: team.Name}
+				teamCosts[team.ID] = teamCost
+				teams = append(teams, teamCost)
+			}
+			teamCost.TotalAmount += amount
+		}
+	}
+
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Name < schedules[j].Name
+	})
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+	return schedules, teams
+}
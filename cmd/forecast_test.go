@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_addMissingCalendars(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       *configuration.Configuration
+		wantWarnings []string
+		wantAdded    []string
+	}{
+		{
+			name:         "calendars of the year are loaded",
+			config:       &configuration.Configuration{DefaultHolidayCalendar: "uk"},
+			wantWarnings: []string{},
+		},
+		{
+			name: "missing calendars are added empty",
+			config: &configuration.Configuration{
+				DefaultHolidayCalendar: "uk",
+				RotationUsers:          []configuration.RotationUser{{UserID: "USER1", HolidaysCalendar: "atlantis"}},
+				TimezoneCalendars:      []configuration.TimezoneCalendar{{Timezone: "Pacific/Nauru", Calendar: "lemuria"}},
+			},
+			wantWarnings: []string{
+				"calendar 'atlantis-2026' not found, its bank holidays are priced as regular days",
+				"calendar 'lemuria-2026' not found, its bank holidays are priced as regular days",
+			},
+			wantAdded: []string{"atlantis-2026", "lemuria-2026"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = tt.config
+			defer func() { Config = nil }()
+			configuration.LoadCalendars(2026)
+
+			assert.Equal(t, tt.wantWarnings, addMissingCalendars(2026))
+			for _, key := range tt.wantAdded {
+				assert.Empty(t, configuration.BankHolidaysCalendars[key].DaysMaps, key)
+				assert.Contains(t, configuration.BankHolidaysCalendars, key)
+			}
+			assert.NotEmpty(t, configuration.BankHolidaysCalendars["uk-2026"].DaysMaps)
+		})
+	}
+}
+
+func Test_scheduleAndTeamCosts(t *testing.T) {
+	rotation := func(id, name string, amount float32, teams ...api.Team) *scheduleRotation {
+		return &scheduleRotation{
+			info: &api.ScheduleInfo{ID: id, Name: name, Teams: teams},
+			data: &report.ScheduleData{RotaUsers: []*report.ScheduleUser{{TotalAmount: amount}, {TotalAmount: 1}}},
+		}
+	}
+	payments := api.Team{ID: "TEAM1", Name: "Payments"}
+	platform := api.Team{ID: "TEAM2", Name: "Platform"}
+
+	schedules, teams := scheduleAndTeamCosts([]*scheduleRotation{
+		rotation("SCHED2", "Platform primary", 10, platform),
+		rotation("SCHED1", "Payments primary", 4, payments, platform),
+		rotation("SCHED1", "Payments primary", 5, payments, platform),
+		rotation("SCHED3", "Unowned", 2),
+	})
+
+	assert.Equal(t, []*report.ProjectedCost{
+		{ID: "SCHED1", Name: "Payments primary", TotalAmount: 11},
+		{ID: "SCHED2", Name: "Platform primary", TotalAmount: 11},
+		{ID: "SCHED3", Name: "Unowned", TotalAmount: 3},
+	}, schedules)
+	assert.Equal(t, []*report.ProjectedCost{
+		{ID: "TEAM1", Name: "Payments", TotalAmount: 11},
+		{ID: "TEAM2", Name: "Platform", TotalAmount: 22},
+	}, teams)
+}
+
+func Test_forecastSchedules(t *testing.T) {
+	newTestConfig(t)
+	startDate := time.Date(2027, time.December, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2028, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []Schedule{
+		{id: "SCHED1", startDate: startDate, endDate: endDate.Add(8 * time.Hour)},
+		{id: "SCHED2", startDate: startDate, endDate: endDate.Add(8 * time.Hour)},
+	}, forecastSchedules([]string{"SCHED1", "SCHED2"}, startDate, endDate))
+}
+
+func Test_generateScheduleData_acrossMonths(t *testing.T) {
+	newTestConfig(t)
+	Config.PayAdjustments.Minimums = []configuration.PayMinimum{{Per: "shift", MinHours: 168, Amount: 300}}
+	Config.Stipends = []configuration.Stipend{
+		{Pattern: "fullWeekend", Amount: 10},
+		{Pattern: "isoWeek", MinHours: 150, Amount: 20},
+	}
+	monday := time.Date(2027, time.December, 27, 8, 0, 0, 0, time.UTC)
+
+	users := generateTestRangeScheduleUsers(t,
+		time.Date(2027, time.December, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, time.February, 1, 8, 0, 0, 0, time.UTC),
+		api.ScheduleUserRotationData{
+			"USER1": {ID: "USER1", Name: "John Doe", Periods: []*api.UserRotaPeriod{{Start: monday, End: monday.AddDate(0, 0, 7)}}},
+		})
+
+	// the weekly shift over the new year is priced in full, with the early hours of the 1st of January on the
+	// 31st of December, so its minimum and its ISO week and weekend stipends are paid
+	user := users["John Doe"]
+	assert.InDelta(t, 120, user.NumWorkHours, 0.0001)
+	assert.InDelta(t, 48, user.NumWeekendHours, 0.0001)
+	assert.Equal(t, 2, user.NumStipends)
+	assert.InDelta(t, 30, user.TotalAmountStipends, 0.0001)
+	assert.InDelta(t, 84, user.TotalAmountAdjustment, 0.0001)
+}
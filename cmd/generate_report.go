@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
@@ -19,23 +22,38 @@ var (
 		Short: "generates the report(s) for the given schedule(s) id(s)",
 		Long:  "Generates the report of the given list of schedules or all (except the ignored ones configured in yml)",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			apiClient, err := newAPIClient(Config)
+			if err != nil {
+				return err
+			}
 			pd := &pagerDutyClient{
-				client:              api.NewPagerDutyAPIClient(Config.PdAuthToken),
+				client:              apiClient,
 				defaultUserTimezone: Config.DefaultUserTimezone,
+				scheduleWindowDays:  Config.PdAPI.ScheduleWindowDays,
+				scheduleConcurrency: Config.PdAPI.ScheduleConcurrency,
 			}
-			return pd.generateReport()
+			return pd.generateReport(cmd.Context())
 		},
 	}
 
 	rawSchedules []string
 	outputFormat string
 	directory    string
+	strict       bool
+	metrics      bool
+
+	failOnCompliance bool
+	failOnThreshold  bool
 )
 
 func init() {
 	scheduleReportCmd.Flags().StringSliceVarP(&rawSchedules, "schedules", "s", []string{"all"}, "schedule ids to report (comma-separated with no spaces), or 'all'")
-	scheduleReportCmd.Flags().StringVarP(&outputFormat, "output-format", "o", "console", "pdf, console, csv")
+	scheduleReportCmd.Flags().StringVarP(&outputFormat, "output-format", "o", "console", "pdf, console, csv, json")
 	scheduleReportCmd.Flags().StringVarP(&directory, "output", "d", "", "output path (default is $HOME)")
+	scheduleReportCmd.Flags().BoolVar(&strict, "strict", false, "exit with an error if on-call periods overlap users leave")
+	scheduleReportCmd.Flags().BoolVar(&metrics, "metrics", false, "include the on-call responsiveness metrics of the users")
+	scheduleReportCmd.Flags().BoolVar(&failOnCompliance, "fail-on-compliance", false, "exit with an error if on-call periods break the compliance rules")
+	scheduleReportCmd.Flags().BoolVar(&failOnThreshold, "fail-on-threshold", false, "exit with an error if budgets or user soft caps are exceeded")
 	rootCmd.AddCommand(scheduleReportCmd)
 }
 
@@ -54,60 +72,57 @@ func contains(s []string, e string) bool {
 	return false
 }
 
-func (pd *pagerDutyClient) processArguments() []Schedule {
-	if !contains([]string{"console", "pdf", "csv"}, outputFormat) {
+// processOutputArguments defaults the output format and directory of the report writers.
+func processOutputArguments() {
+	if !contains([]string{"console", "pdf", "csv", "json"}, outputFormat) {
 		log.Printf("output format %s not supported. Defaulting to 'console'", outputFormat)
 		outputFormat = "console"
 	}
 	if directory == "" {
 		directory, _ = homedir.Dir()
 	}
-	now := time.Now()
-	lastMonth := now.AddDate(0, -1, 0)
+}
 
-	var defaultStartDate time.Time
-	if Config.ReportTimeRange.Start != "" {
-		var err error
-		defaultStartDate, err = time.Parse(time.RFC822, Config.ReportTimeRange.Start)
-		if err != nil {
-			log.Fatalf("Error parsing report start time: %s", err)
-		}
-	} else {
-		defaultStartDate = time.Date(lastMonth.Year(), lastMonth.Month(), 1, 0, 0, 0, 0, time.UTC)
+func newReportWriter() report.Writer {
+	switch outputFormat {
+	case "pdf":
+		return report.NewPDFReport(Config.RotationPrices.Currency, directory)
+	case "csv":
+		return report.NewCsvReport(Config.RotationPrices.Currency, directory)
+	case "json":
+		return report.NewJSONReport(Config.RotationPrices.Currency, directory)
+	default:
+		return report.NewConsoleReport(Config.RotationPrices.Currency)
 	}
+}
 
-	var defaultEndDate time.Time
-	if Config.ReportTimeRange.End != "" {
-		var err error
-		defaultEndDate, err = time.Parse(time.RFC822, Config.ReportTimeRange.End)
-		if err != nil {
-			log.Fatalf("Error parsing report end time: %s", err)
-		}
-	} else {
-		defaultEndDate = defaultStartDate.AddDate(0, 1, 0)
-		defaultEndDate = defaultEndDate.Add(time.Hour * time.Duration(Config.RotationInfo.DailyRotationStartsAt))
+func (pd *pagerDutyClient) processArguments(ctx context.Context) ([]Schedule, error) {
+	processOutputArguments()
+
+	defaultStartDate, defaultEndDate, err := defaultReportTimeRange()
+	if err != nil {
+		return nil, err
 	}
 
 	startOverrides := make(map[string]time.Time)
 	endOverrides := make(map[string]time.Time)
 
-	for _, override := range Config.ScheduleTimeRangeOverrides {
-		var err error
-		startOverrides[override.Id], err = time.Parse(time.RFC822, override.Start)
+	for i, override := range Config.ScheduleTimeRangeOverrides {
+		startOverrides[override.Id], err = Config.ParseReportTime(override.Start)
 		if err != nil {
-			log.Fatalf("Error parsing start time override for schedule %s: %v", override.Id, err)
+			return nil, fmt.Errorf("scheduleTimeRangeOverrides[%d].start (schedule %s): %w", i, override.Id, err)
 		}
-		endOverrides[override.Id], err = time.Parse(time.RFC822, override.End)
+		endOverrides[override.Id], err = Config.ParseReportTime(override.End)
 		if err != nil {
-			log.Fatalf("Error parsing end time override for schedule %s: %v", override.Id, err)
+			return nil, fmt.Errorf("scheduleTimeRangeOverrides[%d].end (schedule %s): %w", i, override.Id, err)
 		}
 	}
 
 	schedules := make([]Schedule, 0)
 	if len(rawSchedules) == 1 && rawSchedules[0] == "all" {
-		schedulesList, err := pd.client.ListSchedules()
+		schedulesList, err := pd.client.ListSchedules(ctx)
 		if err != nil {
-			log.Fatalln(fmt.Sprintf("Error getting the schedules list: %s", err.Error()))
+			return nil, fmt.Errorf("error getting the schedules list: %w", err)
 		}
 
 		for _, schedule := range schedulesList {
@@ -168,16 +183,53 @@ func (pd *pagerDutyClient) processArguments() []Schedule {
 
 				log.Printf("[%s] defaultStartDate: %s, defaultEndDate: %s", schedule, thisStartDate, thisEndDate)
 			} else {
-				log.Fatal("Configuration explicitly ignores schedule passed as parameter - check your config.")
+				return nil, fmt.Errorf("configuration explicitly ignores schedule %s passed as parameter - check your config", schedule)
 			}
 		}
 	}
 
-	return schedules
+	return schedules, nil
 }
 
-func (pd *pagerDutyClient) generateReport() error {
-	input := pd.processArguments()
+// defaultReportTimeRange returns the configured report time range, last month by default.
+func defaultReportTimeRange() (time.Time, time.Time, error) {
+	location, err := Config.ReportLocation()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	now := time.Now().In(location)
+	lastMonth := now.AddDate(0, -1, 0)
+
+	var defaultStartDate time.Time
+	if Config.ReportTimeRange.Start != "" {
+		defaultStartDate, err = Config.ParseReportTime(Config.ReportTimeRange.Start)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("reportTimeRange.start: %w", err)
+		}
+	} else {
+		defaultStartDate = time.Date(lastMonth.Year(), lastMonth.Month(), 1, 0, 0, 0, 0, location)
+	}
+
+	var defaultEndDate time.Time
+	if Config.ReportTimeRange.End != "" {
+		defaultEndDate, err = Config.ParseReportTime(Config.ReportTimeRange.End)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("reportTimeRange.end: %w", err)
+		}
+	} else {
+		defaultEndDate = defaultStartDate.AddDate(0, 1, 0)
+		defaultEndDate = defaultEndDate.Add(time.Hour * time.Duration(Config.RotationInfo.DailyRotationStartsAt))
+	}
+
+	return defaultStartDate, defaultEndDate, nil
+}
+
+func (pd *pagerDutyClient) generateReport(ctx context.Context) error {
+	input, err := pd.processArguments(ctx)
+	if err != nil {
+		return err
+	}
 
 	firstStartDate := time.Now()
 	lastEndDate := time.Time{}
@@ -190,62 +242,147 @@ func (pd *pagerDutyClient) generateReport() error {
 			lastEndDate = schedule.endDate
 		}
 	}
-	configuration.LoadCalendars(firstStartDate.Year())
-	printableData := &report.PrintableData{
-		Start:         firstStartDate,
-		End:           lastEndDate,
+	loadReportCalendars(firstStartDate, lastEndDate)
+	printableData := newPrintableData(firstStartDate, lastEndDate)
+
+	pricesInfo, err := getPricesInfo()
+	if err != nil {
+		return err
+	}
+
+	rotations, err := pd.addSchedulesData(ctx, printableData, input, pricesInfo)
+	if err != nil {
+		return err
+	}
+
+	var incidentLogs []*incidentLog
+	if len(Config.CalloutPrices) > 0 || len(Config.ActiveRates) > 0 || metrics {
+		incidentLogs, err = pd.getIncidentLogs(ctx, firstStartDate, lastEndDate, rotations)
+		if err != nil {
+			return err
+		}
+	}
+	printableData.Callouts, err = pd.getIncidentPayments(ctx, incidentLogs, rotations)
+	if err != nil {
+		return err
+	}
+	if metrics {
+		printableData.Metrics, err = pd.getResponseMetrics(ctx, incidentLogs, rotations)
+		if err != nil {
+			return err
+		}
+	}
+
+	printableData.ComplianceViolations, err = pd.checkCompliance(ctx, rotations)
+	if err != nil {
+		return err
+	}
+
+	summaryPrintableData := calculateSummaryData(printableData.SchedulesData, pricesInfo)
+	applyPayCaps(summaryPrintableData, rotations)
+	printableData.UsersSchedulesSummary = summaryPrintableData
+	printableData.InferredCalendars = pd.getInferredCalendars()
+	printableData.ThresholdWarnings = checkThresholds(printableData, rotations)
+
+	message, err := newReportWriter().GenerateReport(ctx, printableData)
+	if err != nil {
+		return err
+	}
+
+	if len(message) > 0 {
+		log.Println(message)
+	}
+
+	if strict && len(printableData.Conflicts) > 0 {
+		return fmt.Errorf("found %d on-call period(s) overlapping users leave", len(printableData.Conflicts))
+	}
+	if failOnCompliance && len(printableData.ComplianceViolations) > 0 {
+		return fmt.Errorf("found %d compliance rule violation(s)", len(printableData.ComplianceViolations))
+	}
+	if failOnThreshold && len(printableData.ThresholdWarnings) > 0 {
+		return fmt.Errorf("found %d exceeded threshold(s)", len(printableData.ThresholdWarnings))
+	}
+	return nil
+}
+
+// loadReportCalendars loads the calendars of every year of the report range. The local days of users ahead or behind
+// the schedules' timezone can spill into the years around the range, their missing calendars are added empty.
+func loadReportCalendars(startDate, endDate time.Time) {
+	years := make([]int, 0)
+	for year := startDate.Year() - 1; year <= endDate.Year()+1; year++ {
+		years = append(years, year)
+	}
+	configuration.LoadCalendars(years...)
+	addMissingCalendars(years[0])
+	addMissingCalendars(years[len(years)-1])
+}
+
+func newPrintableData(startDate, endDate time.Time) *report.PrintableData {
+	return &report.PrintableData{
+		Start:         startDate,
+		End:           endDate,
 		SchedulesData: make([]*report.ScheduleData, 0),
+		Conflicts:     make([]*report.Conflict, 0),
+		Overrides:     make([]*report.Override, 0),
+		Coverage:      make([]*report.Coverage, 0),
 	}
+}
 
+func getPricesInfo() (*configuration.PricesInfo, error) {
 	pricesInfo, err := Config.GetPricesInfo()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	log.Println(fmt.Sprintf("Hourly prices (in %s) - Week day: %v (%vh), Weekend day: %v (%vh), Bank holiday: %v (%vh)",
 		Config.RotationPrices.Currency, pricesInfo.WeekDayHourlyPrice, pricesInfo.HoursWeekDay, pricesInfo.WeekendDayHourlyPrice,
 		pricesInfo.HoursWeekendDay, pricesInfo.BhDayHourlyPrice, pricesInfo.HoursBhDay))
+	return pricesInfo, nil
+}
+
+// addSchedulesData adds the priced on-call data of the schedules to the report data, with their overrides,
+// coverage and leave conflicts, and returns their rotations.
+func (pd *pagerDutyClient) addSchedulesData(ctx context.Context, printableData *report.PrintableData, input []Schedule,
+	pricesInfo *configuration.PricesInfo) ([]*scheduleRotation, error) {
 
+	rotations := make([]*scheduleRotation, 0, len(input))
 	for _, schedule := range input {
 		log.Printf("Loading information for the schedule '%s'", schedule.id)
-		scheduleInfo, err := pd.getScheduleInformation(schedule.id, schedule.startDate, schedule.endDate)
+		scheduleInfo, err := pd.getScheduleInformation(ctx, schedule.id, schedule.startDate, schedule.endDate)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if err := pd.getScheduleOverrides(ctx, scheduleInfo); err != nil {
+			return nil, err
 		}
 
 		usersRotationData, err := getUsersRotationData(scheduleInfo)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		coverage, err := getCoverage(scheduleInfo)
+		if err != nil {
+			return nil, err
 		}
+		printableData.Coverage = append(printableData.Coverage, coverage)
 
-		scheduleData, err := pd.generateScheduleData(scheduleInfo, usersRotationData, pricesInfo, schedule)
+		scheduleData, usersPay, err := pd.generateScheduleData(ctx, scheduleInfo, usersRotationData, pricesInfo, schedule)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		printableData.SchedulesData = append(printableData.SchedulesData, scheduleData)
-	}
-
-	summaryPrintableData := calculateSummaryData(printableData.SchedulesData, pricesInfo)
-	printableData.UsersSchedulesSummary = summaryPrintableData
+		rotations = append(rotations, &scheduleRotation{info: scheduleInfo, users: usersRotationData, data: scheduleData, pay: usersPay})
+		printableData.Overrides = append(printableData.Overrides, getOverrides(scheduleInfo, usersRotationData)...)
 
-	var reportWriter report.Writer
-	if outputFormat == "pdf" {
-		reportWriter = report.NewPDFReport(Config.RotationPrices.Currency, directory)
-	} else if outputFormat == "csv" {
-		reportWriter = report.NewCsvReport(Config.RotationPrices.Currency, directory)
-	} else {
-		reportWriter = report.NewConsoleReport(Config.RotationPrices.Currency)
-	}
-	message, err := reportWriter.GenerateReport(printableData)
-	if err != nil {
-		return err
+		conflicts, err := pd.findLeaveConflicts(ctx, scheduleInfo, usersRotationData)
+		if err != nil {
+			return nil, err
+		}
+		printableData.Conflicts = append(printableData.Conflicts, conflicts...)
 	}
 
-	if len(message) > 0 {
-		log.Println(message)
-	}
-	return nil
+	return rotations, nil
 }
 
 func calculateSummaryData(data []*report.ScheduleData, pricesInfo *configuration.PricesInfo) []*report.ScheduleUser {
@@ -258,6 +395,7 @@ func calculateSummaryData(data []*report.ScheduleData, pricesInfo *configuration
 				userSummary = &report.ScheduleUser{
 					Name:         schedUser.Name,
 					EmailAddress: schedUser.EmailAddress,
+					CoveredFor:   make([]string, 0),
 				}
 				usersSummary[schedUser.Name] = userSummary
 			}
@@ -269,6 +407,21 @@ func calculateSummaryData(data []*report.ScheduleData, pricesInfo *configuration
 			userSummary.TotalAmountWeekendHours += schedUser.TotalAmountWeekendHours
 			userSummary.TotalAmountBankHolidaysHours += schedUser.TotalAmountBankHolidaysHours
 			userSummary.TotalAmount += schedUser.TotalAmount
+			userSummary.NumOverrideHours += schedUser.NumOverrideHours
+			userSummary.NumCallouts += schedUser.NumCallouts
+			userSummary.TotalAmountCallouts += schedUser.TotalAmountCallouts
+			userSummary.NumActiveWorkHours += schedUser.NumActiveWorkHours
+			userSummary.NumActiveWeekendHours += schedUser.NumActiveWeekendHours
+			userSummary.NumActiveBankHolidaysHours += schedUser.NumActiveBankHolidaysHours
+			userSummary.TotalAmountActive += schedUser.TotalAmountActive
+			userSummary.NumStipends += schedUser.NumStipends
+			userSummary.TotalAmountStipends += schedUser.TotalAmountStipends
+			userSummary.TotalAmountAdjustment += schedUser.TotalAmountAdjustment
+			for _, name := range schedUser.CoveredFor {
+				if !contains(userSummary.CoveredFor, name) {
+					userSummary.CoveredFor = append(userSummary.CoveredFor, name)
+				}
+			}
 		}
 	}
 
@@ -283,10 +436,8 @@ func calculateSummaryData(data []*report.ScheduleData, pricesInfo *configuration
 	return result
 }
 
-func (pd *pagerDutyClient) getScheduleInformation(scheduleID string, startDate, endDate time.Time) (*api.ScheduleInfo, error) {
-	schedule, err := pd.client.GetSchedule(scheduleID,
-		startDate.Format("2006-01-02T15:04:05"),
-		endDate.Format("2006-01-02T15:04:05"))
+func (pd *pagerDutyClient) getScheduleInformation(ctx context.Context, scheduleID string, startDate, endDate time.Time) (*api.ScheduleInfo, error) {
+	schedule, err := pd.getSchedule(ctx, scheduleID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -299,12 +450,21 @@ func (pd *pagerDutyClient) getScheduleInformation(scheduleID string, startDate,
 		Location:      location,
 		Start:         startDate,
 		End:           endDate,
+		Teams:         schedule.Teams,
 		FinalSchedule: schedule.FinalSchedule,
+		Layers:        schedule.Layers,
+
+		EscalationPolicyIDs: schedule.EscalationPolicyIDs,
 	}
 	return scheduleInfo, nil
 }
 
 func getUsersRotationData(scheduleInfo *api.ScheduleInfo) (api.ScheduleUserRotationData, error) {
+	overrides, err := overridePeriodsByUser(scheduleInfo)
+	if err != nil {
+		return nil, err
+	}
+
 	usersInfo := api.ScheduleUserRotationData{}
 	for _, entry := range scheduleInfo.FinalSchedule.RenderedScheduleEntries {
 		startDate, err := time.ParseInLocation(time.RFC3339, entry.Start, scheduleInfo.Location)
@@ -325,19 +485,19 @@ func getUsersRotationData(scheduleInfo *api.ScheduleInfo) (api.ScheduleUserRotat
 			}
 			usersInfo[entry.User.ID] = userRotaInfo
 		}
-		newUserRotaPeriod := &api.UserRotaPeriod{
-			Start: startDate,
-			End:   endDate,
+		newUserRotaPeriods, err := splitByOverrides(scheduleInfo, entry.User.ID, startDate, endDate, overrides[entry.User.ID])
+		if err != nil {
+			return nil, err
 		}
 
-		userRotaInfo.Periods = append(userRotaInfo.Periods, newUserRotaPeriod)
+		userRotaInfo.Periods = append(userRotaInfo.Periods, newUserRotaPeriods...)
 	}
 
 	return usersInfo, nil
 }
 
-func (pd *pagerDutyClient) generateScheduleData(scheduleInfo *api.ScheduleInfo, usersRotationData api.ScheduleUserRotationData,
-	pricesInfo *configuration.PricesInfo, schedule Schedule) (*report.ScheduleData, error) {
+func (pd *pagerDutyClient) generateScheduleData(ctx context.Context, scheduleInfo *api.ScheduleInfo, usersRotationData api.ScheduleUserRotationData,
+	pricesInfo *configuration.PricesInfo, schedule Schedule) (*report.ScheduleData, map[string]dailyPay, error) {
 
 	scheduleData := &report.ScheduleData{
 		ID:        scheduleInfo.ID,
@@ -346,43 +506,73 @@ func (pd *pagerDutyClient) generateScheduleData(scheduleInfo *api.ScheduleInfo,
 		EndDate:   schedule.endDate,
 		RotaUsers: make([]*report.ScheduleUser, 0),
 	}
+	usersPay := make(map[string]dailyPay)
 
 	for userID, userRotaInfo := range usersRotationData {
-		rotationUserConfig, err := Config.FindRotationUserInfoByID(userID)
-		if err != nil {
+		rotationUserConfig, err := pd.findRotationUser(ctx, userRotaInfo)
+		if isUserNotFound(err) {
 			log.Println("Error:", err)
 			continue
 		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("aborted due to failed to find user '%s': %w", userID, err)
+		}
 
-		calendarName := fmt.Sprintf("%s-%d", rotationUserConfig.HolidaysCalendar, scheduleInfo.Start.Year())
-		userCalendar, present := configuration.BankHolidaysCalendars[calendarName]
-		if !present {
-			return nil, fmt.Errorf("aborted due to calendar '%s' not found for user '%s'", calendarName, userID)
+		calendars := &userCalendars{rotationUser: rotationUserConfig, years: make(map[int]*configuration.BHCalendar)}
+		userCalendar, err := calendars.forYear(schedule.startDate.Year())
+		if err != nil {
+			return nil, nil, fmt.Errorf("aborted due to %w for user '%s'", err, userID)
 		}
 
-		userEmailAddress, err := pd.getUserEmail(userRotaInfo.ID)
+		userEmailAddress, err := pd.getUserEmail(ctx, userRotaInfo.ID)
 		if err != nil {
-			return nil, fmt.Errorf("aborted due to failed to get user's email address: %w", err)
+			return nil, nil, fmt.Errorf("aborted due to failed to get user's email address: %w", err)
 		}
 
 		scheduleUserData := &report.ScheduleUser{
 			Name:         userRotaInfo.Name,
 			EmailAddress: userEmailAddress,
+			CoveredFor:   make([]string, 0),
 		}
 
+		periodsPay := make([]*paidTime, 0, len(userRotaInfo.Periods))
+		daysPay := make(map[string]*paidTime)
+		pay := dailyPay{}
+		onCall := newOnCallTime()
 		for _, period := range userRotaInfo.Periods {
-			currentMonth := period.Start.Month()
+			periodPay := &paidTime{start: period.Start, end: period.End}
 			currentDate := period.Start
 
-			currentLocalDate, err := pd.convertToUserLocalTimezone(currentDate, userRotaInfo.ID)
+			currentLocalDate, err := pd.convertToUserLocalTimezone(ctx, currentDate, userRotaInfo.ID)
 			if err != nil {
-				return nil, fmt.Errorf("aborted due to failed to convert to user local timezone: %w", err)
+				return nil, nil, fmt.Errorf("aborted due to failed to convert to user local timezone: %w", err)
 			}
 
 			for currentLocalDate.Before(period.End) {
-				updateDataForDate(&userCalendar, scheduleUserData, currentMonth, currentLocalDate)
+				hoursBefore := scheduleUserData.NumWorkHours + scheduleUserData.NumWeekendHours + scheduleUserData.NumBankHolidaysHours
+				amountBefore := hourlyAmount(scheduleUserData, pricesInfo)
+				dateCalendar, err := calendars.forYear(rotaDate(currentLocalDate).Year())
+				if err != nil {
+					return nil, nil, fmt.Errorf("aborted due to %w for user '%s'", err, userID)
+				}
+				updateDataForDate(dateCalendar, scheduleUserData, schedule.startDate, currentLocalDate)
+				hours := scheduleUserData.NumWorkHours + scheduleUserData.NumWeekendHours + scheduleUserData.NumBankHolidaysHours - hoursBefore
+				amount := hourlyAmount(scheduleUserData, pricesInfo) - amountBefore
+				addPaidTime(periodPay, daysPay, currentLocalDate, hours, amount)
+				pay.add(rotaDate(currentLocalDate), amount)
+				onCall.add(currentLocalDate, time.Minute*time.Duration(Config.RotationInfo.CheckRotationChangeEvery), hours)
 				currentLocalDate = currentLocalDate.Add(time.Minute * time.Duration(Config.RotationInfo.CheckRotationChangeEvery))
 			}
+
+			if period.Override {
+				scheduleUserData.NumOverrideHours += overrideHours(period)
+			}
+			for _, name := range period.CoveredFor {
+				if !contains(scheduleUserData.CoveredFor, name) {
+					scheduleUserData.CoveredFor = append(scheduleUserData.CoveredFor, name)
+				}
+			}
+			periodsPay = append(periodsPay, periodPay)
 		}
 
 		scheduleUserData.NumWorkDays = scheduleUserData.NumWorkHours / float32(pricesInfo.HoursWeekDay)
@@ -394,14 +584,107 @@ func (pd *pagerDutyClient) generateScheduleData(scheduleInfo *api.ScheduleInfo,
 		scheduleUserData.TotalAmount = scheduleUserData.TotalAmountWorkHours +
 			scheduleUserData.TotalAmountWeekendHours +
 			scheduleUserData.TotalAmountBankHolidaysHours
+		priceStipends(scheduleUserData, scheduleInfo.ID, onCall, userCalendar, pay)
+		adjustment, adjustmentPay := minimumsAdjustment(periodsPay, daysPay)
+		scheduleUserData.TotalAmountAdjustment = adjustment
+		for day, amount := range adjustmentPay {
+			pay[day] += amount
+		}
 		scheduleData.RotaUsers = append(scheduleData.RotaUsers, scheduleUserData)
+		usersPay[scheduleUserData.Name] = pay
+	}
+
+	return scheduleData, usersPay, nil
+}
+
+// findRotationUser returns the user's config entry, inferring the holidays calendar from the
+// user's timezone for users without one. Inferred calendars are recorded to be shown in the report.
+func (pd *pagerDutyClient) findRotationUser(ctx context.Context, userRotaInfo *api.UserRotaInfo) (*configuration.RotationUser, error) {
+	timezone, err := pd.getUserTimezone(ctx, userRotaInfo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rotationUser, err := Config.FindRotationUserInfoByIDAndTimezone(userRotaInfo.ID, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if rotationUser.InferredFromTimezone != "" {
+		if pd.inferredCalendars == nil {
+			pd.inferredCalendars = make(map[string]*report.InferredCalendar)
+		}
+		if _, ok := pd.inferredCalendars[userRotaInfo.ID]; !ok {
+			pd.inferredCalendars[userRotaInfo.ID] = &report.InferredCalendar{
+				UserName: userRotaInfo.Name,
+				Timezone: rotationUser.InferredFromTimezone,
+				Calendar: rotationUser.HolidaysCalendar,
+			}
+		}
+	}
+
+	return rotationUser, nil
+}
+
+// isUserNotFound reports whether the error is about a user without a config entry nor a calendar to fall
+// back to, who is left out of the report.
+func isUserNotFound(err error) bool {
+	var userNotFound *configuration.UserNotFoundError
+	return errors.As(err, &userNotFound)
+}
+
+// userCalendars are the user's holidays calendars per year, for schedules over several years.
+type userCalendars struct {
+	rotationUser *configuration.RotationUser
+	years        map[int]*configuration.BHCalendar
+}
+
+func (c *userCalendars) forYear(year int) (*configuration.BHCalendar, error) {
+	if calendar, ok := c.years[year]; ok {
+		return calendar, nil
+	}
+
+	calendar, err := userCalendar(c.rotationUser, year)
+	if err != nil {
+		return nil, err
+	}
+	c.years[year] = calendar
+	return calendar, nil
+}
+
+// rotaDate returns the day the date belongs to: hours before the daily rotation start belong to the previous day.
+func rotaDate(date time.Time) time.Time {
+	if date.Hour() < Config.RotationInfo.DailyRotationStartsAt {
+		return date.AddDate(0, 0, -1)
+	}
+	return date
+}
+
+// userCalendar returns the user's holidays calendar for the year, with the user's weekend days.
+func userCalendar(rotationUser *configuration.RotationUser, year int) (*configuration.BHCalendar, error) {
+	calendarName := fmt.Sprintf("%s-%d", rotationUser.HolidaysCalendar, year)
+	calendar, present := configuration.BankHolidaysCalendars[calendarName]
+	if !present {
+		return nil, fmt.Errorf("calendar '%s' not found", calendarName)
+	}
+	calendar.WeekendDays = Config.FindWeekendDays(rotationUser)
+	return &calendar, nil
+}
+
+func (pd *pagerDutyClient) getInferredCalendars() []*report.InferredCalendar {
+	inferredCalendars := make([]*report.InferredCalendar, 0, len(pd.inferredCalendars))
+	for _, inferredCalendar := range pd.inferredCalendars {
+		inferredCalendars = append(inferredCalendars, inferredCalendar)
 	}
 
-	return scheduleData, nil
+	sort.Slice(inferredCalendars, func(i, j int) bool {
+		return inferredCalendars[i].UserName < inferredCalendars[j].UserName
+	})
+	return inferredCalendars
 }
 
-func (pd *pagerDutyClient) convertToUserLocalTimezone(scheduleDate time.Time, userID string) (time.Time, error) {
-	timezone, err := pd.getUserTimezone(userID)
+func (pd *pagerDutyClient) convertToUserLocalTimezone(ctx context.Context, scheduleDate time.Time, userID string) (time.Time, error) {
+	timezone, err := pd.getUserTimezone(ctx, userID)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to find user local timezone, Aborting: %w", err)
 	}
@@ -419,8 +702,8 @@ func (pd *pagerDutyClient) convertToUserLocalTimezone(scheduleDate time.Time, us
 	return currentLocalDate, nil
 }
 
-func (pd *pagerDutyClient) loadUsersInMemoryCache() error {
-	users, err := pd.client.ListUsers()
+func (pd *pagerDutyClient) loadUsersInMemoryCache(ctx context.Context) error {
+	users, err := pd.client.ListUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load users in memory: %w", err)
 	}
@@ -429,11 +712,11 @@ func (pd *pagerDutyClient) loadUsersInMemoryCache() error {
 	return nil
 }
 
-func (pd *pagerDutyClient) getUserTimezone(userID string) (string, error) {
+func (pd *pagerDutyClient) getUserTimezone(ctx context.Context, userID string) (string, error) {
 	var timezone string
 
 	if len(pd.cachedUsers) == 0 {
-		err := pd.loadUsersInMemoryCache()
+		err := pd.loadUsersInMemoryCache(ctx)
 		if err != nil {
 			return "", fmt.Errorf("failed to get user with id %s timezone: %w", userID, err)
 		}
@@ -452,11 +735,11 @@ func (pd *pagerDutyClient) getUserTimezone(userID string) (string, error) {
 	return timezone, nil
 }
 
-func (pd *pagerDutyClient) getUserEmail(userID string) (string, error) {
+func (pd *pagerDutyClient) getUserEmail(ctx context.Context, userID string) (string, error) {
 	var email string
 
 	if len(pd.cachedUsers) == 0 {
-		err := pd.loadUsersInMemoryCache()
+		err := pd.loadUsersInMemoryCache(ctx)
 		if err != nil {
 			return "", fmt.Errorf("failed to get user with id %s email: %w", userID, err)
 		}
@@ -471,48 +754,48 @@ func (pd *pagerDutyClient) getUserEmail(userID string) (string, error) {
 	return email, nil
 }
 
-func updateDataForDate(calendar *configuration.BHCalendar, data *report.ScheduleUser, currentMonth time.Month, date time.Time) {
+func updateDataForDate(calendar *configuration.BHCalendar, data *report.ScheduleUser, rangeStart time.Time, date time.Time) {
 	if date.Hour() < Config.RotationInfo.DailyRotationStartsAt {
 		newDate := date.Add(time.Hour * time.Duration(-(date.Hour() + 1))) // move to yesterday night to determine which kind of day it was
-		// if yesterday night was last month, ignore the date
-		if newDate.Month() == currentMonth {
-			updateDataForDate(calendar, data, currentMonth, newDate)
+		// if yesterday night was before the range, it was priced in the previous report, ignore the date
+		if !newDate.Before(rangeStart) {
+			updateDataForDate(calendar, data, rangeStart, newDate)
 		}
 	} else {
 		if calendar.IsDateBankHoliday(date) {
 			excludedHours := Config.FindRotationExcludedHoursByDay("bankholiday")
 			if excludedHours == nil {
-				//fmt.Printf("%s - Month: %d, time: %v -- bank holiday\n", data.Name, currentMonth, date)
+				//fmt.Printf("%s - time: %v -- bank holiday\n", data.Name, date)
 				data.NumBankHolidaysHours += 0.5
 				return
 			}
 
 			if date.Hour() < excludedHours.ExcludedStartsAt || date.Hour() >= excludedHours.ExcludedEndsAt {
-				//fmt.Printf("%s - Month: %d, time: %v -- bank holiday non excluded hours\n", data.Name, currentMonth, date)
+				//fmt.Printf("%s - time: %v -- bank holiday non excluded hours\n", data.Name, date)
 				data.NumBankHolidaysHours += 0.5
 			}
 		} else if calendar.IsWeekend(date) {
 			excludedHours := Config.FindRotationExcludedHoursByDay("weekend")
 			if excludedHours == nil {
-				//fmt.Printf("%s - Month: %d, time: %v -- weekend\n", data.Name, currentMonth, date)
+				//fmt.Printf("%s - time: %v -- weekend\n", data.Name, date)
 				data.NumWeekendHours += 0.5
 				return
 			}
 
 			if date.Hour() < excludedHours.ExcludedStartsAt || date.Hour() >= excludedHours.ExcludedEndsAt {
-				//fmt.Printf("%s - Month: %d, time: %v -- weekend non excluded hours\n", data.Name, currentMonth, date)
+				//fmt.Printf("%s - time: %v -- weekend non excluded hours\n", data.Name, date)
 				data.NumWeekendHours += 0.5
 			}
 		} else {
 			excludedHours := Config.FindRotationExcludedHoursByDay("weekday")
 			if excludedHours == nil {
-				//fmt.Printf("%s - Month: %d, time: %v -- weekday\n", data.Name, currentMonth, date)
+				//fmt.Printf("%s - time: %v -- weekday\n", data.Name, date)
 				data.NumWorkHours += 0.5
 				return
 			}
 
 			if date.Hour() < excludedHours.ExcludedStartsAt || date.Hour() >= excludedHours.ExcludedEndsAt {
-				//fmt.Printf("%s - Month: %d, time: %v -- weekday non excluded hours\n", data.Name, currentMonth, date)
+				//fmt.Printf("%s - time: %v -- weekday non excluded hours\n", data.Name, date)
 				data.NumWorkHours += 0.5
 			}
 		}
@@ -1,13 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api/fake"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -31,16 +42,16 @@ func Test_pagerDutyClient_loadUsersInMemoryCache(t *testing.T) {
 	}{
 		{
 			name: "Successfully load users in memory",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListUsers").Once().Return(users, nil)
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return(users, nil)
 			},
 			want:    users,
 			wantErr: false,
 		},
 		{
 			name: "Failed load users in memory",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListUsers").Once().Return(nil, errors.New("failed"))
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return(nil, errors.New("failed"))
 			},
 			wantErr: true,
 		},
@@ -53,7 +64,7 @@ func Test_pagerDutyClient_loadUsersInMemoryCache(t *testing.T) {
 			}
 
 			pd := pagerDutyClient{client: mockedClient}
-			err := pd.loadUsersInMemoryCache()
+			err := pd.loadUsersInMemoryCache(context.Background())
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -73,7 +84,7 @@ func Test_pagerDutyClient_getUserTimezone(t *testing.T) {
 		name                string
 		cachedUsers         []*api.User
 		defaultUserTimezone string
-		mockSetup           func(mock *clientMock)
+		mockSetup           func(clientMock *clientMock)
 		want                string
 		wantErr             bool
 	}{
@@ -112,8 +123,8 @@ func Test_pagerDutyClient_getUserTimezone(t *testing.T) {
 		},
 		{
 			name: "If user not cached it will load users in cache and successfully return timezone",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListUsers").Once().Return([]*api.User{
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return([]*api.User{
 					{ID: "USER_ID", Timezone: "Europe/London"},
 				}, nil)
 			},
@@ -134,7 +145,7 @@ func Test_pagerDutyClient_getUserTimezone(t *testing.T) {
 				defaultUserTimezone: tt.defaultUserTimezone,
 			}
 
-			got, err := pd.getUserTimezone("USER_ID")
+			got, err := pd.getUserTimezone(context.Background(), "USER_ID")
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -154,7 +165,7 @@ func Test_pagerDutyClient_getUserEmailAddress(t *testing.T) {
 		name                string
 		cachedUsers         []*api.User
 		defaultUserTimezone string
-		mockSetup           func(mock *clientMock)
+		mockSetup           func(clientMock *clientMock)
 		want                string
 		wantErr             bool
 	}{
@@ -191,8 +202,8 @@ func Test_pagerDutyClient_getUserEmailAddress(t *testing.T) {
 		},
 		{
 			name: "If user not cached it will load users in cache and successfully return email address",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListUsers").Once().Return([]*api.User{
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return([]*api.User{
 					{ID: "USER_ID", Email: "anyUser@anyDomain.com"},
 				}, nil)
 			},
@@ -213,7 +224,7 @@ func Test_pagerDutyClient_getUserEmailAddress(t *testing.T) {
 				defaultUserTimezone: tt.defaultUserTimezone,
 			}
 
-			got, err := pd.getUserEmail("USER_ID")
+			got, err := pd.getUserEmail(context.Background(), "USER_ID")
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -233,7 +244,7 @@ func Test_pagerDutyClient_convertToUserLocalTimezone(t *testing.T) {
 		name         string
 		scheduleDate string
 		cachedUsers  []*api.User
-		mockSetup    func(mock *clientMock)
+		mockSetup    func(clientMock *clientMock)
 		want         string
 		wantErr      bool
 	}{
@@ -252,8 +263,8 @@ func Test_pagerDutyClient_convertToUserLocalTimezone(t *testing.T) {
 		{
 			name:         "Fails to list users fails to convert user timezone",
 			scheduleDate: "01 Sep 22 17:00 BST",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListUsers").Once().Return(nil, errors.New("failed"))
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Once().Return(nil, errors.New("failed"))
 			},
 			wantErr: true,
 		},
@@ -284,7 +295,7 @@ func Test_pagerDutyClient_convertToUserLocalTimezone(t *testing.T) {
 			scheduleDate, err := time.Parse(time.RFC822, tt.scheduleDate)
 			require.NoError(t, err)
 
-			got, err := pd.convertToUserLocalTimezone(scheduleDate, "USER_ID")
+			got, err := pd.convertToUserLocalTimezone(context.Background(), scheduleDate, "USER_ID")
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -302,3 +313,181 @@ func Test_pagerDutyClient_convertToUserLocalTimezone(t *testing.T) {
 		})
 	}
 }
+
+// newTestConfig sets a configuration with the uk calendar, rotations starting at 8 and checked every 30
+// minutes, restored once the test is done.
+func newTestConfig(t *testing.T) {
+	Config = configuration.New()
+	Config.DefaultHolidayCalendar = "uk"
+	Config.RotationInfo = configuration.RotationInfo{DailyRotationStartsAt: 8, CheckRotationChangeEvery: 30}
+	t.Cleanup(func() {
+		Config = nil
+	})
+}
+
+// generateTestScheduleUsers prices the rotations of a schedule over September 2026, for users in UTC, without
+// bank holidays and at hourly prices of 1, 2 and 3 per weekday, weekend and bank holiday hour.
+func generateTestScheduleUsers(t *testing.T, usersRotationData api.ScheduleUserRotationData) map[string]*report.ScheduleUser {
+	start, end := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	return generateTestRangeScheduleUsers(t, start, end, usersRotationData)
+}
+
+// generateTestRangeScheduleUsers prices the rotations of a schedule over the range like generateTestScheduleUsers.
+func generateTestRangeScheduleUsers(t *testing.T, start, end time.Time, usersRotationData api.ScheduleUserRotationData) map[string]*report.ScheduleUser {
+	calendars := configuration.BankHolidaysCalendars
+	configuration.BankHolidaysCalendars = configuration.BHCalendars{}
+	for year := start.Year(); year <= end.Year(); year++ {
+		configuration.BankHolidaysCalendars[fmt.Sprintf("uk-%d", year)] = configuration.BHCalendar{}
+	}
+	t.Cleanup(func() {
+		configuration.BankHolidaysCalendars = calendars
+	})
+
+	pd := &pagerDutyClient{}
+	for _, userRotaInfo := range usersRotationData {
+		pd.cachedUsers = append(pd.cachedUsers, &api.User{ID: userRotaInfo.ID, Name: userRotaInfo.Name, Timezone: "UTC"})
+	}
+	scheduleInfo := &api.ScheduleInfo{ID: "SCHED1", Name: "Payments primary", Location: time.UTC, Start: start, End: end}
+	pricesInfo := &configuration.PricesInfo{
+		WeekDayHourlyPrice: 1, HoursWeekDay: 24,
+		WeekendDayHourlyPrice: 2, HoursWeekendDay: 24,
+		BhDayHourlyPrice: 3, HoursBhDay: 24,
+	}
+
+	scheduleData, _, err := pd.generateScheduleData(context.Background(), scheduleInfo, usersRotationData, pricesInfo,
+		Schedule{id: scheduleInfo.ID, startDate: start, endDate: end})
+	require.NoError(t, err)
+
+	users := make(map[string]*report.ScheduleUser)
+	for _, user := range scheduleData.RotaUsers {
+		users[user.Name] = user
+	}
+	return users
+}
+
+// hourlyTestAmount is the pay of the user's hours at the prices of generateTestScheduleUsers.
+func hourlyTestAmount(user *report.ScheduleUser) float32 {
+	return user.NumWorkHours + 2*user.NumWeekendHours + 3*user.NumBankHolidaysHours
+}
+
+func newFakeServerReport(t *testing.T) *pagerDutyClient {
+	return newFakeServerReportWithHandler(t, func(handler http.Handler) http.Handler { return handler })
+}
+
+// newFakeServerReportWithHandler is newFakeServerReport with the fake server wrapped by the given handler,
+// e.g. to make some of its endpoints fail.
+func newFakeServerReportWithHandler(t *testing.T, wrap func(http.Handler) http.Handler) *pagerDutyClient {
+	fixture, err := fake.LoadFixture("../api/fake/testdata/fixture.yml")
+	require.NoError(t, err)
+	server := httptest.NewServer(wrap(fake.NewServer(fixture)))
+	t.Cleanup(server.Close)
+
+	apiClient, err := api.NewPagerDutyAPIClient("fake-token", api.ClientOptions{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	Config = configuration.New()
+	Config.DefaultHolidayCalendar = "uk"
+	Config.ReportTimeRange = configuration.ReportTimeRange{Start: "2026-09-01", End: "2026-10-01"}
+	Config.RotationInfo = configuration.RotationInfo{DailyRotationStartsAt: 8, CheckRotationChangeEvery: 30}
+	Config.RotationPrices = configuration.RotationPrices{
+		Currency: "£",
+		DaysInfo: []configuration.RotationPriceDay{
+			{Day: "weekday", Price: 1},
+			{Day: "weekend", Price: 2},
+			{Day: "bankholiday", Price: 3},
+		},
+	}
+	Config.SchedulesToIgnore = []string{"SCHED2"}
+	rawSchedules, outputFormat, directory = []string{"all"}, "csv", t.TempDir()
+	t.Cleanup(func() {
+		Config, rawSchedules, outputFormat, directory = nil, []string{"all"}, "console", ""
+	})
+
+	return &pagerDutyClient{client: apiClient}
+}
+
+func Test_pagerDutyClient_generateReport_fakeServer(t *testing.T) {
+	pd := newFakeServerReport(t)
+	require.NoError(t, pd.generateReport(context.Background()))
+
+	summary, err := os.ReadFile(filepath.Join(directory, "pagerduty_oncall_report.9-2026-Summary.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "John Doe,john.doe@example.com")
+	assert.Contains(t, string(summary), "Mary Jane,mary.jane@example.com")
+	assert.NotContains(t, string(summary), "Bruce Wayne")
+	assert.Contains(t, string(summary), "Mary Jane,mary.jane@example.com,")
+	assert.Contains(t, string(summary), ",48,John Doe,")
+
+	overrides, err := os.ReadFile(filepath.Join(directory, "pagerduty_oncall_report.9-2026-Overrides.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(overrides), "Mary Jane,Payments primary,SCHED1,2026-09-19T09:00:00+01:00,2026-09-21T09:00:00+01:00,48,John Doe")
+}
+
+func Test_pagerDutyClient_generateReport_acrossYears(t *testing.T) {
+	pd := newFakeServerReport(t)
+	// the rotation starts on the 5th of January 2026, priced with the calendar of 2026
+	Config.ReportTimeRange = configuration.ReportTimeRange{Start: "2025-12-01", End: "2026-01-15"}
+	require.NoError(t, pd.generateReport(context.Background()))
+
+	summary, err := os.ReadFile(filepath.Join(directory, "pagerduty_oncall_report.12-2025-Summary.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "John Doe,john.doe@example.com")
+	assert.Contains(t, string(summary), "Mary Jane,mary.jane@example.com")
+}
+
+func Test_loadReportCalendars(t *testing.T) {
+	Config = &configuration.Configuration{DefaultHolidayCalendar: "uk"}
+	defer func() { Config = nil }()
+
+	loadReportCalendars(time.Date(2027, time.December, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, time.January, 1, 8, 0, 0, 0, time.UTC))
+
+	// the years of the range and the years around it, missing ones empty
+	assert.NotEmpty(t, configuration.BankHolidaysCalendars["uk-2026"].DaysMaps)
+	assert.NotEmpty(t, configuration.BankHolidaysCalendars["uk-2027"].DaysMaps)
+	assert.Contains(t, configuration.BankHolidaysCalendars, "uk-2029")
+	assert.Empty(t, configuration.BankHolidaysCalendars["uk-2029"].DaysMaps)
+}
+
+func Test_pagerDutyClient_generateReport_cancelled(t *testing.T) {
+	pd := newFakeServerReport(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := pd.generateReport(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	files, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func Test_pagerDutyClient_generateReport_usersFailing(t *testing.T) {
+	pd := newFakeServerReportWithHandler(t, func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/users") {
+				http.Error(w, `{"error":{"code":2000,"message":"Internal error"}}`, http.StatusInternalServerError)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	})
+
+	err := pd.generateReport(context.Background())
+	require.ErrorContains(t, err, "failed to get user with id")
+
+	files, err := os.ReadDir(directory)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func Test_pagerDutyClient_generateReport_userNotFound(t *testing.T) {
+	pd := newFakeServerReport(t)
+	Config.DefaultHolidayCalendar = ""
+	Config.RotationUsers = []configuration.RotationUser{{UserID: "USER1", HolidaysCalendar: "uk"}}
+	require.NoError(t, pd.generateReport(context.Background()))
+
+	summary, err := os.ReadFile(filepath.Join(directory, "pagerduty_oncall_report.9-2026-Summary.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(summary), "John Doe,john.doe@example.com")
+	assert.NotContains(t, string(summary), "Mary Jane")
+}
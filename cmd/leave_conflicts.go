@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+func (pd *pagerDutyClient) findLeaveConflicts(ctx context.Context, scheduleInfo *api.ScheduleInfo, usersRotationData api.ScheduleUserRotationData) ([]*report.Conflict, error) {
+	conflicts := make([]*report.Conflict, 0)
+	for userID, userRotaInfo := range usersRotationData {
+		rotationUserConfig, err := pd.findRotationUser(ctx, userRotaInfo)
+		if isUserNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("aborted due to failed to find user '%s': %w", userID, err)
+		}
+		if rotationUserConfig.LeaveFile == "" {
+			continue
+		}
+
+		leaves, err := pd.getUserLeaves(ctx, rotationUserConfig)
+		if err != nil {
+			return nil, fmt.Errorf("aborted due to failed to load leave of user '%s': %w", userID, err)
+		}
+
+		conflicts = append(conflicts, leaveConflicts(scheduleInfo, userRotaInfo, leaves)...)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].OnCallStart.Before(conflicts[j].OnCallStart)
+	})
+
+	return conflicts, nil
+}
+
+func leaveConflicts(scheduleInfo *api.ScheduleInfo, userRotaInfo *api.UserRotaInfo, leaves []configuration.Leave) []*report.Conflict {
+	conflicts := make([]*report.Conflict, 0)
+	for _, period := range userRotaInfo.Periods {
+		for _, leave := range leaves {
+			if !leave.Overlaps(period.Start, period.End) {
+				continue
+			}
+
+			log.Printf("[%s] %s is on call from %s to %s during leave '%s'", scheduleInfo.ID, userRotaInfo.Name,
+				period.Start.Format(time.RFC822), period.End.Format(time.RFC822), leave.Title)
+			conflicts = append(conflicts, &report.Conflict{
+				ScheduleID:   scheduleInfo.ID,
+				ScheduleName: scheduleInfo.Name,
+				UserName:     userRotaInfo.Name,
+				OnCallStart:  period.Start,
+				OnCallEnd:    period.End,
+				LeaveTitle:   leave.Title,
+				LeaveStart:   leave.Start,
+				LeaveEnd:     leave.End,
+			})
+		}
+	}
+
+	return conflicts
+}
+
+func (pd *pagerDutyClient) getUserLeaves(ctx context.Context, rotationUser *configuration.RotationUser) ([]configuration.Leave, error) {
+	if leaves, ok := pd.cachedLeaves[rotationUser.UserID]; ok {
+		return leaves, nil
+	}
+
+	timezone, err := pd.getUserTimezone(ctx, rotationUser.UserID)
+	if err != nil {
+		return nil, err
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load location by timezone: %w", err)
+	}
+
+	leaves, err := configuration.LoadLeaveFile(rotationUser.LeaveFile, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if pd.cachedLeaves == nil {
+		pd.cachedLeaves = make(map[string][]configuration.Leave)
+	}
+	pd.cachedLeaves[rotationUser.UserID] = leaves
+	return leaves, nil
+}
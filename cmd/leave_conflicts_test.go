@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_leaveConflicts(t *testing.T) {
+	scheduleInfo := &api.ScheduleInfo{ID: "SCHED_1", Name: "Schedule 1"}
+	userRotaInfo := &api.UserRotaInfo{
+		ID:   "USER_ID",
+		Name: "John Doe",
+		Periods: []*api.UserRotaPeriod{
+			{
+				Start: time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, 9, 8, 8, 0, 0, 0, time.UTC),
+			},
+			{
+				Start: time.Date(2026, 9, 15, 8, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, 9, 22, 8, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		leaves []configuration.Leave
+		want   int
+	}{
+		{
+			name: "Leave overlapping one on-call period is a conflict",
+			leaves: []configuration.Leave{
+				{
+					Title: "Holidays",
+					Start: time.Date(2026, 9, 7, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want: 1,
+		},
+		{
+			name: "Leave between on-call periods is not a conflict",
+			leaves: []configuration.Leave{
+				{
+					Title: "Holidays",
+					Start: time.Date(2026, 9, 8, 8, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 9, 15, 8, 0, 0, 0, time.UTC),
+				},
+			},
+			want: 0,
+		},
+		{
+			name: "Leave spanning both on-call periods is two conflicts",
+			leaves: []configuration.Leave{
+				{
+					Title: "Sick",
+					Start: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := leaveConflicts(scheduleInfo, userRotaInfo, tt.leaves)
+
+			require.Len(t, got, tt.want)
+			for _, conflict := range got {
+				assert.Equal(t, "SCHED_1", conflict.ScheduleID)
+				assert.Equal(t, "John Doe", conflict.UserName)
+			}
+		})
+	}
+}
+
+func Test_pagerDutyClient_getUserLeaves(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     []configuration.Leave
+		wantErr  bool
+	}{
+		{
+			name:     "Successfully load yml leave file in the user timezone",
+			fileName: "leave.yml",
+			content: `
+- title: Holidays
+  start: 07/09/2026
+  end: 09/09/2026
+`,
+			want: []configuration.Leave{
+				{
+					Title: "Holidays",
+					Start: time.Date(2026, 9, 6, 23, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 9, 9, 23, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:     "Successfully load csv leave file with header",
+			fileName: "leave.csv",
+			content:  "start,end,title\n07/09/2026,07/09/2026,Sick\n",
+			want: []configuration.Leave{
+				{
+					Title: "Sick",
+					Start: time.Date(2026, 9, 6, 23, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 9, 7, 23, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:     "Successfully load ics leave file",
+			fileName: "leave.ics",
+			content: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:Holi\r\n days\r\nDTSTART;VALUE=DATE:20260907\r\n" +
+				"DTEND;VALUE=DATE:20260910\r\nEND:VEVENT\r\nBEGIN:VEVENT\r\nSUMMARY:Dentist\r\n" +
+				"DTSTART:20260915T090000Z\r\nDTEND:20260915T110000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			want: []configuration.Leave{
+				{
+					Title: "Holidays",
+					Start: time.Date(2026, 9, 6, 23, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 9, 9, 23, 0, 0, 0, time.UTC),
+				},
+				{
+					Title: "Dentist",
+					Start: time.Date(2026, 9, 15, 9, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, 9, 15, 11, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:     "Unsupported leave file format fails",
+			fileName: "leave.txt",
+			content:  "07/09/2026",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaveFile := filepath.Join(t.TempDir(), tt.fileName)
+			require.NoError(t, os.WriteFile(leaveFile, []byte(tt.content), 0600))
+
+			pd := pagerDutyClient{
+				client: &clientMock{},
+				cachedUsers: []*api.User{
+					{ID: "USER_ID", Timezone: "Europe/London"},
+				},
+			}
+
+			got, err := pd.getUserLeaves(context.Background(), &configuration.RotationUser{UserID: "USER_ID", LeaveFile: leaveFile})
+
+			if tt.wantErr == true {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			require.Len(t, got, len(tt.want))
+			for i, wantLeave := range tt.want {
+				assert.Equal(t, wantLeave.Title, got[i].Title)
+				assert.True(t, wantLeave.Start.Equal(got[i].Start), "start %s != %s", wantLeave.Start, got[i].Start)
+				assert.True(t, wantLeave.End.Equal(got[i].End), "end %s != %s", wantLeave.End, got[i].End)
+			}
+		})
+	}
+}
@@ -1,10 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
-
 	"github.com/spf13/cobra"
 )
 
@@ -13,8 +12,12 @@ var listSchedulesCmd = &cobra.Command{
 	Short: "list schedules on PagerDuty",
 	Long:  "Get the list of schedules configured in PagerDuty",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pd := &pagerDutyClient{client: api.NewPagerDutyAPIClient(Config.PdAuthToken)}
-		return pd.listSchedules()
+		apiClient, err := newAPIClient(Config)
+		if err != nil {
+			return err
+		}
+		pd := &pagerDutyClient{client: apiClient}
+		return pd.listSchedules(cmd.Context())
 	},
 }
 
@@ -22,8 +25,8 @@ func init() {
 	rootCmd.AddCommand(listSchedulesCmd)
 }
 
-func (pd *pagerDutyClient) listSchedules() error {
-	schedules, err := pd.client.ListSchedules()
+func (pd *pagerDutyClient) listSchedules(ctx context.Context) error {
+	schedules, err := pd.client.ListSchedules(ctx)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -53,7 +54,7 @@ func Test_listSchedules(t *testing.T) {
 			}
 
 			pd := pagerDutyClient{client: mockedClient}
-			err := pd.listSchedules()
+			err := pd.listSchedules(context.Background())
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -1,10 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
-
 	"github.com/spf13/cobra"
 )
 
@@ -14,8 +13,12 @@ var listServicesCmd = &cobra.Command{
 	Long:  "Get the list of services configured in PagerDuty",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pd := &pagerDutyClient{client: api.NewPagerDutyAPIClient(Config.PdAuthToken)}
-		return pd.listServices(args[0])
+		apiClient, err := newAPIClient(Config)
+		if err != nil {
+			return err
+		}
+		pd := &pagerDutyClient{client: apiClient}
+		return pd.listServices(cmd.Context(), args[0])
 	},
 }
 
@@ -23,8 +26,8 @@ func init() {
 	rootCmd.AddCommand(listServicesCmd)
 }
 
-func (pd *pagerDutyClient) listServices(teamID string) error {
-	services, err := pd.client.ListServices(teamID)
+func (pd *pagerDutyClient) listServices(ctx context.Context, teamID string) error {
+	services, err := pd.client.ListServices(ctx, teamID)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -19,7 +20,7 @@ func Test_listServices(t *testing.T) {
 		{
 			name: "Successfully list pagerduty services",
 			mockSetup: func(clientMock *clientMock) {
-				clientMock.On("ListServices", mock.Anything).Return([]*api.Service{
+				clientMock.On("ListServices", mock.Anything, mock.Anything).Return([]*api.Service{
 					{
 						ID:   "QWERTY",
 						Name: "Service 1",
@@ -31,7 +32,7 @@ func Test_listServices(t *testing.T) {
 		{
 			name: "Failed to list pagerduty services",
 			mockSetup: func(clientMock *clientMock) {
-				clientMock.On("ListServices", mock.Anything).Return(nil, errors.New("failed to list"))
+				clientMock.On("ListServices", mock.Anything, mock.Anything).Return(nil, errors.New("failed to list"))
 			},
 			wantErr: true,
 		},
@@ -45,7 +46,7 @@ func Test_listServices(t *testing.T) {
 			}
 
 			pd := pagerDutyClient{client: mockedClient}
-			err := pd.listServices("fake-service-id")
+			err := pd.listServices(context.Background(), "fake-service-id")
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
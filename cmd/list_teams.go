@@ -1,10 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
-
 	"github.com/spf13/cobra"
 )
 
@@ -13,8 +12,12 @@ var listTeamsCmd = &cobra.Command{
 	Short: "list teams on PagerDuty",
 	Long:  "Get the list of teams configured in PagerDuty",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pd := &pagerDutyClient{client: api.NewPagerDutyAPIClient(Config.PdAuthToken)}
-		return pd.listTeams()
+		apiClient, err := newAPIClient(Config)
+		if err != nil {
+			return err
+		}
+		pd := &pagerDutyClient{client: apiClient}
+		return pd.listTeams(cmd.Context())
 	},
 }
 
@@ -22,8 +25,8 @@ func init() {
 	rootCmd.AddCommand(listTeamsCmd)
 }
 
-func (pd *pagerDutyClient) listTeams() error {
-	teams, err := pd.client.ListTeams()
+func (pd *pagerDutyClient) listTeams(ctx context.Context) error {
+	teams, err := pd.client.ListTeams(ctx)
 	if err != nil {
 		return err
 	}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,8 +19,8 @@ func Test_listTeams(t *testing.T) {
 	}{
 		{
 			name: "Successfully list pagerduty teams",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListTeams").Return([]*api.Team{
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListTeams", mock.Anything).Return([]*api.Team{
 					{
 						ID:   "QWERTY",
 						Name: "Team 1",
@@ -29,8 +31,8 @@ func Test_listTeams(t *testing.T) {
 		},
 		{
 			name: "Failed to list pagerduty teams",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListTeams").Return(nil, errors.New("failed to list"))
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListTeams", mock.Anything).Return(nil, errors.New("failed to list"))
 			},
 			wantErr: true,
 		},
@@ -44,7 +46,7 @@ func Test_listTeams(t *testing.T) {
 			}
 
 			pd := pagerDutyClient{client: mockedClient}
-			err := pd.listTeams()
+			err := pd.listTeams(context.Background())
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
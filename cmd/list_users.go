@@ -1,10 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
-	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
-
 	"github.com/spf13/cobra"
 )
 
@@ -13,8 +12,12 @@ var listUsersCmd = &cobra.Command{
 	Short: "List users on PagerDuty",
 	Long:  "Get the list of users configured in PagerDuty",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		pd := &pagerDutyClient{client: api.NewPagerDutyAPIClient(Config.PdAuthToken)}
-		return pd.listUsers()
+		apiClient, err := newAPIClient(Config)
+		if err != nil {
+			return err
+		}
+		pd := &pagerDutyClient{client: apiClient}
+		return pd.listUsers(cmd.Context())
 	},
 }
 
@@ -22,8 +25,8 @@ func init() {
 	rootCmd.AddCommand(listUsersCmd)
 }
 
-func (pd *pagerDutyClient) listUsers() error {
-	users, err := pd.client.ListUsers()
+func (pd *pagerDutyClient) listUsers(ctx context.Context) error {
+	users, err := pd.client.ListUsers(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch user list: %w", err)
 	}
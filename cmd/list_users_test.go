@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,8 +19,8 @@ func Test_listUsers(t *testing.T) {
 	}{
 		{
 			name: "Successfully list pagerduty users",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListUsers").Return([]*api.User{
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Return([]*api.User{
 					{
 						ID:    "QWERTY",
 						Name:  "John Doe",
@@ -35,8 +37,8 @@ func Test_listUsers(t *testing.T) {
 		},
 		{
 			name: "Failed to list pagerduty users",
-			mockSetup: func(mock *clientMock) {
-				mock.On("ListUsers").Return(nil, errors.New("failed to list"))
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("ListUsers", mock.Anything).Return(nil, errors.New("failed to list"))
 			},
 			wantErr: true,
 		},
@@ -50,7 +52,7 @@ func Test_listUsers(t *testing.T) {
 			}
 
 			pd := pagerDutyClient{client: mockedClient}
-			err := pd.listUsers()
+			err := pd.listUsers(context.Background())
 			mockedClient.AssertExpectations(t)
 
 			if tt.wantErr == true {
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+const (
+	escalateLogEntry = "escalate_log_entry"
+
+	// nightEndHour is the local hour until which the time from midnight is night time
+	nightEndHour = 7
+)
+
+// timedLogEntry is a log entry with its parsed creation time.
+type timedLogEntry struct {
+	*api.LogEntry
+	at time.Time
+}
+
+// userPage is a user paged by an incident.
+type userPage struct {
+	userName     string
+	at           time.Time
+	acknowledged bool
+	escalated    bool
+}
+
+type userResponses struct {
+	metrics    *report.UserMetrics
+	timesToAck []time.Duration
+}
+
+// getResponseMetrics computes how the users on call in the schedules responded to the incidents. A user is
+// paged once per incident, when first notified while on call, and the time to acknowledge runs from then
+// to the user's first acknowledgement. An escalation not made by a user counts against every paged user
+// who hadn't acknowledged the incident yet.
+func (pd *pagerDutyClient) getResponseMetrics(ctx context.Context, incidentLogs []*incidentLog, rotations []*scheduleRotation) ([]*report.UserMetrics, error) {
+	usersResponses := make(map[string]*userResponses)
+	responsesOf := func(userName string) *userResponses {
+		responses, ok := usersResponses[userName]
+		if !ok {
+			responses = &userResponses{metrics: &report.UserMetrics{UserName: userName}}
+			usersResponses[userName] = responses
+		}
+		return responses
+	}
+	for _, rotation := range rotations {
+		for _, scheduleUser := range rotation.data.RotaUsers {
+			responsesOf(scheduleUser.Name)
+		}
+	}
+
+	for _, loggedIncident := range incidentLogs {
+		logEntries, err := sortLogEntries(loggedIncident.logEntries)
+		if err != nil {
+			return nil, fmt.Errorf("incident %s: %w", loggedIncident.incident.ID, err)
+		}
+
+		pages := make(map[string]*userPage)
+		for _, logEntry := range logEntries {
+			switch logEntry.Type {
+			case notifyLogEntry:
+				if logEntry.User.ID == "" || pages[logEntry.User.ID] != nil {
+					continue
+				}
+				rotation, userRotaInfo := findOnCallRotation(rotations, logEntry.User.ID, logEntry.at)
+				if rotation == nil {
+					continue
+				}
+				pages[logEntry.User.ID] = &userPage{userName: userRotaInfo.Name, at: logEntry.at}
+
+				responses := responsesOf(userRotaInfo.Name)
+				responses.metrics.Pages++
+				localPagedAt, err := pd.convertToUserLocalTimezone(ctx, logEntry.at, logEntry.User.ID)
+				if err != nil {
+					return nil, fmt.Errorf("aborted due to failed to convert to user local timezone: %w", err)
+				}
+				if localPagedAt.Hour() < nightEndHour {
+					responses.metrics.NightPages++
+				}
+			case acknowledgeLogEntry:
+				page := pages[logEntry.Agent.ID]
+				if page == nil || page.acknowledged {
+					continue
+				}
+				page.acknowledged = true
+				responses := responsesOf(page.userName)
+				responses.timesToAck = append(responses.timesToAck, logEntry.at.Sub(page.at))
+			case escalateLogEntry:
+				if logEntry.Agent.ID != "" {
+					continue // escalated by a user, not for lack of acknowledgement
+				}
+				for _, page := range pages {
+					if !page.acknowledged && !page.escalated {
+						page.escalated = true
+						responsesOf(page.userName).metrics.Escalations++
+					}
+				}
+			}
+		}
+	}
+
+	result := make([]*report.UserMetrics, 0, len(usersResponses))
+	for _, responses := range usersResponses {
+		responses.metrics.Acknowledged = len(responses.timesToAck)
+		responses.metrics.MeanTimeToAckMinutes, responses.metrics.P90TimeToAckMinutes = timeToAckStats(responses.timesToAck)
+		result = append(result, responses.metrics)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].UserName < result[j].UserName
+	})
+	return result, nil
+}
+
+// sortLogEntries returns the log entries from the oldest.
+func sortLogEntries(logEntries []*api.LogEntry) ([]*timedLogEntry, error) {
+	timedLogEntries := make([]*timedLogEntry, 0, len(logEntries))
+	for _, logEntry := range logEntries {
+		at, err := time.Parse(time.RFC3339, logEntry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		timedLogEntries = append(timedLogEntries, &timedLogEntry{LogEntry: logEntry, at: at})
+	}
+	sort.SliceStable(timedLogEntries, func(i, j int) bool {
+		return timedLogEntries[i].at.Before(timedLogEntries[j].at)
+	})
+	return timedLogEntries, nil
+}
+
+// timeToAckStats returns the mean and the 90th percentile (nearest rank) in minutes.
+func timeToAckStats(timesToAck []time.Duration) (float32, float32) {
+	if len(timesToAck) == 0 {
+		return 0, 0
+	}
+	sorted := append([]time.Duration(nil), timesToAck...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	var total time.Duration
+	for _, timeToAck := range sorted {
+		total += timeToAck
+	}
+	mean := total.Minutes() / float64(len(sorted))
+	p90 := sorted[int(math.Ceil(0.9*float64(len(sorted))))-1].Minutes()
+	return float32(mean), float32(p90)
+}
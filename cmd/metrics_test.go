@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pagerDutyClient_getResponseMetrics(t *testing.T) {
+	onCall := func(start, end string) []*api.UserRotaPeriod {
+		startTime, _ := time.Parse(time.RFC3339, start)
+		endTime, _ := time.Parse(time.RFC3339, end)
+		return []*api.UserRotaPeriod{{Start: startTime, End: endTime}}
+	}
+	rotations := []*scheduleRotation{
+		{
+			info: &api.ScheduleInfo{ID: "SCHED1", Name: "Payments primary"},
+			users: api.ScheduleUserRotationData{
+				"USER1": {ID: "USER1", Name: "John Doe", Periods: onCall("2026-09-01T08:00:00Z", "2026-09-08T08:00:00Z")},
+				"USER2": {ID: "USER2", Name: "Mary Jane", Periods: onCall("2026-09-08T08:00:00Z", "2026-09-15T08:00:00Z")},
+			},
+			data: &report.ScheduleData{RotaUsers: []*report.ScheduleUser{{Name: "John Doe"}, {Name: "Mary Jane"}}},
+		},
+	}
+	notify := func(userID, at string) *api.LogEntry {
+		return &api.LogEntry{Type: notifyLogEntry, CreatedAt: at, User: api.User{ID: userID}}
+	}
+	acknowledge := func(userID, at string) *api.LogEntry {
+		return &api.LogEntry{Type: acknowledgeLogEntry, CreatedAt: at, Agent: api.User{ID: userID}}
+	}
+	escalate := func(userID, at string) *api.LogEntry {
+		return &api.LogEntry{Type: escalateLogEntry, CreatedAt: at, Agent: api.User{ID: userID}}
+	}
+	incident := func(id string, logEntries ...*api.LogEntry) *incidentLog {
+		return &incidentLog{incident: &api.Incident{ID: id}, logEntries: logEntries}
+	}
+
+	tests := []struct {
+		name         string
+		incidentLogs []*incidentLog
+		want         []*report.UserMetrics
+		wantErr      bool
+	}{
+		{
+			name: "Pages are counted once per incident with the time to acknowledge",
+			incidentLogs: []*incidentLog{
+				incident("INC1",
+					acknowledge("USER1", "2026-09-02T10:04:00Z"),
+					notify("USER1", "2026-09-02T10:00:30Z"),
+					notify("USER1", "2026-09-02T10:00:00Z"),
+				),
+				incident("INC2",
+					notify("USER1", "2026-09-03T10:00:00Z"),
+					acknowledge("USER1", "2026-09-03T10:10:00Z"),
+				),
+				incident("INC3",
+					notify("USER1", "2026-09-04T10:00:00Z"),
+					acknowledge("USER1", "2026-09-04T10:02:00Z"),
+				),
+			},
+			want: []*report.UserMetrics{
+				{UserName: "John Doe", Pages: 3, Acknowledged: 3, MeanTimeToAckMinutes: 16.0 / 3, P90TimeToAckMinutes: 10},
+				{UserName: "Mary Jane"},
+			},
+		},
+		{
+			name: "Escalations for lack of acknowledgement count against the paged users",
+			incidentLogs: []*incidentLog{
+				incident("INC1",
+					notify("USER1", "2026-09-05T02:00:00Z"),
+					escalate("", "2026-09-05T02:15:00Z"),
+					notify("USER2", "2026-09-05T02:15:00Z"),
+					escalate("", "2026-09-05T02:30:00Z"),
+					acknowledge("USER1", "2026-09-05T02:32:00Z"),
+				),
+				incident("INC2",
+					notify("USER2", "2026-09-10T10:00:00Z"),
+					escalate("USER2", "2026-09-10T10:01:00Z"),
+				),
+			},
+			want: []*report.UserMetrics{
+				{UserName: "John Doe", Pages: 1, Acknowledged: 1, MeanTimeToAckMinutes: 32, P90TimeToAckMinutes: 32, Escalations: 1, NightPages: 1},
+				{UserName: "Mary Jane", Pages: 1},
+			},
+		},
+		{
+			name: "Pages of users off call are ignored",
+			incidentLogs: []*incidentLog{
+				incident("INC1",
+					notify("USER2", "2026-09-02T10:00:00Z"),
+					acknowledge("USER2", "2026-09-02T10:01:00Z"),
+				),
+			},
+			want: []*report.UserMetrics{
+				{UserName: "John Doe"},
+				{UserName: "Mary Jane"},
+			},
+		},
+		{
+			name: "Invalid log entry date fails",
+			incidentLogs: []*incidentLog{
+				incident("INC1", notify("USER1", "02/09/2026")),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pd := &pagerDutyClient{
+				cachedUsers: []*api.User{
+					{ID: "USER1", Name: "John Doe", Timezone: "Europe/London"},
+					{ID: "USER2", Name: "Mary Jane", Timezone: "Europe/Madrid"},
+				},
+			}
+
+			got, err := pd.getResponseMetrics(context.Background(), tt.incidentLogs, rotations)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_pagerDutyClient_generateReport_metricsJSON(t *testing.T) {
+	pd := newFakeServerReport(t)
+	metrics, outputFormat = true, "json"
+	t.Cleanup(func() {
+		metrics = false
+	})
+	require.NoError(t, pd.generateReport(context.Background()))
+
+	content, err := os.ReadFile(filepath.Join(directory, "pagerduty_oncall_report.9-2026.json"))
+	require.NoError(t, err)
+
+	var data struct {
+		Currency              string
+		UsersSchedulesSummary []*report.ScheduleUser
+		Metrics               []*report.UserMetrics
+	}
+	require.NoError(t, json.Unmarshal(content, &data))
+	assert.Equal(t, "£", data.Currency)
+	assert.Len(t, data.UsersSchedulesSummary, 2)
+	assert.Equal(t, []*report.UserMetrics{
+		{UserName: "John Doe", Pages: 1, Acknowledged: 1, MeanTimeToAckMinutes: 4, P90TimeToAckMinutes: 4},
+		{UserName: "Mary Jane", Pages: 1, Acknowledged: 1, MeanTimeToAckMinutes: 5, P90TimeToAckMinutes: 5},
+	}, data.Metrics)
+}
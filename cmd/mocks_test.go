@@ -3,6 +3,8 @@
 package cmd
 
 import (
+	context "context"
+
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
 
 	mock "github.com/stretchr/testify/mock"
@@ -13,13 +15,13 @@ type clientMock struct {
 	mock.Mock
 }
 
-// GetSchedule provides a mock function with given fields: scheduleID, startDate, endDate
-func (_m *clientMock) GetSchedule(scheduleID string, startDate string, endDate string) (*api.Schedule, error) {
-	ret := _m.Called(scheduleID, startDate, endDate)
+// GetSchedule provides a mock function with given fields: ctx, scheduleID, startDate, endDate
+func (_m *clientMock) GetSchedule(ctx context.Context, scheduleID string, startDate string, endDate string) (*api.Schedule, error) {
+	ret := _m.Called(ctx, scheduleID, startDate, endDate)
 
 	var r0 *api.Schedule
-	if rf, ok := ret.Get(0).(func(string, string, string) *api.Schedule); ok {
-		r0 = rf(scheduleID, startDate, endDate)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *api.Schedule); ok {
+		r0 = rf(ctx, scheduleID, startDate, endDate)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*api.Schedule)
@@ -27,8 +29,8 @@ func (_m *clientMock) GetSchedule(scheduleID string, startDate string, endDate s
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
-		r1 = rf(scheduleID, startDate, endDate)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, scheduleID, startDate, endDate)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -36,13 +38,13 @@ func (_m *clientMock) GetSchedule(scheduleID string, startDate string, endDate s
 	return r0, r1
 }
 
-// ListUsers provides a mock function with given fields:
-func (_m *clientMock) ListUsers() ([]*api.User, error) {
-	ret := _m.Called()
+// ListUsers provides a mock function with given fields: ctx
+func (_m *clientMock) ListUsers(ctx context.Context) ([]*api.User, error) {
+	ret := _m.Called(ctx)
 
 	var r0 []*api.User
-	if rf, ok := ret.Get(0).(func() []*api.User); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []*api.User); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*api.User)
@@ -50,8 +52,8 @@ func (_m *clientMock) ListUsers() ([]*api.User, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -59,13 +61,13 @@ func (_m *clientMock) ListUsers() ([]*api.User, error) {
 	return r0, r1
 }
 
-// ListTeams provides a mock function with given fields:
-func (_m *clientMock) ListTeams() ([]*api.Team, error) {
-	ret := _m.Called()
+// ListTeams provides a mock function with given fields: ctx
+func (_m *clientMock) ListTeams(ctx context.Context) ([]*api.Team, error) {
+	ret := _m.Called(ctx)
 
 	var r0 []*api.Team
-	if rf, ok := ret.Get(0).(func() []*api.Team); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []*api.Team); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*api.Team)
@@ -73,8 +75,8 @@ func (_m *clientMock) ListTeams() ([]*api.Team, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -82,13 +84,13 @@ func (_m *clientMock) ListTeams() ([]*api.Team, error) {
 	return r0, r1
 }
 
-// ListServices provides a mock function with given fields: _a0
-func (_m *clientMock) ListServices(_a0 string) ([]*api.Service, error) {
-	ret := _m.Called(_a0)
+// ListServices provides a mock function with given fields: ctx, teamID
+func (_m *clientMock) ListServices(ctx context.Context, teamID string) ([]*api.Service, error) {
+	ret := _m.Called(ctx, teamID)
 
 	var r0 []*api.Service
-	if rf, ok := ret.Get(0).(func(string) []*api.Service); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*api.Service); ok {
+		r0 = rf(ctx, teamID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*api.Service)
@@ -96,8 +98,8 @@ func (_m *clientMock) ListServices(_a0 string) ([]*api.Service, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, teamID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -105,13 +107,13 @@ func (_m *clientMock) ListServices(_a0 string) ([]*api.Service, error) {
 	return r0, r1
 }
 
-// ListSchedules provides a mock function with given fields:
-func (_m *clientMock) ListSchedules() ([]*api.Schedule, error) {
-	ret := _m.Called()
+// ListSchedules provides a mock function with given fields: ctx
+func (_m *clientMock) ListSchedules(ctx context.Context) ([]*api.Schedule, error) {
+	ret := _m.Called(ctx)
 
 	var r0 []*api.Schedule
-	if rf, ok := ret.Get(0).(func() []*api.Schedule); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []*api.Schedule); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*api.Schedule)
@@ -119,8 +121,77 @@ func (_m *clientMock) ListSchedules() ([]*api.Schedule, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOverrides provides a mock function with given fields: ctx, scheduleID, startDate, endDate
+func (_m *clientMock) ListOverrides(ctx context.Context, scheduleID string, startDate string, endDate string) ([]*api.Override, error) {
+	ret := _m.Called(ctx, scheduleID, startDate, endDate)
+
+	var r0 []*api.Override
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) []*api.Override); ok {
+		r0 = rf(ctx, scheduleID, startDate, endDate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*api.Override)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, scheduleID, startDate, endDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListIncidents provides a mock function with given fields: ctx, startDate, endDate, serviceIDs
+func (_m *clientMock) ListIncidents(ctx context.Context, startDate string, endDate string, serviceIDs []string) ([]*api.Incident, error) {
+	ret := _m.Called(ctx, startDate, endDate, serviceIDs)
+
+	var r0 []*api.Incident
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) []*api.Incident); ok {
+		r0 = rf(ctx, startDate, endDate, serviceIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*api.Incident)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []string) error); ok {
+		r1 = rf(ctx, startDate, endDate, serviceIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListIncidentLogEntries provides a mock function with given fields: ctx, incidentID
+func (_m *clientMock) ListIncidentLogEntries(ctx context.Context, incidentID string) ([]*api.LogEntry, error) {
+	ret := _m.Called(ctx, incidentID)
+
+	var r0 []*api.LogEntry
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*api.LogEntry); ok {
+		r0 = rf(ctx, incidentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*api.LogEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, incidentID)
 	} else {
 		r1 = ret.Error(1)
 	}
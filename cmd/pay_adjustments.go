@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+// paidTime is an on-call time of a user, a period, a shift or a day, with its hourly pay.
+type paidTime struct {
+	start  time.Time
+	end    time.Time
+	hours  float32
+	amount float32
+}
+
+// hourlyAmount is the pay of the hours counted so far for the user.
+func hourlyAmount(scheduleUserData *report.ScheduleUser, pricesInfo *configuration.PricesInfo) float32 {
+	return scheduleUserData.NumWorkHours*pricesInfo.WeekDayHourlyPrice +
+		scheduleUserData.NumWeekendHours*pricesInfo.WeekendDayHourlyPrice +
+		scheduleUserData.NumBankHolidaysHours*pricesInfo.BhDayHourlyPrice
+}
+
+// dailyPay is the pay of a user per day, to cap it per week and per month.
+type dailyPay map[string]float32
+
+func (p dailyPay) add(day time.Time, amount float32) {
+	p[day.Format("2006-01-02")] += amount
+}
+
+// addPaidTime adds hours counted at the local date, and their pay, to the period and to the day.
+func addPaidTime(period *paidTime, days map[string]*paidTime, localDate time.Time, hours, amount float32) {
+	period.hours += hours
+	period.amount += amount
+
+	key := localDate.Format("2006-01-02")
+	day, ok := days[key]
+	if !ok {
+		day = &paidTime{}
+		days[key] = day
+	}
+	day.hours += hours
+	day.amount += amount
+}
+
+// mergePaidShifts merges the overlapping or back to back periods into shifts.
+func mergePaidShifts(periods []*paidTime) []*paidTime {
+	sorted := make([]*paidTime, len(periods))
+	copy(sorted, periods)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].start.Before(sorted[j].start)
+	})
+
+	shifts := make([]*paidTime, 0, len(sorted))
+	for _, period := range sorted {
+		if len(shifts) > 0 && !period.start.After(shifts[len(shifts)-1].end) {
+			last := shifts[len(shifts)-1]
+			if period.end.After(last.end) {
+				last.end = period.end
+			}
+			last.hours += period.hours
+			last.amount += period.amount
+			continue
+		}
+		shift := *period
+		shifts = append(shifts, &shift)
+	}
+	return shifts
+}
+
+// minimumsAdjustment is the top-up of the hourly pay of the completed shifts, or of the days, to their guaranteed
+// minimum, and the top-ups per day, paid on the first day of the shift. When several minimums apply, the largest
+// top-up is paid.
+func minimumsAdjustment(periods []*paidTime, days map[string]*paidTime) (float32, dailyPay) {
+	var adjustment float32
+	adjustmentDays := dailyPay{}
+	shifts := mergePaidShifts(periods)
+	for _, minimum := range Config.PayAdjustments.Minimums {
+		var topUp float32
+		topUpDays := dailyPay{}
+		if minimum.Per == "shift" {
+			for _, shift := range shifts {
+				if shift.end.Sub(shift.start).Hours() >= float64(minimum.MinHours) && shift.amount < float32(minimum.Amount) {
+					topUp += float32(minimum.Amount) - shift.amount
+					topUpDays.add(shift.start, float32(minimum.Amount)-shift.amount)
+				}
+			}
+		} else {
+			for key, day := range days {
+				if day.hours > 0 && day.hours >= float32(minimum.MinHours) && day.amount < float32(minimum.Amount) {
+					topUp += float32(minimum.Amount) - day.amount
+					topUpDays[key] += float32(minimum.Amount) - day.amount
+				}
+			}
+		}
+
+		if topUp > adjustment {
+			adjustment, adjustmentDays = topUp, topUpDays
+		}
+	}
+	return adjustment, adjustmentDays
+}
+
+// applyPayCaps reduces the adjusted pay of each user, across all the schedules, to their cap of each ISO week and
+// then of each calendar month. A week or month cut by the report range is capped on its reported days.
+func applyPayCaps(users []*report.ScheduleUser, rotations []*scheduleRotation) {
+	if len(Config.PayAdjustments.Caps) == 0 {
+		return
+	}
+
+	userIDs := userIDsByName(rotations)
+	for _, user := range users {
+		pay := dailyPay{}
+		for _, rotation := range rotations {
+			for day, amount := range rotation.pay[user.Name] {
+				pay[day] += amount
+			}
+		}
+
+		var reduction float32
+		for _, period := range []string{"week", "month"} {
+			payCap, found := Config.FindPayCap(userIDs[user.Name], period)
+			if found {
+				reduction += capPay(pay, period, float32(payCap.MaxAmount))
+			}
+		}
+		user.TotalAmountAdjustment -= reduction
+	}
+}
+
+// capPay reduces the pay of the days of each ISO week, or calendar month, over the cap in proportion to their pay,
+// and returns the pay taken off.
+func capPay(pay dailyPay, period string, maxAmount float32) float32 {
+	periodDays := make(map[string][]string)
+	periodAmounts := make(map[string]float32)
+	for day, amount := range pay {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		key := date.Format("2006-01")
+		if period == "week" {
+			year, week := date.ISOWeek()
+			key = fmt.Sprintf("%d-W%02d", year, week)
+		}
+		periodDays[key] = append(periodDays[key], day)
+		periodAmounts[key] += amount
+	}
+
+	var reduction float32
+	for key, amount := range periodAmounts {
+		if amount <= maxAmount {
+			continue
+		}
+		for _, day := range periodDays[key] {
+			pay[day] *= maxAmount / amount
+		}
+		reduction += amount - maxAmount
+	}
+	return reduction
+}
+
+// userIDsByName maps the names of the users of the rotations to their ids.
+func userIDsByName(rotations []*scheduleRotation) map[string]string {
+	userIDs := make(map[string]string)
+	for _, rotation := range rotations {
+		for _, userRotaInfo := range rotation.users {
+			userIDs[userRotaInfo.Name] = userRotaInfo.ID
+		}
+	}
+	return userIDs
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_minimumsAdjustment(t *testing.T) {
+	monday := time.Date(2026, time.September, 7, 8, 0, 0, 0, time.UTC)
+	periods := []*paidTime{
+		// a weekly shift split by an override
+		{start: monday.AddDate(0, 0, 3), end: monday.AddDate(0, 0, 7), hours: 96, amount: 5},
+		{start: monday, end: monday.AddDate(0, 0, 3), hours: 72, amount: 3},
+		// an uncompleted shift
+		{start: monday.AddDate(0, 0, 14), end: monday.AddDate(0, 0, 16), hours: 48, amount: 2},
+	}
+	days := map[string]*paidTime{
+		"2026-09-07": {hours: 16, amount: 0.5},
+		"2026-09-08": {hours: 24, amount: 1},
+		"2026-09-09": {hours: 24, amount: 3},
+	}
+
+	tests := []struct {
+		name     string
+		minimums []configuration.PayMinimum
+		want     float32
+		wantDays dailyPay
+	}{
+		{
+			name:     "no minimums",
+			want:     0,
+			wantDays: dailyPay{},
+		},
+		{
+			name:     "completed shifts are topped up",
+			minimums: []configuration.PayMinimum{{Per: "shift", MinHours: 168, Amount: 10}},
+			want:     2,
+			wantDays: dailyPay{"2026-09-07": 2},
+		},
+		{
+			name:     "days on call long enough are topped up",
+			minimums: []configuration.PayMinimum{{Per: "day", MinHours: 20, Amount: 2}},
+			want:     1,
+			wantDays: dailyPay{"2026-09-08": 1},
+		},
+		{
+			name: "the largest top-up is paid",
+			minimums: []configuration.PayMinimum{
+				{Per: "shift", MinHours: 168, Amount: 10},
+				{Per: "day", Amount: 2},
+			},
+			want:     2.5,
+			wantDays: dailyPay{"2026-09-07": 1.5, "2026-09-08": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = &configuration.Configuration{PayAdjustments: configuration.PayAdjustments{Minimums: tt.minimums}}
+			defer func() { Config = nil }()
+
+			got, gotDays := minimumsAdjustment(periods, days)
+			assert.InDelta(t, tt.want, got, 0.0001)
+			assert.InDeltaMapValues(t, tt.wantDays, gotDays, 0.0001)
+		})
+	}
+}
+
+func Test_applyPayCaps(t *testing.T) {
+	rotations := []*scheduleRotation{
+		{
+			users: api.ScheduleUserRotationData{
+				"USER1": {ID: "USER1", Name: "John Doe"},
+				"USER2": {ID: "USER2", Name: "Mary Jane"},
+			},
+			pay: map[string]dailyPay{
+				"John Doe": {"2026-09-07": 50, "2026-09-08": 40},
+				// the weeks 37 and 38, and the week 40 across September and October
+				"Mary Jane": {"2026-09-07": 100, "2026-09-14": 100, "2026-09-30": 100},
+			},
+		},
+		{
+			users: api.ScheduleUserRotationData{"USER2": {ID: "USER2", Name: "Mary Jane"}},
+			pay: map[string]dailyPay{
+				"John Doe":  {"2026-09-08": 10},
+				"Mary Jane": {"2026-10-01": 50},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		caps []configuration.PayCap
+		want map[string]float32
+	}{
+		{
+			name: "no caps",
+			want: map[string]float32{"John Doe": 5, "Mary Jane": 0},
+		},
+		{
+			name: "users get their own cap or the default one across the schedules",
+			caps: []configuration.PayCap{
+				{Period: "month", MaxAmount: 80},
+				{UserID: "USER2", Period: "month", MaxAmount: 1000},
+			},
+			want: map[string]float32{"John Doe": -15, "Mary Jane": 0},
+		},
+		{
+			name: "each ISO week is capped separately",
+			caps: []configuration.PayCap{{UserID: "USER2", Period: "week", MaxAmount: 120}},
+			want: map[string]float32{"John Doe": 5, "Mary Jane": -30},
+		},
+		{
+			name: "each month is capped separately, after the weeks",
+			caps: []configuration.PayCap{
+				{Period: "month", MaxAmount: 1000},
+				{UserID: "USER2", Period: "week", MaxAmount: 120},
+				{UserID: "USER2", Period: "month", MaxAmount: 250},
+			},
+			// the week 40 is capped to 80 in September and 40 in October, then September to 250
+			want: map[string]float32{"John Doe": 5, "Mary Jane": -60},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = &configuration.Configuration{PayAdjustments: configuration.PayAdjustments{Caps: tt.caps}}
+			defer func() { Config = nil }()
+			users := []*report.ScheduleUser{
+				{Name: "John Doe", TotalAmount: 95, TotalAmountAdjustment: 5},
+				{Name: "Mary Jane", TotalAmount: 350},
+			}
+
+			applyPayCaps(users, rotations)
+
+			for _, user := range users {
+				assert.InDelta(t, tt.want[user.Name], user.TotalAmountAdjustment, 0.0001, user.Name)
+			}
+		})
+	}
+}
+
+func Test_generateScheduleData_minimums(t *testing.T) {
+	monday := time.Date(2026, time.September, 7, 8, 0, 0, 0, time.UTC)
+	saturday := monday.AddDate(0, 0, 5)
+
+	tests := []struct {
+		name     string
+		minimums []configuration.PayMinimum
+		periods  []*api.UserRotaPeriod
+		want     float32
+	}{
+		{
+			// 120 weekday hours and 48 weekend hours are paid 216
+			name:     "a completed shift is topped up",
+			minimums: []configuration.PayMinimum{{Per: "shift", MinHours: 168, Amount: 300}},
+			periods:  []*api.UserRotaPeriod{{Start: monday, End: monday.AddDate(0, 0, 7)}},
+			want:     84,
+		},
+		{
+			name:     "a shift split by an override is completed",
+			minimums: []configuration.PayMinimum{{Per: "shift", MinHours: 168, Amount: 300}},
+			periods: []*api.UserRotaPeriod{
+				{Start: saturday, End: monday.AddDate(0, 0, 7), Override: true},
+				{Start: monday, End: saturday},
+			},
+			want: 84,
+		},
+		{
+			name:     "an uncompleted shift isn't topped up",
+			minimums: []configuration.PayMinimum{{Per: "shift", MinHours: 168, Amount: 300}},
+			periods:  []*api.UserRotaPeriod{{Start: monday, End: saturday}},
+			want:     0,
+		},
+		{
+			// from Tuesday to Friday, the days on call all day are paid 24 each
+			name:     "days on call long enough are topped up",
+			minimums: []configuration.PayMinimum{{Per: "day", MinHours: 20, Amount: 30}},
+			periods:  []*api.UserRotaPeriod{{Start: monday, End: monday.AddDate(0, 0, 7)}},
+			want:     24,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestConfig(t)
+			Config.PayAdjustments = configuration.PayAdjustments{Minimums: tt.minimums}
+
+			users := generateTestScheduleUsers(t, api.ScheduleUserRotationData{
+				"USER1": {ID: "USER1", Name: "John Doe", Periods: tt.periods},
+			})
+
+			user := users["John Doe"]
+			assert.InDelta(t, tt.want, user.TotalAmountAdjustment, 0.0001)
+			assert.InDelta(t, hourlyTestAmount(user)+tt.want, user.AdjustedTotalAmount(), 0.0001)
+		})
+	}
+}
@@ -1,81 +1,186 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
 )
 
 var (
-	cfgFile string
-	Config  *configuration.Configuration
+	cfgFiles  []string
+	profile   string
+	tokenFile string
+	timeout   time.Duration
+	Config    *configuration.Configuration
+
+	cancelTimeout context.CancelFunc = func() {}
 )
 
 type client interface {
-	ListUsers() ([]*api.User, error)
-	ListTeams() ([]*api.Team, error)
-	ListServices(string) ([]*api.Service, error)
-	ListSchedules() ([]*api.Schedule, error)
-	GetSchedule(scheduleID, startDate, endDate string) (*api.Schedule, error)
+	ListUsers(ctx context.Context) ([]*api.User, error)
+	ListTeams(ctx context.Context) ([]*api.Team, error)
+	ListServices(ctx context.Context, teamID string) ([]*api.Service, error)
+	ListSchedules(ctx context.Context) ([]*api.Schedule, error)
+	GetSchedule(ctx context.Context, scheduleID, startDate, endDate string) (*api.Schedule, error)
+	ListOverrides(ctx context.Context, scheduleID, startDate, endDate string) ([]*api.Override, error)
+	ListIncidents(ctx context.Context, startDate, endDate string, serviceIDs []string) ([]*api.Incident, error)
+	ListIncidentLogEntries(ctx context.Context, incidentID string) ([]*api.LogEntry, error)
 }
 
 type pagerDutyClient struct {
 	client client
 
-	cachedUsers []*api.User
+	cachedUsers  []*api.User
+	cachedLeaves map[string][]configuration.Leave
+
+	inferredCalendars map[string]*report.InferredCalendar
 
 	defaultUserTimezone string
+
+	scheduleWindowDays  int
+	scheduleConcurrency int
 }
 
 func init() {
-	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "configuration file (default is ~/.pd-report-config.yml)")
+	rootCmd.PersistentFlags().StringSliceVar(&cfgFiles, "config", nil, "configuration file(s), merged in the given order (default is ~/.pd-report-config.yml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "configuration profile to apply over the base configuration")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "maximum duration of the command, e.g. 5m (default is no limit)")
+	rootCmd.PersistentFlags().StringVar(&tokenFile, "token-file", "", "file with the PagerDuty token (overrides PD_AUTH_TOKEN and the configuration)")
 
 	viper.SetDefault("rotationStartHour", "08:00:00")
 	viper.SetDefault("currency", "£")
 }
 
+// applyTimeout limits the context of the executed command to the --timeout duration.
+func applyTimeout(cmd *cobra.Command) {
+	if timeout <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	cancelTimeout = cancel
+	cmd.SetContext(ctx)
+}
+
 func initConfig() {
-	// Don't forget to read model either from cfgFile or from home directory!
-	if cfgFile != "" {
-		// Use model file from the flag.
-		viper.SetConfigFile(cfgFile)
-		log.Println("Reading configuration file:", cfgFile)
+	if err := readConfig(); err != nil {
+		log.Fatal("Can't read config: ", err)
+	}
+
+	Config = configuration.New()
+	err := unmarshalConfig(Config)
+	if err != nil {
+		log.Fatal("Can't unmarshal config: ", err)
+	}
+}
+
+// newAPIClient creates the PagerDuty client with the token from --token-file or the configuration
+// and the configured API endpoint and HTTP transport.
+func newAPIClient(config *configuration.Configuration) (*api.PagerDutyClient, error) {
+	var authToken string
+	var err error
+	if tokenFile != "" {
+		authToken, err = configuration.ReadAuthTokenFile(tokenFile)
+	} else {
+		authToken, err = config.AuthToken()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return api.NewPagerDutyAPIClient(authToken, api.ClientOptions{
+		BaseURL:   config.PdAPI.BaseURL,
+		Proxy:     config.PdAPI.Proxy,
+		CABundle:  config.PdAPI.CABundle,
+		Timeout:   config.PdAPI.Timeout,
+		UserAgent: config.PdAPI.UserAgent,
+	})
+}
+
+func unmarshalConfig(config *configuration.Configuration) error {
+	return viper.Unmarshal(config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		configuration.TimeToStringHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
+}
+
+func readConfig() error {
+	viper.SetConfigType("yaml")
+
+	// Don't forget to read model either from cfgFiles or from home directory!
+	if len(cfgFiles) > 0 {
+		// Use model files from the flag, later files override the previous ones.
+		for i, cfgFile := range cfgFiles {
+			viper.SetConfigFile(cfgFile)
+			log.Println("Reading configuration file:", cfgFile)
+
+			var err error
+			if i == 0 {
+				err = viper.ReadInConfig()
+			} else {
+				err = viper.MergeInConfig()
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", cfgFile, err)
+			}
+		}
 	} else {
 		// Find home directory.
 		home, err := homedir.Dir()
 		if err != nil {
-			log.Fatal("Can't get the homedir: ", err)
+			return fmt.Errorf("can't get the homedir: %w", err)
 		}
 
 		viper.AddConfigPath(home)
 		viper.SetConfigName(".pd-report-config")
 		log.Println("Reading configuration file:", fmt.Sprintf("%s/.pd-report-config-yml", home))
-	}
 
-	viper.SetConfigType("yaml")
+		if err := viper.ReadInConfig(); err != nil {
+			return err
+		}
+	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		log.Fatal("Can't read config: ", err)
+	if profile != "" {
+		if err := applyProfile(profile); err != nil {
+			return err
+		}
 	}
 
 	viper.AutomaticEnv()
-	if err := viper.BindEnv("PD_AUTH_TOKEN"); err != nil {
-		log.Fatal(err)
-	}
+	return viper.BindEnv("PD_AUTH_TOKEN")
+}
 
-	Config = configuration.New()
-	err := viper.Unmarshal(&Config)
-	if err != nil {
-		log.Fatalf("%v, %#v", err, Config)
+// applyProfile merges the named entry of the 'profiles' section over the base configuration.
+func applyProfile(name string) error {
+	profiles := viper.GetStringMap("profiles")
+	profileConfig, ok := profiles[strings.ToLower(name)].(map[string]interface{})
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for profileName := range profiles {
+			names = append(names, profileName)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("profile '%s' not found, available profiles: [%s]", name, strings.Join(names, ", "))
 	}
+
+	log.Println("Using configuration profile:", name)
+	return viper.MergeConfigMap(profileConfig)
 }
 
 var rootCmd = &cobra.Command{
@@ -84,10 +189,20 @@ var rootCmd = &cobra.Command{
 	Long: `Generate on-call rotation reports automatically
 from your PagerDuty account.`,
 	SilenceUsage: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		applyTimeout(cmd)
+		initConfig()
+	},
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels the running command, which removes the partially written reports
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	cancelTimeout()
+	stop()
+
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
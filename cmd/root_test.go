@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseConfig = `
+rotationPrices:
+  currency: £
+  daysInfo:
+    - day: weekday
+      price: 1
+    - day: weekend
+      price: 2
+    - day: bankholiday
+      price: 2
+schedulesToIgnore:
+  - SCHED1
+profiles:
+  payments:
+    rotationPrices:
+      currency: €
+    schedulesToIgnore:
+      - SCHED2
+      - SCHED3
+`
+
+const teamOverlayConfig = `
+rotationUsers:
+  - userId: USER1
+    name: John Doe
+    holidaysCalendar: uk
+`
+
+func Test_readConfig(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.yml")
+	overlayPath := filepath.Join(dir, "team.yml")
+	require.NoError(t, os.WriteFile(basePath, []byte(baseConfig), 0o600))
+	require.NoError(t, os.WriteFile(overlayPath, []byte(teamOverlayConfig), 0o600))
+
+	tests := []struct {
+		name             string
+		files            []string
+		profile          string
+		wantCurrency     string
+		wantIgnored      []string
+		wantRotationUser bool
+		wantErr          bool
+	}{
+		{
+			name:         "Base configuration only",
+			files:        []string{basePath},
+			wantCurrency: "£",
+			wantIgnored:  []string{"SCHED1"},
+		},
+		{
+			name:             "Files are merged in order",
+			files:            []string{basePath, overlayPath},
+			wantCurrency:     "£",
+			wantIgnored:      []string{"SCHED1"},
+			wantRotationUser: true,
+		},
+		{
+			name:             "Profile is applied over the merged files",
+			files:            []string{basePath, overlayPath},
+			profile:          "payments",
+			wantCurrency:     "€",
+			wantIgnored:      []string{"SCHED2", "SCHED3"},
+			wantRotationUser: true,
+		},
+		{
+			name:    "Unknown profile fails",
+			files:   []string{basePath},
+			profile: "unknown",
+			wantErr: true,
+		},
+		{
+			name:    "Missing file fails",
+			files:   []string{basePath, filepath.Join(dir, "missing.yml")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			defer viper.Reset()
+			cfgFiles, profile = tt.files, tt.profile
+			defer func() { cfgFiles, profile = nil, "" }()
+
+			err := readConfig()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			config := &configuration.Configuration{}
+			require.NoError(t, unmarshalConfig(config))
+			assert.Equal(t, tt.wantCurrency, config.RotationPrices.Currency)
+			assert.Len(t, config.RotationPrices.DaysInfo, 3)
+			assert.Equal(t, tt.wantIgnored, config.SchedulesToIgnore)
+			assert.Equal(t, tt.wantRotationUser, len(config.RotationUsers) == 1)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+)
+
+const (
+	defaultScheduleWindowDays  = 31
+	defaultScheduleConcurrency = 4
+)
+
+type scheduleWindow struct {
+	start time.Time
+	end   time.Time
+}
+
+// scheduleWindows splits the range in consecutive windows of at most the given number of days.
+func scheduleWindows(start, end time.Time, days int) []scheduleWindow {
+	if !start.Before(end) {
+		return []scheduleWindow{{start: start, end: end}}
+	}
+
+	windows := make([]scheduleWindow, 0)
+	for windowStart := start; windowStart.Before(end); {
+		windowEnd := windowStart.AddDate(0, 0, days)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+		windows = append(windows, scheduleWindow{start: windowStart, end: windowEnd})
+		windowStart = windowEnd
+	}
+	return windows
+}
+
+// getSchedule fetches the schedule rendered for the range. Long ranges are fetched concurrently
+// in windows and their entries stitched back together.
+func (pd *pagerDutyClient) getSchedule(ctx context.Context, scheduleID string, startDate, endDate time.Time) (*api.Schedule, error) {
+	windowDays := pd.scheduleWindowDays
+	if windowDays <= 0 {
+		windowDays = defaultScheduleWindowDays
+	}
+	concurrency := pd.scheduleConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultScheduleConcurrency
+	}
+
+	windows := scheduleWindows(startDate, endDate, windowDays)
+	if len(windows) > 1 {
+		log.Printf("[%s] fetching the schedule in %d windows of %d days", scheduleID, len(windows), windowDays)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	schedules := make([]*api.Schedule, len(windows))
+	errs := make([]error, len(windows))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, window := range windows {
+		wg.Add(1)
+		go func(i int, window scheduleWindow) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			schedules[i], errs[i] = pd.client.GetSchedule(ctx, scheduleID,
+				window.start.Format(time.RFC3339), window.end.Format(time.RFC3339))
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i, window)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			if len(windows) > 1 {
+				return nil, fmt.Errorf("schedule %s from %s to %s: %w", scheduleID,
+					windows[i].start.Format(time.RFC3339), windows[i].end.Format(time.RFC3339), err)
+			}
+			return nil, err
+		}
+	}
+
+	return stitchSchedules(schedules)
+}
+
+// stitchSchedules joins the entries of consecutive windows of a schedule, merging the entries
+// cut at the window boundaries. Layers are stitched with the same layer of the other windows.
+func stitchSchedules(schedules []*api.Schedule) (*api.Schedule, error) {
+	stitched := *schedules[0]
+	stitched.FinalSchedule = api.ScheduleLayer{RenderedScheduleEntries: make([]api.RenderedScheduleEntry, 0)}
+	stitched.Layers = nil
+
+	for _, schedule := range schedules {
+		if err := stitchLayer(&stitched.FinalSchedule, schedule.FinalSchedule); err != nil {
+			return nil, err
+		}
+		for i, layer := range schedule.Layers {
+			if i == len(stitched.Layers) {
+				stitched.Layers = append(stitched.Layers, api.ScheduleLayer{RenderedScheduleEntries: make([]api.RenderedScheduleEntry, 0)})
+			}
+			if err := stitchLayer(&stitched.Layers[i], layer); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &stitched, nil
+}
+
+func stitchLayer(stitched *api.ScheduleLayer, layer api.ScheduleLayer) error {
+	for i, entry := range layer.RenderedScheduleEntries {
+		entries := stitched.RenderedScheduleEntries
+		if i == 0 && len(entries) > 0 {
+			last := &entries[len(entries)-1]
+			contiguous, err := sameInstant(last.End, entry.Start)
+			if err != nil {
+				return err
+			}
+			if contiguous && last.User.ID == entry.User.ID {
+				last.End = entry.End
+				continue
+			}
+		}
+		stitched.RenderedScheduleEntries = append(entries, entry)
+	}
+	return nil
+}
+
+func sameInstant(a, b string) (bool, error) {
+	timeA, err := time.Parse(time.RFC3339, a)
+	if err != nil {
+		return false, err
+	}
+	timeB, err := time.Parse(time.RFC3339, b)
+	if err != nil {
+		return false, err
+	}
+	return timeA.Equal(timeB), nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func scheduleWithEntries(entries ...api.RenderedScheduleEntry) *api.Schedule {
+	return &api.Schedule{
+		ID:            "SCHED1",
+		Name:          "Schedule 1",
+		TimeZone:      "UTC",
+		FinalSchedule: api.ScheduleLayer{RenderedScheduleEntries: entries},
+	}
+}
+
+func entry(userID, start, end string) api.RenderedScheduleEntry {
+	return api.RenderedScheduleEntry{Start: start, End: end, User: api.User{ID: userID}}
+}
+
+func Test_pagerDutyClient_getSchedule(t *testing.T) {
+	startDate := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		windowDays int
+		mockSetup  func(*clientMock)
+		want       []api.RenderedScheduleEntry
+		wantErr    bool
+	}{
+		{
+			name:       "Windows are stitched merging the entries cut at their boundaries",
+			windowDays: 31,
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("GetSchedule", mock.Anything, "SCHED1", "2026-01-01T00:00:00Z", "2026-02-01T00:00:00Z").Once().Return(
+					scheduleWithEntries(
+						entry("USER1", "2026-01-01T00:00:00Z", "2026-01-20T00:00:00Z"),
+						entry("USER2", "2026-01-20T00:00:00Z", "2026-02-01T00:00:00Z"),
+					), nil)
+				clientMock.On("GetSchedule", mock.Anything, "SCHED1", "2026-02-01T00:00:00Z", "2026-03-04T00:00:00Z").Once().Return(
+					scheduleWithEntries(
+						entry("USER2", "2026-02-01T00:00:00Z", "2026-02-10T00:00:00Z"),
+						entry("USER1", "2026-02-10T00:00:00Z", "2026-03-04T00:00:00Z"),
+					), nil)
+				clientMock.On("GetSchedule", mock.Anything, "SCHED1", "2026-03-04T00:00:00Z", "2026-03-15T00:00:00Z").Once().Return(
+					scheduleWithEntries(
+						entry("USER2", "2026-03-04T00:00:00Z", "2026-03-15T00:00:00Z"),
+					), nil)
+			},
+			want: []api.RenderedScheduleEntry{
+				entry("USER1", "2026-01-01T00:00:00Z", "2026-01-20T00:00:00Z"),
+				entry("USER2", "2026-01-20T00:00:00Z", "2026-02-10T00:00:00Z"),
+				entry("USER1", "2026-02-10T00:00:00Z", "2026-03-04T00:00:00Z"),
+				entry("USER2", "2026-03-04T00:00:00Z", "2026-03-15T00:00:00Z"),
+			},
+		},
+		{
+			name:       "Short ranges are fetched in a single call",
+			windowDays: 90,
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("GetSchedule", mock.Anything, "SCHED1", "2026-01-01T00:00:00Z", "2026-03-15T00:00:00Z").Once().Return(
+					scheduleWithEntries(
+						entry("USER1", "2026-01-01T00:00:00Z", "2026-03-15T00:00:00Z"),
+					), nil)
+			},
+			want: []api.RenderedScheduleEntry{
+				entry("USER1", "2026-01-01T00:00:00Z", "2026-03-15T00:00:00Z"),
+			},
+		},
+		{
+			name:       "Failed window fails",
+			windowDays: 31,
+			mockSetup: func(clientMock *clientMock) {
+				clientMock.On("GetSchedule", mock.Anything, "SCHED1", "2026-02-01T00:00:00Z", mock.Anything).Once().Return(nil, errors.New("failed"))
+				clientMock.On("GetSchedule", mock.Anything, "SCHED1", mock.Anything, mock.Anything).Return(scheduleWithEntries(), nil)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedClient := &clientMock{}
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockedClient)
+			}
+
+			pd := &pagerDutyClient{client: mockedClient, scheduleWindowDays: tt.windowDays}
+			got, err := pd.getSchedule(context.Background(), "SCHED1", startDate, endDate)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			mockedClient.AssertExpectations(t)
+			require.NoError(t, err)
+			assert.Equal(t, "Schedule 1", got.Name)
+			assert.Equal(t, tt.want, got.FinalSchedule.RenderedScheduleEntries)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+type overridePeriod struct {
+	start time.Time
+	end   time.Time
+}
+
+// getScheduleOverrides loads the overrides of the schedule for its time range.
+func (pd *pagerDutyClient) getScheduleOverrides(ctx context.Context, scheduleInfo *api.ScheduleInfo) error {
+	overrides, err := pd.client.ListOverrides(ctx, scheduleInfo.ID,
+		scheduleInfo.Start.Format(time.RFC3339), scheduleInfo.End.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to get overrides of schedule %s: %w", scheduleInfo.ID, err)
+	}
+
+	scheduleInfo.Overrides = overrides
+	return nil
+}
+
+// overridePeriodsByUser returns the overrides of each user, sorted by start.
+func overridePeriodsByUser(scheduleInfo *api.ScheduleInfo) (map[string][]overridePeriod, error) {
+	periods := make(map[string][]overridePeriod)
+	for _, override := range scheduleInfo.Overrides {
+		start, err := time.ParseInLocation(time.RFC3339, override.Start, scheduleInfo.Location)
+		if err != nil {
+			return nil, fmt.Errorf("override %s: %w", override.ID, err)
+		}
+		end, err := time.ParseInLocation(time.RFC3339, override.End, scheduleInfo.Location)
+		if err != nil {
+			return nil, fmt.Errorf("override %s: %w", override.ID, err)
+		}
+		periods[override.User.ID] = append(periods[override.User.ID], overridePeriod{start: start, end: end})
+	}
+
+	for _, userPeriods := range periods {
+		sort.Slice(userPeriods, func(i, j int) bool {
+			return userPeriods[i].start.Before(userPeriods[j].start)
+		})
+	}
+	return periods, nil
+}
+
+// splitByOverrides cuts an on-call period of the user at the boundaries of the user's overrides,
+// tagging the parts covered by an override.
+func splitByOverrides(scheduleInfo *api.ScheduleInfo, userID string, start, end time.Time, overrides []overridePeriod) ([]*api.UserRotaPeriod, error) {
+	periods := make([]*api.UserRotaPeriod, 0, 1)
+	current := start
+	for _, override := range overrides {
+		if !override.start.Before(end) || !current.Before(override.end) {
+			continue
+		}
+
+		overrideStart := override.start
+		if overrideStart.Before(current) {
+			overrideStart = current
+		}
+		overrideEnd := override.end
+		if overrideEnd.After(end) {
+			overrideEnd = end
+		}
+
+		if current.Before(overrideStart) {
+			periods = append(periods, &api.UserRotaPeriod{Start: current, End: overrideStart})
+		}
+		coveredFor, err := coveredUsers(scheduleInfo, userID, overrideStart, overrideEnd)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, &api.UserRotaPeriod{
+			Start:      overrideStart,
+			End:        overrideEnd,
+			Override:   true,
+			CoveredFor: coveredFor,
+		})
+		current = overrideEnd
+	}
+
+	if current.Before(end) {
+		periods = append(periods, &api.UserRotaPeriod{Start: current, End: end})
+	}
+	return periods, nil
+}
+
+// coveredUsers returns the names of the users the highest priority layer with someone on call
+// between start and end had on call, other than the given user.
+func coveredUsers(scheduleInfo *api.ScheduleInfo, userID string, start, end time.Time) ([]string, error) {
+	for _, layer := range scheduleInfo.Layers {
+		covered := false
+		names := make([]string, 0)
+		for _, entry := range layer.RenderedScheduleEntries {
+			entryStart, err := time.Parse(time.RFC3339, entry.Start)
+			if err != nil {
+				return nil, err
+			}
+			entryEnd, err := time.Parse(time.RFC3339, entry.End)
+			if err != nil {
+				return nil, err
+			}
+			if !entryStart.Before(end) || !start.Before(entryEnd) {
+				continue
+			}
+
+			covered = true
+			if entry.User.ID != userID && !contains(names, entry.User.Summary) {
+				names = append(names, entry.User.Summary)
+			}
+		}
+		if covered {
+			return names, nil
+		}
+	}
+
+	return []string{}, nil
+}
+
+// overrideHours is the wall-clock duration of an override period, excluded hours included, the same for the
+// users' override hours and the overrides section.
+func overrideHours(period *api.UserRotaPeriod) float32 {
+	return float32(period.End.Sub(period.Start).Hours())
+}
+
+// getOverrides lists the override periods of the schedule for the report.
+func getOverrides(scheduleInfo *api.ScheduleInfo, usersRotationData api.ScheduleUserRotationData) []*report.Override {
+	overrides := make([]*report.Override, 0)
+	for _, userRotaInfo := range usersRotationData {
+		for _, period := range userRotaInfo.Periods {
+			if !period.Override {
+				continue
+			}
+			overrides = append(overrides, &report.Override{
+				ScheduleID:   scheduleInfo.ID,
+				ScheduleName: scheduleInfo.Name,
+				UserName:     userRotaInfo.Name,
+				Start:        period.Start,
+				End:          period.End,
+				Hours:        overrideHours(period),
+				CoveredFor:   period.CoveredFor,
+			})
+		}
+	}
+
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Start.Before(overrides[j].Start)
+	})
+	return overrides
+}
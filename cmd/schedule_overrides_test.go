@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func namedEntry(userID, name, start, end string) api.RenderedScheduleEntry {
+	return api.RenderedScheduleEntry{Start: start, End: end, User: api.User{ID: userID, Summary: name}}
+}
+
+func Test_getUsersRotationData_overrides(t *testing.T) {
+	date := func(day, hour int) time.Time {
+		return time.Date(2026, 9, day, hour, 0, 0, 0, time.UTC)
+	}
+	layers := []api.ScheduleLayer{
+		{RenderedScheduleEntries: []api.RenderedScheduleEntry{
+			namedEntry("USER1", "John Doe", "2026-09-01T00:00:00Z", "2026-09-10T00:00:00Z"),
+		}},
+		{RenderedScheduleEntries: []api.RenderedScheduleEntry{
+			namedEntry("USER3", "Bruce Wayne", "2026-09-01T00:00:00Z", "2026-09-10T00:00:00Z"),
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		final     []api.RenderedScheduleEntry
+		overrides []*api.Override
+		want      map[string][]*api.UserRotaPeriod
+		wantErr   bool
+	}{
+		{
+			name: "Periods without overrides are regular",
+			final: []api.RenderedScheduleEntry{
+				namedEntry("USER1", "John Doe", "2026-09-01T00:00:00Z", "2026-09-10T00:00:00Z"),
+			},
+			want: map[string][]*api.UserRotaPeriod{
+				"USER1": {{Start: date(1, 0), End: date(10, 0)}},
+			},
+		},
+		{
+			name: "Override is tagged with the user of the highest priority layer",
+			final: []api.RenderedScheduleEntry{
+				namedEntry("USER1", "John Doe", "2026-09-01T00:00:00Z", "2026-09-05T00:00:00Z"),
+				namedEntry("USER2", "Mary Jane", "2026-09-05T00:00:00Z", "2026-09-06T12:00:00Z"),
+				namedEntry("USER1", "John Doe", "2026-09-06T12:00:00Z", "2026-09-10T00:00:00Z"),
+			},
+			overrides: []*api.Override{
+				{ID: "O1", Start: "2026-09-05T00:00:00Z", End: "2026-09-06T12:00:00Z", User: api.User{ID: "USER2"}},
+			},
+			want: map[string][]*api.UserRotaPeriod{
+				"USER1": {{Start: date(1, 0), End: date(5, 0)}, {Start: date(6, 12), End: date(10, 0)}},
+				"USER2": {{Start: date(5, 0), End: date(6, 12), Override: true, CoveredFor: []string{"John Doe"}}},
+			},
+		},
+		{
+			name: "Entries merged with an override are split at its boundaries",
+			final: []api.RenderedScheduleEntry{
+				namedEntry("USER2", "Mary Jane", "2026-09-08T00:00:00Z", "2026-09-12T00:00:00Z"),
+			},
+			overrides: []*api.Override{
+				{ID: "O1", Start: "2026-09-08T00:00:00Z", End: "2026-09-10T00:00:00Z", User: api.User{ID: "USER2"}},
+			},
+			want: map[string][]*api.UserRotaPeriod{
+				"USER2": {
+					{Start: date(8, 0), End: date(10, 0), Override: true, CoveredFor: []string{"John Doe"}},
+					{Start: date(10, 0), End: date(12, 0)},
+				},
+			},
+		},
+		{
+			name: "Override without any layer on call covers nobody",
+			final: []api.RenderedScheduleEntry{
+				namedEntry("USER2", "Mary Jane", "2026-09-20T00:00:00Z", "2026-09-21T00:00:00Z"),
+			},
+			overrides: []*api.Override{
+				{ID: "O1", Start: "2026-09-20T00:00:00Z", End: "2026-09-21T00:00:00Z", User: api.User{ID: "USER2"}},
+			},
+			want: map[string][]*api.UserRotaPeriod{
+				"USER2": {{Start: date(20, 0), End: date(21, 0), Override: true, CoveredFor: []string{}}},
+			},
+		},
+		{
+			name: "Invalid override dates fail",
+			final: []api.RenderedScheduleEntry{
+				namedEntry("USER2", "Mary Jane", "2026-09-20T00:00:00Z", "2026-09-21T00:00:00Z"),
+			},
+			overrides: []*api.Override{
+				{ID: "O1", Start: "20/09/2026", End: "2026-09-21T00:00:00Z", User: api.User{ID: "USER2"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduleInfo := &api.ScheduleInfo{
+				ID:            "SCHED1",
+				Location:      time.UTC,
+				FinalSchedule: api.ScheduleLayer{RenderedScheduleEntries: tt.final},
+				Layers:        layers,
+				Overrides:     tt.overrides,
+			}
+
+			got, err := getUsersRotationData(scheduleInfo)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, got, len(tt.want))
+			for userID, periods := range tt.want {
+				assert.Equal(t, periods, got[userID].Periods, userID)
+			}
+		})
+	}
+}
+
+func Test_generateScheduleData_overrideHours(t *testing.T) {
+	newTestConfig(t)
+	Config.RotationExcludedHours = []configuration.RotationExcludedHoursDay{{Day: "weekend", ExcludedStartsAt: 0, ExcludedEndsAt: 12}}
+	saturday := time.Date(2026, time.September, 19, 8, 0, 0, 0, time.UTC)
+	override := &api.UserRotaPeriod{Start: saturday, End: saturday.AddDate(0, 0, 2), Override: true, CoveredFor: []string{"John Doe"}}
+
+	users := generateTestScheduleUsers(t, api.ScheduleUserRotationData{
+		"USER2": {ID: "USER2", Name: "Mary Jane", Periods: []*api.UserRotaPeriod{override}},
+	})
+
+	// the paid hours leave out the excluded weekend mornings, the override hours don't
+	assert.InDelta(t, 40, users["Mary Jane"].NumWeekendHours, 0.0001)
+	assert.InDelta(t, 48, users["Mary Jane"].NumOverrideHours, 0.0001)
+	assert.Equal(t, []string{"John Doe"}, users["Mary Jane"].CoveredFor)
+	assert.InDelta(t, users["Mary Jane"].NumOverrideHours, overrideHours(override), 0.0001)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+// onCallTime is the time a user held in a schedule per rota day, which starts at the daily rotation hour, and the
+// hours counted per ISO week, to match the stipend patterns.
+type onCallTime struct {
+	location  *time.Location
+	rotaDays  map[string]time.Duration
+	weekHours map[string]float32
+	// weekStarts is the first date on call of each ISO week
+	weekStarts map[string]time.Time
+}
+
+func newOnCallTime() *onCallTime {
+	return &onCallTime{
+		rotaDays:   make(map[string]time.Duration),
+		weekHours:  make(map[string]float32),
+		weekStarts: make(map[string]time.Time),
+	}
+}
+
+// add adds a check of the rotation at the local date, lasting step, with the hours it counted.
+func (o *onCallTime) add(localDate time.Time, step time.Duration, hours float32) {
+	o.location = localDate.Location()
+	rotaDay := localDate
+	if rotaDay.Hour() < Config.RotationInfo.DailyRotationStartsAt {
+		rotaDay = rotaDay.AddDate(0, 0, -1)
+	}
+	o.rotaDays[rotaDay.Format("2006-01-02")] += step
+
+	year, week := localDate.ISOWeek()
+	key := fmt.Sprintf("%d-W%02d", year, week)
+	o.weekHours[key] += hours
+	if start, ok := o.weekStarts[key]; !ok || localDate.Before(start) {
+		o.weekStarts[key] = localDate
+	}
+}
+
+func (o *onCallTime) rotaDayStart(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), Config.RotationInfo.DailyRotationStartsAt, 0, 0, 0, o.location)
+}
+
+// held reports whether the whole rota day was on call.
+func (o *onCallTime) held(day time.Time) bool {
+	start := o.rotaDayStart(day)
+	return o.rotaDays[day.Format("2006-01-02")] >= start.AddDate(0, 0, 1).Sub(start)
+}
+
+// fullWeekends returns the first rota day of the weekends of the calendar held from their first to their last
+// rota day.
+func (o *onCallTime) fullWeekends(calendar *configuration.BHCalendar) []time.Time {
+	isWeekend := func(day time.Time) bool {
+		return calendar.IsWeekend(o.rotaDayStart(day))
+	}
+
+	weekends := make([]time.Time, 0)
+	for key := range o.rotaDays {
+		firstDay, err := time.ParseInLocation("2006-01-02", key, o.location)
+		if err != nil || !isWeekend(firstDay) || isWeekend(firstDay.AddDate(0, 0, -1)) {
+			continue
+		}
+
+		held := true
+		for day := firstDay; held && isWeekend(day); day = day.AddDate(0, 0, 1) {
+			held = o.held(day)
+		}
+		if held {
+			weekends = append(weekends, o.rotaDayStart(firstDay))
+		}
+	}
+	sortDates(weekends)
+	return weekends
+}
+
+// isoWeeks returns the first date on call of the ISO weeks with at least minHours on call.
+func (o *onCallTime) isoWeeks(minHours int) []time.Time {
+	weeks := make([]time.Time, 0)
+	for key, hours := range o.weekHours {
+		if hours > 0 && hours >= float32(minHours) {
+			weeks = append(weeks, o.weekStarts[key])
+		}
+	}
+	sortDates(weeks)
+	return weeks
+}
+
+func sortDates(dates []time.Time) {
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+}
+
+// priceStipends adds the stipends of the schedule for the shift patterns matched by the user's on-call time, each
+// paid on the first date of its occurrence.
+func priceStipends(scheduleUserData *report.ScheduleUser, scheduleID string, onCall *onCallTime, calendar *configuration.BHCalendar,
+	pay dailyPay) {
+
+	for _, stipend := range Config.Stipends {
+		if len(stipend.ScheduleIDs) > 0 && !contains(stipend.ScheduleIDs, scheduleID) {
+			continue
+		}
+
+		occurrences := onCall.isoWeeks(stipend.MinHours)
+		if stipend.Pattern == "fullWeekend" {
+			occurrences = onCall.fullWeekends(calendar)
+		}
+		for _, date := range occurrences {
+			pay.add(date, float32(stipend.Amount))
+		}
+		scheduleUserData.NumStipends += len(occurrences)
+		scheduleUserData.TotalAmountStipends += float32(len(occurrences) * stipend.Amount)
+	}
+	scheduleUserData.TotalAmount += scheduleUserData.TotalAmountStipends
+}
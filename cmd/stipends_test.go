@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOnCallTime(shifts ...[2]time.Time) *onCallTime {
+	step := 30 * time.Minute
+	onCall := newOnCallTime()
+	for _, shift := range shifts {
+		for date := shift[0]; date.Before(shift[1]); date = date.Add(step) {
+			onCall.add(date, step, 0.5)
+		}
+	}
+	return onCall
+}
+
+func Test_onCallTime_fullWeekends(t *testing.T) {
+	Config = &configuration.Configuration{RotationInfo: configuration.RotationInfo{DailyRotationStartsAt: 8}}
+	defer func() { Config = nil }()
+	saturday := time.Date(2026, time.September, 5, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		calendar configuration.BHCalendar
+		shifts   [][2]time.Time
+		want     int
+	}{
+		{
+			name:   "a weekend held from saturday to monday morning",
+			shifts: [][2]time.Time{{saturday, saturday.AddDate(0, 0, 2)}},
+			want:   1,
+		},
+		{
+			name:   "a weekend held within a weekly shift",
+			shifts: [][2]time.Time{{saturday.AddDate(0, 0, -5), saturday.AddDate(0, 0, 9)}},
+			want:   2,
+		},
+		{
+			name:   "a weekend missing its sunday evening",
+			shifts: [][2]time.Time{{saturday, saturday.AddDate(0, 0, 1).Add(12 * time.Hour)}},
+			want:   0,
+		},
+		{
+			name:   "a weekend split between shifts",
+			shifts: [][2]time.Time{{saturday, saturday.AddDate(0, 0, 1)}, {saturday.AddDate(0, 0, 1), saturday.AddDate(0, 0, 2)}},
+			want:   1,
+		},
+		{
+			name:     "the weekend days of the calendar",
+			calendar: configuration.BHCalendar{WeekendDays: []configuration.WeekendDay{{Day: "friday"}, {Day: "saturday"}}},
+			shifts:   [][2]time.Time{{saturday, saturday.AddDate(0, 0, 2)}},
+			want:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onCall := newTestOnCallTime(tt.shifts...)
+
+			assert.Len(t, onCall.fullWeekends(&tt.calendar), tt.want)
+		})
+	}
+}
+
+func Test_onCallTime_isoWeeks(t *testing.T) {
+	Config = &configuration.Configuration{RotationInfo: configuration.RotationInfo{DailyRotationStartsAt: 8}}
+	defer func() { Config = nil }()
+	monday := time.Date(2026, time.September, 7, 8, 0, 0, 0, time.UTC)
+	onCall := newTestOnCallTime(
+		// a weekly shift handed over on monday morning
+		[2]time.Time{monday, monday.AddDate(0, 0, 7)},
+		// a long weekend
+		[2]time.Time{monday.AddDate(0, 0, 11), monday.AddDate(0, 0, 14)},
+	)
+
+	// the weeks hold 160, 8 + 64 and 8 hours
+	// the weeks after the first start at midnight, the end of the rota day of the previous week
+	nextMidnight := monday.AddDate(0, 0, 7).Add(-8 * time.Hour)
+	assert.Equal(t, []time.Time{monday, nextMidnight, nextMidnight.AddDate(0, 0, 7)}, onCall.isoWeeks(0))
+	assert.Equal(t, []time.Time{monday, nextMidnight}, onCall.isoWeeks(72))
+	assert.Equal(t, []time.Time{monday}, onCall.isoWeeks(160))
+	assert.Empty(t, onCall.isoWeeks(168))
+}
+
+func Test_generateScheduleData_stipends(t *testing.T) {
+	newTestConfig(t)
+	Config.Stipends = []configuration.Stipend{
+		{Pattern: "fullWeekend", Amount: 10},
+		{Pattern: "isoWeek", MinHours: 100, Amount: 20},
+		{Pattern: "isoWeek", Amount: 1000, ScheduleIDs: []string{"SCHED2"}},
+	}
+	monday := time.Date(2026, time.September, 7, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		periods    []*api.UserRotaPeriod
+		wantNum    int
+		wantAmount float32
+	}{
+		{
+			name:       "a weekly shift holds a weekend and an ISO week",
+			periods:    []*api.UserRotaPeriod{{Start: monday, End: monday.AddDate(0, 0, 7)}},
+			wantNum:    2,
+			wantAmount: 30,
+		},
+		{
+			name:       "a weekly shift without its weekend",
+			periods:    []*api.UserRotaPeriod{{Start: monday, End: monday.AddDate(0, 0, 5)}},
+			wantNum:    1,
+			wantAmount: 20,
+		},
+		{
+			name:       "a weekend override",
+			periods:    []*api.UserRotaPeriod{{Start: monday.AddDate(0, 0, 5), End: monday.AddDate(0, 0, 7), Override: true}},
+			wantNum:    1,
+			wantAmount: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := generateTestScheduleUsers(t, api.ScheduleUserRotationData{
+				"USER1": {ID: "USER1", Name: "John Doe", Periods: tt.periods},
+			})
+
+			user := users["John Doe"]
+			assert.Equal(t, tt.wantNum, user.NumStipends)
+			assert.InDelta(t, tt.wantAmount, user.TotalAmountStipends, 0.0001)
+			assert.InDelta(t, hourlyTestAmount(user)+tt.wantAmount, user.TotalAmount, 0.0001)
+		})
+	}
+}
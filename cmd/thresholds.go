@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"sort"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+)
+
+// checkThresholds compares the reported costs of the schedules and teams against their budgets, and the
+// users' hours and pay across all the schedules against their soft caps. The monthly thresholds are
+// prorated to the months of the report range.
+func checkThresholds(printableData *report.PrintableData, rotations []*scheduleRotation) []*report.ThresholdWarning {
+	warnings := make([]*report.ThresholdWarning, 0)
+	months := rangeMonths(printableData.Start, printableData.End)
+
+	schedules, teams := scheduleAndTeamCosts(rotations)
+	for _, budget := range Config.Thresholds.Budgets {
+		kind, id, costs := "schedule", budget.ScheduleID, schedules
+		if budget.TeamID != "" {
+			kind, id, costs = "team", budget.TeamID, teams
+		}
+		for _, cost := range costs {
+			limit := float32(budget.MonthlyAmount) * months
+			if cost.ID == id && cost.TotalAmount > limit {
+				warnings = append(warnings, &report.ThresholdWarning{Kind: kind, ID: id, Name: cost.Name,
+					Threshold: "monthlyAmount", Limit: limit, Actual: cost.TotalAmount})
+			}
+		}
+	}
+
+	userIDs := userIDsByName(rotations)
+	users := make([]*report.ScheduleUser, len(printableData.UsersSchedulesSummary))
+	copy(users, printableData.UsersSchedulesSummary)
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	for _, user := range users {
+		userCap, found := Config.FindUserCap(userIDs[user.Name])
+		if !found {
+			continue
+		}
+
+		hours := user.NumWorkHours + user.NumWeekendHours + user.NumBankHolidaysHours
+		if limit := float32(userCap.MonthlyHours) * months; userCap.MonthlyHours > 0 && hours > limit {
+			warnings = append(warnings, &report.ThresholdWarning{Kind: "user", ID: userIDs[user.Name], Name: user.Name,
+				Threshold: "monthlyHours", Limit: limit, Actual: hours})
+		}
+		if limit := float32(userCap.MonthlyAmount) * months; userCap.MonthlyAmount > 0 && user.AdjustedTotalAmount() > limit {
+			warnings = append(warnings, &report.ThresholdWarning{Kind: "user", ID: userIDs[user.Name], Name: user.Name,
+				Threshold: "monthlyAmount", Limit: limit, Actual: user.AdjustedTotalAmount()})
+		}
+	}
+
+	return warnings
+}
+
+// rangeMonths is the number of months of the range, each calendar month counting for its fraction within
+// the range.
+func rangeMonths(start, end time.Time) float32 {
+	var months float64
+	monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	for ; monthStart.Before(end); monthStart = monthStart.AddDate(0, 1, 0) {
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		from, to := monthStart, monthEnd
+		if start.After(from) {
+			from = start
+		}
+		if end.Before(to) {
+			to = end
+		}
+		months += to.Sub(from).Hours() / monthEnd.Sub(monthStart).Hours()
+	}
+	return float32(months)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/api"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/report"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rangeMonths(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		want  float32
+	}{
+		{
+			name:  "a calendar month",
+			start: time.Date(2026, time.September, 1, 0, 0, 0, 0, london),
+			end:   time.Date(2026, time.October, 1, 0, 0, 0, 0, london),
+			want:  1,
+		},
+		{
+			name:  "half a month",
+			start: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2026, time.September, 16, 0, 0, 0, 0, time.UTC),
+			want:  0.5,
+		},
+		{
+			name:  "over the end of a year",
+			start: time.Date(2026, time.December, 16, 12, 0, 0, 0, time.UTC),
+			end:   time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC),
+			want:  2.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, rangeMonths(tt.start, tt.end), 0.0001)
+		})
+	}
+}
+
+func Test_checkThresholds(t *testing.T) {
+	rotations := []*scheduleRotation{
+		{
+			info: &api.ScheduleInfo{ID: "SCHED1", Name: "Payments primary", Teams: []api.Team{{ID: "TEAM1", Name: "Payments"}}},
+			users: api.ScheduleUserRotationData{
+				"USER1": {ID: "USER1", Name: "John Doe"},
+				"USER2": {ID: "USER2", Name: "Mary Jane"},
+			},
+			data: &report.ScheduleData{RotaUsers: []*report.ScheduleUser{
+				{Name: "John Doe", TotalAmount: 300},
+				{Name: "Mary Jane", TotalAmount: 600},
+			}},
+		},
+	}
+	printableData := &report.PrintableData{
+		Start: time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		UsersSchedulesSummary: []*report.ScheduleUser{
+			{Name: "Mary Jane", NumWorkHours: 400, NumWeekendHours: 200, TotalAmount: 600},
+			{Name: "John Doe", NumWorkHours: 300, NumWeekendHours: 100, NumBankHolidaysHours: 10, TotalAmount: 300},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		thresholds configuration.Thresholds
+		want       []*report.ThresholdWarning
+	}{
+		{
+			name:       "no thresholds",
+			thresholds: configuration.Thresholds{},
+			want:       []*report.ThresholdWarning{},
+		},
+		{
+			name: "budgets are prorated to the months of the range",
+			thresholds: configuration.Thresholds{Budgets: []configuration.Budget{
+				{TeamID: "TEAM1", MonthlyAmount: 400},
+				{ScheduleID: "SCHED1", MonthlyAmount: 500},
+				{ScheduleID: "SCHED2", MonthlyAmount: 1},
+			}},
+			want: []*report.ThresholdWarning{
+				{Kind: "team", ID: "TEAM1", Name: "Payments", Threshold: "monthlyAmount", Limit: 800, Actual: 900},
+			},
+		},
+		{
+			name: "users get their own cap or the default one",
+			thresholds: configuration.Thresholds{UserCaps: []configuration.UserCap{
+				{MonthlyHours: 200, MonthlyAmount: 200},
+				{UserID: "USER2", MonthlyAmount: 250},
+			}},
+			want: []*report.ThresholdWarning{
+				{Kind: "user", ID: "USER1", Name: "John Doe", Threshold: "monthlyHours", Limit: 400, Actual: 410},
+				{Kind: "user", ID: "USER2", Name: "Mary Jane", Threshold: "monthlyAmount", Limit: 500, Actual: 600},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Config = &configuration.Configuration{Thresholds: tt.thresholds}
+			defer func() { Config = nil }()
+
+			assert.Equal(t, tt.want, checkThresholds(printableData, rotations))
+		})
+	}
+}
+
+func Test_pagerDutyClient_generateReport_failOnThreshold(t *testing.T) {
+	pd := newFakeServerReport(t)
+	Config.Thresholds = configuration.Thresholds{
+		Budgets:  []configuration.Budget{{TeamID: "TEAM1", MonthlyAmount: 20}},
+		UserCaps: []configuration.UserCap{{MonthlyHours: 350}},
+	}
+	failOnThreshold = true
+	t.Cleanup(func() {
+		failOnThreshold = false
+	})
+
+	err := pd.generateReport(context.Background())
+	require.EqualError(t, err, "found 2 exceeded threshold(s)")
+
+	warnings, err := os.ReadFile(filepath.Join(directory, "pagerduty_oncall_report.9-2026-Warnings.csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(warnings), "team,TEAM1,Payments,monthlyAmount,20.00,")
+	assert.Contains(t, string(warnings), "user,USER2,Mary Jane,monthlyHours,350.00,384.00\n")
+	assert.NotContains(t, string(warnings), "John Doe")
+}
@@ -1,7 +1,9 @@
 package configuration
 
 import (
+	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"os"
@@ -38,31 +40,105 @@ func (d *Day) ToHashKey() string {
 	return d.Time.Format("02/01/2006")
 }
 
+type HourMinute struct {
+	Time *time.Time
+}
+
+func (h *HourMinute) UnmarshalYAML(unmarshal func(interface{}) error) error {
+
+	var timeStr string
+	if err := unmarshal(&timeStr); err != nil {
+		return err
+	}
+
+	// Parse the string to produce a proper time.Time struct.
+	pt, err := time.Parse("15:04", timeStr)
+	if err != nil {
+		return err
+	}
+	h.Time = &pt
+	return nil
+}
+
+func (h *HourMinute) minutesOfDay() int {
+	return h.Time.Hour()*60 + h.Time.Minute()
+}
+
 type BankHoliday struct {
-	Name string `yaml:"title,omitempty"`
-	Date Day    `yaml:"date,omitempty"`
+	Name  string     `yaml:"title,omitempty"`
+	Date  Day        `yaml:"date,omitempty"`
+	Start HourMinute `yaml:"start,omitempty"` // optional, the holiday starts at this time of the day
+	End   HourMinute `yaml:"end,omitempty"`   // optional, the holiday ends at this time of the day
+}
+
+// Covers reports whether the time of the day of the given date is within the holiday hours.
+// Holidays without start/end times cover the whole day.
+func (bh *BankHoliday) Covers(date time.Time) bool {
+	minutes := date.Hour()*60 + date.Minute()
+	if bh.Start.Time != nil && minutes < bh.Start.minutesOfDay() {
+		return false
+	}
+	if bh.End.Time != nil && minutes >= bh.End.minutesOfDay() {
+		return false
+	}
+	return true
+}
+
+type WeekendDay struct {
+	Day      string
+	StartsAt int
+	EndsAt   int
+}
+
+// DefaultWeekendDays is used for calendars and users without an explicit weekend definition.
+var DefaultWeekendDays = []WeekendDay{
+	{Day: "saturday"},
+	{Day: "sunday"},
+}
+
+// Covers reports whether the given date falls on this weekend day, honouring the optional
+// hour range (e.g. a half-day saturday starting at 12).
+func (w WeekendDay) Covers(date time.Time) bool {
+	if !strings.EqualFold(w.Day, date.Weekday().String()) {
+		return false
+	}
+
+	endsAt := w.EndsAt
+	if endsAt == 0 {
+		endsAt = 24
+	}
+	return date.Hour() >= w.StartsAt && date.Hour() < endsAt
 }
 
 type BHCalendar struct {
-	DaysMaps map[string]BankHoliday // map[date 02-01-2006 format]
+	DaysMaps    map[string]BankHoliday // map[date 02-01-2006 format]
+	WeekendDays []WeekendDay
 }
 
 func (b *BHCalendar) IsDateBankHoliday(date time.Time) bool {
-	_, present := b.DaysMaps[date.Format("02/01/2006")]
-	return present
+	bankHoliday, present := b.DaysMaps[date.Format("02/01/2006")]
+	return present && bankHoliday.Covers(date)
 }
 
 func (b *BHCalendar) IsWeekend(date time.Time) bool {
-	return date.Weekday() == 6 || date.Weekday() == 0
+	weekendDays := b.WeekendDays
+	if len(weekendDays) == 0 {
+		weekendDays = DefaultWeekendDays
+	}
+
+	for _, weekendDay := range weekendDays {
+		if weekendDay.Covers(date) {
+			return true
+		}
+	}
+	return false
 }
 
 type BHCalendars map[string]BHCalendar // map[calendar_name-year]
 
 var BankHolidaysCalendars BHCalendars
 
-func LoadCalendars(year int) {
-	log.Printf("Loading calendars for year: %d", year)
-
+func findCalendarsBox() (*rice.Box, error) {
 	riceConf := rice.Config{
 		LocateOrder: []rice.LocateMethod{
 			rice.LocateEmbedded,
@@ -71,7 +147,47 @@ func LoadCalendars(year int) {
 			rice.LocateWorkingDirectory,
 		},
 	}
-	calendarsLocation, err := riceConf.FindBox("./../_assets")
+	return riceConf.FindBox("./../_assets")
+}
+
+// AvailableCalendars returns the names of the bundled holidays calendars, for any year.
+func AvailableCalendars() ([]string, error) {
+	calendarsLocation, err := findCalendarsBox()
+	if err != nil {
+		return nil, fmt.Errorf("cannot find box '_assets': %w", err)
+	}
+
+	names := make(map[string]bool)
+	err = calendarsLocation.Walk("calendars", func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if f.IsDir() {
+			return nil
+		}
+
+		split := strings.Split(f.Name(), ".")
+		names[split[1]] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error going through calendars directory: %w", err)
+	}
+
+	calendars := make([]string, 0, len(names))
+	for name := range names {
+		calendars = append(calendars, name)
+	}
+	sort.Strings(calendars)
+	return calendars, nil
+}
+
+// LoadCalendars loads the bank holidays calendars of the given years, replacing the loaded ones.
+func LoadCalendars(years ...int) {
+	log.Printf("Loading calendars for years: %v", years)
+
+	calendarsLocation, err := findCalendarsBox()
 	if err != nil {
 		log.Fatalf("cannot find box '_assets': %s", err.Error())
 	}
@@ -87,7 +203,7 @@ func LoadCalendars(year int) {
 		}
 
 		split := strings.Split(f.Name(), ".")
-		if parsedYear, _ := strconv.Atoi(split[2]); parsedYear != year {
+		if parsedYear, _ := strconv.Atoi(split[2]); !containsYear(years, parsedYear) {
 			return nil
 		}
 
@@ -120,3 +236,12 @@ func LoadCalendars(year int) {
 		log.Fatalf("error going through calendars directory: %s", err.Error())
 	}
 }
+
+func containsYear(years []int, year int) bool {
+	for _, y := range years {
+		if y == year {
+			return true
+		}
+	}
+	return false
+}
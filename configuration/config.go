@@ -3,12 +3,70 @@ package configuration
 import (
 	"fmt"
 	"log"
+	"reflect"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
 )
 
 type RotationUser struct {
 	UserID           string
 	Name             string
 	HolidaysCalendar string
+	WeekendDays      []WeekendDay
+	LeaveFile        string
+
+	InferredFromTimezone string `mapstructure:"-"` // set when the calendar was inferred from the user's timezone
+}
+
+type TimezoneCalendar struct {
+	Timezone string
+	Calendar string
+}
+
+// TimezoneCalendarGuesses maps common timezones to the bundled holidays calendars, used to
+// bootstrap a configuration. Regional calendars can't be guessed from a timezone.
+var TimezoneCalendarGuesses = []TimezoneCalendar{
+	{Timezone: "Europe/London", Calendar: "uk"},
+	{Timezone: "Europe/Belfast", Calendar: "uk_nir"},
+	{Timezone: "Europe/Dublin", Calendar: "ie"},
+	{Timezone: "Europe/Madrid", Calendar: "sp"},
+	{Timezone: "Atlantic/Canary", Calendar: "sp_laspalmas"},
+	{Timezone: "Europe/Lisbon", Calendar: "pt"},
+	{Timezone: "Europe/Paris", Calendar: "fr"},
+	{Timezone: "Europe/Berlin", Calendar: "de"},
+	{Timezone: "Europe/Vienna", Calendar: "at"},
+	{Timezone: "Europe/Amsterdam", Calendar: "nl"},
+	{Timezone: "Europe/Brussels", Calendar: "be"},
+	{Timezone: "Europe/Prague", Calendar: "cz"},
+	{Timezone: "Europe/Bratislava", Calendar: "sk"},
+	{Timezone: "Europe/Warsaw", Calendar: "pl"},
+	{Timezone: "Europe/Bucharest", Calendar: "ro"},
+	{Timezone: "Europe/Sofia", Calendar: "bg"},
+	{Timezone: "Europe/Athens", Calendar: "gr"},
+	{Timezone: "Europe/Ljubljana", Calendar: "si"},
+	{Timezone: "Europe/Tallinn", Calendar: "ee"},
+	{Timezone: "America/Toronto", Calendar: "ca"},
+	{Timezone: "America/Argentina/Buenos_Aires", Calendar: "ar"},
+	{Timezone: "America/Buenos_Aires", Calendar: "ar"},
+	{Timezone: "Australia/Sydney", Calendar: "au_nsw"},
+	{Timezone: "Australia/Melbourne", Calendar: "au_vic"},
+	{Timezone: "Australia/Perth", Calendar: "au_wa"},
+}
+
+// GuessCalendarByTimezone returns the calendar for the timezone, or an empty string when unknown.
+func GuessCalendarByTimezone(timezone string) string {
+	for _, timezoneCalendar := range TimezoneCalendarGuesses {
+		if timezoneCalendar.Timezone == timezone {
+			return timezoneCalendar.Calendar
+		}
+	}
+	return ""
+}
+
+type CalendarWeekend struct {
+	Calendar    string
+	WeekendDays []WeekendDay
 }
 
 type RotationPriceDay struct {
@@ -21,6 +79,83 @@ type RotationPrices struct {
 	DaysInfo []RotationPriceDay
 }
 
+// CalloutPrice is paid for each incident paging the on-call user on the given day type. Prices with an
+// urgency (high or low) only apply to incidents of that urgency and take precedence over the ones without.
+type CalloutPrice struct {
+	Day     string
+	Urgency string
+	Price   int
+}
+
+// ActiveRate is the hourly price of the time engaged in incidents on the given day type.
+type ActiveRate struct {
+	Day   string
+	Price int
+}
+
+// ComplianceRules are the working-time limits checked against each user's on-call periods across all the
+// schedules, a zero value disables the rule.
+type ComplianceRules struct {
+	MaxConsecutiveHours int
+	MinRestHours        int
+	MaxDaysPer7Days     int
+	MaxDaysPer30Days    int
+}
+
+// Thresholds are the monthly limits the reported actuals are compared against, exceeding them raises a warning.
+type Thresholds struct {
+	Budgets  []Budget
+	UserCaps []UserCap
+}
+
+// Budget is the monthly on-call budget of either a schedule or a team.
+type Budget struct {
+	ScheduleID    string
+	TeamID        string
+	MonthlyAmount int
+}
+
+// UserCap is a soft monthly cap on a user's on-call hours and pay, a zero value disables the cap. Without a
+// user id it applies to the users without a cap of their own.
+type UserCap struct {
+	UserID        string
+	MonthlyHours  int
+	MonthlyAmount int
+}
+
+// PayAdjustments are the pay policy applied over the raw on-call pay: minimums guaranteed within each schedule
+// and caps on each user's pay across all the schedules.
+type PayAdjustments struct {
+	Minimums []PayMinimum
+	Caps     []PayCap
+}
+
+// PayMinimum guarantees Amount per shift (an uninterrupted on-call time of at least MinHours) or per day (of the
+// user's timezone, with at least MinHours on call).
+type PayMinimum struct {
+	Per      string
+	MinHours int
+	Amount   int
+}
+
+// PayCap is the maximum pay of a user per week or month. Without a user id it applies to the users without a cap
+// of their own for the period.
+type PayCap struct {
+	UserID    string
+	Period    string
+	MaxAmount int
+}
+
+// Stipend is a flat amount paid, in addition to the hourly prices, for each occurrence of a shift pattern in the
+// given schedules, or in all of them: a fullWeekend held from its first to its last rota day, or an isoWeek with
+// at least MinHours on call.
+type Stipend struct {
+	Pattern     string
+	MinHours    int
+	Amount      int
+	ScheduleIDs []string
+}
+
 type RotationExcludedHoursDay struct {
 	Day              string
 	ExcludedStartsAt int
@@ -43,18 +178,83 @@ type ScheduleTimeRange struct {
 	End   string
 }
 
+// reportTimeLayouts are the accepted formats of reportTimeRange and scheduleTimeRangeOverrides dates.
+var reportTimeLayouts = []string{
+	time.RFC822,
+	time.RFC822Z,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+}
+
+// ParseTime parses the dates used by reportTimeRange and scheduleTimeRangeOverrides. Dates without
+// a timezone, such as plain days (2006-01-02) or months (2006-01), are interpreted in the given location.
+func ParseTime(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range reportTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse '%s', expected RFC822 (02 Jan 06 15:04 MST), "+
+		"RFC3339 (2006-01-02T15:04:05Z07:00), a day (2006-01-02) or a month (2006-01)", value)
+}
+
+// TimeToStringHookFunc keeps dates decoded by the yaml parser, such as an unquoted 2006-01-02,
+// as strings so they can be parsed with ParseTime.
+func TimeToStringHookFunc() mapstructure.DecodeHookFunc {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		date, ok := data.(time.Time)
+		if !ok || to.Kind() != reflect.String {
+			return data, nil
+		}
+
+		if date.Equal(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)) {
+			return date.Format("2006-01-02"), nil
+		}
+		return date.Format(time.RFC3339), nil
+	}
+}
+
+// PdAPI configures how the PagerDuty API is reached.
+type PdAPI struct {
+	BaseURL   string
+	Proxy     string
+	CABundle  string
+	Timeout   time.Duration
+	UserAgent string
+
+	// Long schedules are fetched in windows of ScheduleWindowDays, ScheduleConcurrency at a time
+	ScheduleWindowDays  int
+	ScheduleConcurrency int
+}
+
 type Configuration struct {
-	PdAuthToken string `mapstructure:"PD_AUTH_TOKEN"` // loads from env variable
+	PdAuthToken        string `mapstructure:"PD_AUTH_TOKEN"` // loads from env variable
+	PdAuthTokenFile    string
+	PdAuthTokenCommand string
+	PdAPI              PdAPI
 
+	ActiveRates                []ActiveRate
+	CalendarWeekends           []CalendarWeekend
+	CalloutPrices              []CalloutPrice
+	Compliance                 ComplianceRules
 	DefaultHolidayCalendar     string
 	DefaultUserTimezone        string
+	PayAdjustments             PayAdjustments
 	ReportTimeRange            ReportTimeRange
+	ReportTimezone             string
 	RotationInfo               RotationInfo
 	RotationExcludedHours      []RotationExcludedHoursDay
 	RotationPrices             RotationPrices
 	RotationUsers              []RotationUser
 	ScheduleTimeRangeOverrides []ScheduleTimeRange
 	SchedulesToIgnore          []string
+	Stipends                   []Stipend
+	Thresholds                 Thresholds
+	TimezoneCalendars          []TimezoneCalendar
 
 	cacheRotationUsers  map[string]*RotationUser
 	cacheRotationPrices map[string]int
@@ -84,6 +284,66 @@ func (c *Configuration) FindPriceByDay(dayType string) (*int, error) {
 	return nil, fmt.Errorf("day type %s not found", dayType)
 }
 
+// FindActiveRate returns the hourly price of the time engaged in incidents on the day type, false
+// when there is none.
+func (c *Configuration) FindActiveRate(dayType string) (int, bool) {
+	for _, activeRate := range c.ActiveRates {
+		if activeRate.Day == dayType {
+			return activeRate.Price, true
+		}
+	}
+	return 0, false
+}
+
+// FindUserCap returns the soft cap of the user, or the default one, false when there is none.
+func (c *Configuration) FindUserCap(userID string) (*UserCap, bool) {
+	var defaultCap *UserCap
+	for i, userCap := range c.Thresholds.UserCaps {
+		if userCap.UserID == userID {
+			return &c.Thresholds.UserCaps[i], true
+		}
+		if userCap.UserID == "" {
+			defaultCap = &c.Thresholds.UserCaps[i]
+		}
+	}
+	return defaultCap, defaultCap != nil
+}
+
+// FindPayCap returns the user's pay cap for the period, or the default one, false when there is none.
+func (c *Configuration) FindPayCap(userID, period string) (*PayCap, bool) {
+	var defaultCap *PayCap
+	for i, payCap := range c.PayAdjustments.Caps {
+		if payCap.Period != period {
+			continue
+		}
+		if payCap.UserID == userID {
+			return &c.PayAdjustments.Caps[i], true
+		}
+		if payCap.UserID == "" {
+			defaultCap = &c.PayAdjustments.Caps[i]
+		}
+	}
+	return defaultCap, defaultCap != nil
+}
+
+// FindCalloutPrice returns the price of a callout on the day type for an incident of the given urgency,
+// false when no callout price applies.
+func (c *Configuration) FindCalloutPrice(dayType, urgency string) (int, bool) {
+	price, found := 0, false
+	for _, calloutPrice := range c.CalloutPrices {
+		if calloutPrice.Day != dayType {
+			continue
+		}
+		if calloutPrice.Urgency == urgency {
+			return calloutPrice.Price, true
+		}
+		if calloutPrice.Urgency == "" {
+			price, found = calloutPrice.Price, true
+		}
+	}
+	return price, found
+}
+
 func (c *Configuration) FindRotationExcludedHoursByDay(dayType string) *RotationExcludedHoursDay {
 	if excludedInfo, ok := c.cacheExcludedByDay[dayType]; ok {
 		return excludedInfo
@@ -100,18 +360,65 @@ func (c *Configuration) FindRotationExcludedHoursByDay(dayType string) *Rotation
 }
 
 func (c *Configuration) FindRotationUserInfoByID(userID string) (*RotationUser, error) {
-	if rotationUser, ok := c.cacheRotationUsers[userID]; ok {
+	if rotationUser := c.findConfiguredRotationUser(userID); rotationUser != nil {
+		return rotationUser, nil
+	}
+
+	return c.defaultRotationUser(userID)
+}
+
+// FindRotationUserInfoByIDAndTimezone behaves like FindRotationUserInfoByID, but users without a
+// config entry get the calendar mapped to their timezone in timezoneCalendars, when there is one.
+func (c *Configuration) FindRotationUserInfoByIDAndTimezone(userID, timezone string) (*RotationUser, error) {
+	if rotationUser := c.findConfiguredRotationUser(userID); rotationUser != nil {
 		return rotationUser, nil
 	}
 
+	for _, timezoneCalendar := range c.TimezoneCalendars {
+		if timezoneCalendar.Timezone == timezone {
+			rotationUser := &RotationUser{
+				UserID:               userID,
+				HolidaysCalendar:     timezoneCalendar.Calendar,
+				InferredFromTimezone: timezone,
+			}
+
+			c.cacheRotationUsers[userID] = rotationUser
+			log.Printf("inferred calendar %s for user with id: %s from timezone %s\n", timezoneCalendar.Calendar, userID, timezone)
+
+			return rotationUser, nil
+		}
+	}
+
+	return c.defaultRotationUser(userID)
+}
+
+func (c *Configuration) findConfiguredRotationUser(userID string) *RotationUser {
+	if rotationUser, ok := c.cacheRotationUsers[userID]; ok {
+		return rotationUser
+	}
+
 	for _, rotationUser := range c.RotationUsers {
 		if rotationUser.UserID == userID {
 			c.cacheRotationUsers[userID] = &rotationUser
-			return &rotationUser, nil
+			return &rotationUser
 		}
 	}
+
+	return nil
+}
+
+// UserNotFoundError is returned for users without a config entry nor a calendar to fall back to.
+type UserNotFoundError struct {
+	UserID string
+}
+
+func (e *UserNotFoundError) Error() string {
+	return fmt.Sprintf("user id %s not found", e.UserID)
+}
+
+func (c *Configuration) defaultRotationUser(userID string) (*RotationUser, error) {
 	if c.DefaultHolidayCalendar == "" { // if you dont specify a default calendar fallback to old behaviour
-		return nil, fmt.Errorf("user id %s not found", userID)
+		return nil, &UserNotFoundError{UserID: userID}
 	}
 
 	rotationUser := &RotationUser{
@@ -125,6 +432,44 @@ func (c *Configuration) FindRotationUserInfoByID(userID string) (*RotationUser,
 	return rotationUser, nil
 }
 
+// ReportLocation is the location used for report dates without a timezone, UTC by default.
+func (c *Configuration) ReportLocation() (*time.Location, error) {
+	if c.ReportTimezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(c.ReportTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("reportTimezone: invalid timezone '%s'", c.ReportTimezone)
+	}
+	return location, nil
+}
+
+// ParseReportTime parses a reportTimeRange or scheduleTimeRangeOverrides date in the report location.
+func (c *Configuration) ParseReportTime(value string) (time.Time, error) {
+	location, err := c.ReportLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ParseTime(value, location)
+}
+
+// FindWeekendDays returns the weekend definition for the given user: the user's own one if set,
+// otherwise the one configured for the user's holidays calendar. Nil means the default weekend.
+func (c *Configuration) FindWeekendDays(rotationUser *RotationUser) []WeekendDay {
+	if len(rotationUser.WeekendDays) > 0 {
+		return rotationUser.WeekendDays
+	}
+
+	for _, calendarWeekend := range c.CalendarWeekends {
+		if calendarWeekend.Calendar == rotationUser.HolidaysCalendar {
+			return calendarWeekend.WeekendDays
+		}
+	}
+
+	return nil
+}
+
 func (c *Configuration) IsScheduleIDToIgnore(scheduleID string) bool {
 	for _, scheduleIDToIgnore := range c.SchedulesToIgnore {
 		if scheduleIDToIgnore == scheduleID {
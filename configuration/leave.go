@@ -0,0 +1,192 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Leave is an absence period of a user, End is exclusive.
+type Leave struct {
+	Title string
+	Start time.Time
+	End   time.Time
+}
+
+func (l *Leave) Overlaps(start, end time.Time) bool {
+	return start.Before(l.End) && l.Start.Before(end)
+}
+
+type leaveDays struct {
+	Title string `yaml:"title,omitempty"`
+	Start Day    `yaml:"start,omitempty"`
+	End   Day    `yaml:"end,omitempty"`
+}
+
+// LoadLeaveFile reads a user's leave file. The format is chosen by the file extension:
+// yml/yaml and csv files list whole days (02/01/2006, both ends inclusive), ics files list events.
+// Dates without an explicit timezone are interpreted in the given location.
+func LoadLeaveFile(path string, location *time.Location) ([]Leave, error) {
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leave file: %w", err)
+	}
+
+	var leaves []Leave
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		leaves, err = parseYAMLLeave(fileBytes, location)
+	case ".csv":
+		leaves, err = parseCSVLeave(fileBytes, location)
+	case ".ics":
+		leaves, err = parseICSLeave(fileBytes, location)
+	default:
+		return nil, fmt.Errorf("leave file '%s' has an unsupported format, use yml, csv or ics", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse leave file '%s': %w", path, err)
+	}
+
+	return leaves, nil
+}
+
+func parseYAMLLeave(content []byte, location *time.Location) ([]Leave, error) {
+	var entries []leaveDays
+	if err := yaml.Unmarshal(content, &entries); err != nil {
+		return nil, err
+	}
+
+	leaves := make([]Leave, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Start.Time == nil {
+			return nil, fmt.Errorf("leave '%s' has no start date", entry.Title)
+		}
+		end := entry.End.Time
+		if end == nil {
+			end = entry.Start.Time
+		}
+		leaves = append(leaves, Leave{
+			Title: entry.Title,
+			Start: inLocation(*entry.Start.Time, location),
+			End:   inLocation(*end, location).AddDate(0, 0, 1),
+		})
+	}
+
+	return leaves, nil
+}
+
+func parseCSVLeave(content []byte, location *time.Location) ([]Leave, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := make([]Leave, 0, len(records))
+	for i, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %d: expected at least start and end dates", i+1)
+		}
+		start, err := time.ParseInLocation("02/01/2006", strings.TrimSpace(record[0]), location)
+		if err != nil {
+			if i == 0 { // header line
+				continue
+			}
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		end, err := time.ParseInLocation("02/01/2006", strings.TrimSpace(record[1]), location)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		leave := Leave{
+			Start: start,
+			End:   end.AddDate(0, 0, 1),
+		}
+		if len(record) > 2 {
+			leave.Title = strings.TrimSpace(record[2])
+		}
+		leaves = append(leaves, leave)
+	}
+
+	return leaves, nil
+}
+
+func parseICSLeave(content []byte, location *time.Location) ([]Leave, error) {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	// unfold long content lines
+	text = strings.ReplaceAll(text, "\n ", "")
+	text = strings.ReplaceAll(text, "\n\t", "")
+
+	leaves := make([]Leave, 0)
+	var current *Leave
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "BEGIN:VEVENT":
+			current = &Leave{}
+		case line == "END:VEVENT":
+			if current == nil || current.Start.IsZero() {
+				return nil, fmt.Errorf("event without DTSTART")
+			}
+			if current.End.IsZero() {
+				current.End = current.Start.AddDate(0, 0, 1)
+			}
+			leaves = append(leaves, *current)
+			current = nil
+		case current != nil:
+			property, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			params := strings.Split(property, ";")
+
+			var err error
+			switch params[0] {
+			case "DTSTART":
+				current.Start, err = parseICSDate(value, params[1:], location)
+			case "DTEND":
+				current.End, err = parseICSDate(value, params[1:], location)
+			case "SUMMARY":
+				current.Title = value
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return leaves, nil
+}
+
+func parseICSDate(value string, params []string, location *time.Location) (time.Time, error) {
+	for _, param := range params {
+		if strings.HasPrefix(param, "TZID=") {
+			tzLocation, err := time.LoadLocation(strings.TrimPrefix(param, "TZID="))
+			if err != nil {
+				return time.Time{}, err
+			}
+			location = tzLocation
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	case len(value) == len("20060102"):
+		return time.ParseInLocation("20060102", value, location)
+	default:
+		return time.ParseInLocation("20060102T150405", value, location)
+	}
+}
+
+func inLocation(date time.Time, location *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
+}
@@ -0,0 +1,63 @@
+package configuration
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// AuthToken returns the PagerDuty token, taken from PD_AUTH_TOKEN, pdAuthTokenFile or the output
+// of pdAuthTokenCommand, in that order.
+func (c *Configuration) AuthToken() (string, error) {
+	switch {
+	case c.PdAuthToken != "":
+		return c.PdAuthToken, nil
+	case c.PdAuthTokenFile != "":
+		return ReadAuthTokenFile(c.PdAuthTokenFile)
+	case c.PdAuthTokenCommand != "":
+		return runAuthTokenCommand(c.PdAuthTokenCommand)
+	}
+
+	return "", fmt.Errorf("no PagerDuty token found, set PD_AUTH_TOKEN, pdAuthTokenFile or pdAuthTokenCommand")
+}
+
+// ReadAuthTokenFile reads the PagerDuty token from a file, surrounding whitespace is ignored.
+func ReadAuthTokenFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("token file '%s' is empty", path)
+	}
+	return token, nil
+}
+
+// runAuthTokenCommand runs the command with the system shell and takes the token from its output.
+// The output is never included in errors.
+func runAuthTokenCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("token command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("token command returned an empty token")
+	}
+	return token, nil
+}
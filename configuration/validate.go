@@ -0,0 +1,453 @@
+package configuration
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	dayTypes        = []string{"weekday", "weekend", "bankholiday"}
+	urgencies       = []string{"high", "low"}
+	minimumPers     = []string{"shift", "day"}
+	payCapPeriods   = []string{"week", "month"}
+	stipendPatterns = []string{"fullWeekend", "isoWeek"}
+)
+
+// ValidationError is a configuration problem, Field is the path of the offending yaml field.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+type validator struct {
+	calendars map[string]bool
+	problems  []ValidationError
+}
+
+func (v *validator) add(field, format string, args ...interface{}) {
+	v.problems = append(v.problems, ValidationError{
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Validate checks the whole configuration and returns every problem found, calendars is the
+// list of the known holidays calendar names.
+func (c *Configuration) Validate(calendars []string) []ValidationError {
+	v := &validator{
+		calendars: make(map[string]bool),
+	}
+	for _, calendar := range calendars {
+		v.calendars[calendar] = true
+	}
+
+	c.validatePrices(v)
+	c.validateCalloutPrices(v)
+	c.validateActiveRates(v)
+	c.validateCompliance(v)
+	c.validateThresholds(v)
+	c.validatePayAdjustments(v)
+	c.validateStipends(v)
+	c.validateExcludedHours(v)
+	c.validateRotationInfo(v)
+	c.validateTimeRanges(v)
+	c.validateUsers(v)
+	c.validateCalendars(v)
+	c.validateTimezones(v)
+	c.validateAuthToken(v)
+	c.validatePdAPI(v)
+
+	return v.problems
+}
+
+func (c *Configuration) validatePrices(v *validator) {
+	prices := make(map[string]bool)
+	for i, daysInfo := range c.RotationPrices.DaysInfo {
+		field := fmt.Sprintf("rotationPrices.daysInfo[%d]", i)
+		if !isDayType(daysInfo.Day) {
+			v.add(field+".day", "unknown day type '%s', expected one of %s", daysInfo.Day, strings.Join(dayTypes, ", "))
+		}
+		if prices[daysInfo.Day] {
+			v.add(field+".day", "duplicate price for day type '%s'", daysInfo.Day)
+		}
+		if daysInfo.Price < 0 {
+			v.add(field+".price", "price %d is negative", daysInfo.Price)
+		}
+		prices[daysInfo.Day] = true
+	}
+
+	for _, dayType := range dayTypes {
+		if !prices[dayType] {
+			v.add("rotationPrices.daysInfo", "missing price for day type '%s'", dayType)
+		}
+	}
+}
+
+func (c *Configuration) validateCalloutPrices(v *validator) {
+	prices := make(map[string]int)
+	for i, calloutPrice := range c.CalloutPrices {
+		field := fmt.Sprintf("calloutPrices[%d]", i)
+		if !isDayType(calloutPrice.Day) {
+			v.add(field+".day", "unknown day type '%s', expected one of %s", calloutPrice.Day, strings.Join(dayTypes, ", "))
+		}
+		if calloutPrice.Urgency != "" && calloutPrice.Urgency != urgencies[0] && calloutPrice.Urgency != urgencies[1] {
+			v.add(field+".urgency", "unknown urgency '%s', expected one of %s", calloutPrice.Urgency, strings.Join(urgencies, ", "))
+		}
+		if calloutPrice.Price < 0 {
+			v.add(field+".price", "price %d is negative", calloutPrice.Price)
+		}
+
+		key := calloutPrice.Day + "/" + calloutPrice.Urgency
+		if previous, ok := prices[key]; ok {
+			v.add(field, "duplicate callout price, already set by calloutPrices[%d]", previous)
+		} else {
+			prices[key] = i
+		}
+	}
+}
+
+func (c *Configuration) validateActiveRates(v *validator) {
+	rates := make(map[string]int)
+	for i, activeRate := range c.ActiveRates {
+		field := fmt.Sprintf("activeRates[%d]", i)
+		if !isDayType(activeRate.Day) {
+			v.add(field+".day", "unknown day type '%s', expected one of %s", activeRate.Day, strings.Join(dayTypes, ", "))
+		}
+		if activeRate.Price < 0 {
+			v.add(field+".price", "price %d is negative", activeRate.Price)
+		}
+		if previous, ok := rates[activeRate.Day]; ok {
+			v.add(field+".day", "duplicate active rate for day type '%s', already set by activeRates[%d]", activeRate.Day, previous)
+		} else {
+			rates[activeRate.Day] = i
+		}
+	}
+}
+
+func (c *Configuration) validateCompliance(v *validator) {
+	rules := []struct {
+		field string
+		value int
+		max   int
+	}{
+		{field: "maxConsecutiveHours", value: c.Compliance.MaxConsecutiveHours},
+		{field: "minRestHours", value: c.Compliance.MinRestHours},
+		{field: "maxDaysPer7Days", value: c.Compliance.MaxDaysPer7Days, max: 7},
+		{field: "maxDaysPer30Days", value: c.Compliance.MaxDaysPer30Days, max: 30},
+	}
+	for _, rule := range rules {
+		if rule.value < 0 {
+			v.add("compliance."+rule.field, "%d is negative", rule.value)
+		} else if rule.max > 0 && rule.value > rule.max {
+			v.add("compliance."+rule.field, "%d is more than %d days", rule.value, rule.max)
+		}
+	}
+}
+
+func (c *Configuration) validateThresholds(v *validator) {
+	budgets := make(map[string]int)
+	for i, budget := range c.Thresholds.Budgets {
+		field := fmt.Sprintf("thresholds.budgets[%d]", i)
+		if (budget.ScheduleID == "") == (budget.TeamID == "") {
+			v.add(field, "set either scheduleId or teamId")
+		}
+		if budget.MonthlyAmount <= 0 {
+			v.add(field+".monthlyAmount", "%d is not positive", budget.MonthlyAmount)
+		}
+
+		key := budget.ScheduleID + "/" + budget.TeamID
+		if previous, ok := budgets[key]; ok {
+			v.add(field, "duplicate budget, already set by thresholds.budgets[%d]", previous)
+		} else {
+			budgets[key] = i
+		}
+	}
+
+	userCaps := make(map[string]int)
+	for i, userCap := range c.Thresholds.UserCaps {
+		field := fmt.Sprintf("thresholds.userCaps[%d]", i)
+		if userCap.MonthlyHours < 0 {
+			v.add(field+".monthlyHours", "%d is negative", userCap.MonthlyHours)
+		}
+		if userCap.MonthlyAmount < 0 {
+			v.add(field+".monthlyAmount", "%d is negative", userCap.MonthlyAmount)
+		}
+		if userCap.MonthlyHours == 0 && userCap.MonthlyAmount == 0 {
+			v.add(field, "set monthlyHours or monthlyAmount")
+		}
+		if previous, ok := userCaps[userCap.UserID]; ok {
+			v.add(field+".userId", "duplicate cap for user '%s', already set by thresholds.userCaps[%d]", userCap.UserID, previous)
+		} else {
+			userCaps[userCap.UserID] = i
+		}
+	}
+}
+
+func (c *Configuration) validatePayAdjustments(v *validator) {
+	for i, minimum := range c.PayAdjustments.Minimums {
+		field := fmt.Sprintf("payAdjustments.minimums[%d]", i)
+		if minimum.Per != minimumPers[0] && minimum.Per != minimumPers[1] {
+			v.add(field+".per", "unknown '%s', expected one of %s", minimum.Per, strings.Join(minimumPers, ", "))
+		}
+		if minimum.MinHours < 0 {
+			v.add(field+".minHours", "%d is negative", minimum.MinHours)
+		} else if minimum.Per == "shift" && minimum.MinHours == 0 {
+			v.add(field+".minHours", "the hours of a completed shift are required")
+		}
+		if minimum.Amount <= 0 {
+			v.add(field+".amount", "%d is not positive", minimum.Amount)
+		}
+	}
+
+	caps := make(map[string]int)
+	for i, payCap := range c.PayAdjustments.Caps {
+		field := fmt.Sprintf("payAdjustments.caps[%d]", i)
+		if payCap.Period != payCapPeriods[0] && payCap.Period != payCapPeriods[1] {
+			v.add(field+".period", "unknown period '%s', expected one of %s", payCap.Period, strings.Join(payCapPeriods, ", "))
+		}
+		if payCap.MaxAmount <= 0 {
+			v.add(field+".maxAmount", "%d is not positive", payCap.MaxAmount)
+		}
+
+		key := payCap.UserID + "/" + payCap.Period
+		if previous, ok := caps[key]; ok {
+			v.add(field, "duplicate %s cap for user '%s', already set by payAdjustments.caps[%d]", payCap.Period, payCap.UserID, previous)
+		} else {
+			caps[key] = i
+		}
+	}
+}
+
+func (c *Configuration) validateStipends(v *validator) {
+	for i, stipend := range c.Stipends {
+		field := fmt.Sprintf("stipends[%d]", i)
+		if stipend.Pattern != stipendPatterns[0] && stipend.Pattern != stipendPatterns[1] {
+			v.add(field+".pattern", "unknown pattern '%s', expected one of %s", stipend.Pattern, strings.Join(stipendPatterns, ", "))
+		}
+		if stipend.MinHours < 0 {
+			v.add(field+".minHours", "%d is negative", stipend.MinHours)
+		} else if stipend.MinHours > 7*24 {
+			v.add(field+".minHours", "%d is more than the %d hours of a week", stipend.MinHours, 7*24)
+		}
+		if stipend.Amount <= 0 {
+			v.add(field+".amount", "%d is not positive", stipend.Amount)
+		}
+	}
+}
+
+func (c *Configuration) validateExcludedHours(v *validator) {
+	for i, excludedHours := range c.RotationExcludedHours {
+		field := fmt.Sprintf("rotationExcludedHours[%d]", i)
+		if !isDayType(excludedHours.Day) {
+			v.add(field+".day", "unknown day type '%s', expected one of %s", excludedHours.Day, strings.Join(dayTypes, ", "))
+		}
+		if excludedHours.ExcludedStartsAt < 0 || excludedHours.ExcludedStartsAt > 24 {
+			v.add(field+".excludedStartsAt", "hour %d is outside 0-24", excludedHours.ExcludedStartsAt)
+		}
+		if excludedHours.ExcludedEndsAt < 0 || excludedHours.ExcludedEndsAt > 24 {
+			v.add(field+".excludedEndsAt", "hour %d is outside 0-24", excludedHours.ExcludedEndsAt)
+		}
+		if excludedHours.ExcludedStartsAt > excludedHours.ExcludedEndsAt {
+			v.add(field, "excludedStartsAt (%d) is after excludedEndsAt (%d)", excludedHours.ExcludedStartsAt, excludedHours.ExcludedEndsAt)
+		}
+	}
+}
+
+func (c *Configuration) validateRotationInfo(v *validator) {
+	if c.RotationInfo.DailyRotationStartsAt < 0 || c.RotationInfo.DailyRotationStartsAt > 23 {
+		v.add("rotationInfo.dailyRotationStartsAt", "hour %d is outside 0-23", c.RotationInfo.DailyRotationStartsAt)
+	}
+	if c.RotationInfo.CheckRotationChangeEvery <= 0 {
+		v.add("rotationInfo.checkRotationChangeEvery", "must be a positive number of minutes")
+	}
+}
+
+func (c *Configuration) validateTimeRanges(v *validator) {
+	if _, err := c.ReportLocation(); err != nil {
+		return // reported by validateTimezones
+	}
+
+	c.validateTimeRange(v, "reportTimeRange", c.ReportTimeRange.Start, c.ReportTimeRange.End, false)
+
+	for i, override := range c.ScheduleTimeRangeOverrides {
+		field := fmt.Sprintf("scheduleTimeRangeOverrides[%d]", i)
+		if override.Id == "" {
+			v.add(field+".id", "schedule id is missing")
+		}
+		c.validateTimeRange(v, field, override.Start, override.End, true)
+	}
+}
+
+func (c *Configuration) validateTimeRange(v *validator, field, start, end string, required bool) {
+	var startDate, endDate time.Time
+	var err error
+
+	if start != "" {
+		if startDate, err = c.ParseReportTime(start); err != nil {
+			v.add(field+".start", "%s", err)
+		}
+	} else if required {
+		v.add(field+".start", "start is missing")
+	}
+
+	if end != "" {
+		if endDate, err = c.ParseReportTime(end); err != nil {
+			v.add(field+".end", "%s", err)
+		}
+	} else if required {
+		v.add(field+".end", "end is missing")
+	}
+
+	if !startDate.IsZero() && !endDate.IsZero() && !startDate.Before(endDate) {
+		v.add(field, "start '%s' is not before end '%s'", start, end)
+	}
+}
+
+func (c *Configuration) validateUsers(v *validator) {
+	userIndexes := make(map[string]int)
+	for i, rotationUser := range c.RotationUsers {
+		field := fmt.Sprintf("rotationUsers[%d]", i)
+		if rotationUser.UserID == "" {
+			v.add(field+".userId", "user id is missing")
+		} else if previous, ok := userIndexes[rotationUser.UserID]; ok {
+			v.add(field+".userId", "duplicate user id '%s', already used by rotationUsers[%d]", rotationUser.UserID, previous)
+		} else {
+			userIndexes[rotationUser.UserID] = i
+		}
+
+		v.checkCalendar(field+".holidaysCalendar", rotationUser.HolidaysCalendar, true)
+		validateWeekendDays(v, field+".weekendDays", rotationUser.WeekendDays)
+
+		if rotationUser.LeaveFile != "" {
+			if _, err := os.Stat(rotationUser.LeaveFile); err != nil {
+				v.add(field+".leaveFile", "cannot read leave file: %s", err)
+			}
+		}
+	}
+}
+
+func (c *Configuration) validateCalendars(v *validator) {
+	v.checkCalendar("defaultHolidayCalendar", c.DefaultHolidayCalendar, false)
+
+	for i, calendarWeekend := range c.CalendarWeekends {
+		field := fmt.Sprintf("calendarWeekends[%d]", i)
+		v.checkCalendar(field+".calendar", calendarWeekend.Calendar, true)
+		validateWeekendDays(v, field+".weekendDays", calendarWeekend.WeekendDays)
+	}
+
+	for i, timezoneCalendar := range c.TimezoneCalendars {
+		v.checkCalendar(fmt.Sprintf("timezoneCalendars[%d].calendar", i), timezoneCalendar.Calendar, true)
+	}
+}
+
+func (v *validator) checkCalendar(field, calendar string, required bool) {
+	if calendar == "" {
+		if required {
+			v.add(field, "calendar is missing")
+		}
+		return
+	}
+
+	if !v.calendars[calendar] {
+		v.add(field, "unknown calendar '%s'", calendar)
+	}
+}
+
+func validateWeekendDays(v *validator, field string, weekendDays []WeekendDay) {
+	for i, weekendDay := range weekendDays {
+		dayField := fmt.Sprintf("%s[%d]", field, i)
+		if !isWeekday(weekendDay.Day) {
+			v.add(dayField+".day", "unknown day of the week '%s'", weekendDay.Day)
+		}
+		if weekendDay.StartsAt < 0 || weekendDay.StartsAt > 24 {
+			v.add(dayField+".startsAt", "hour %d is outside 0-24", weekendDay.StartsAt)
+		}
+		if weekendDay.EndsAt < 0 || weekendDay.EndsAt > 24 {
+			v.add(dayField+".endsAt", "hour %d is outside 0-24", weekendDay.EndsAt)
+		}
+		if weekendDay.EndsAt != 0 && weekendDay.StartsAt >= weekendDay.EndsAt {
+			v.add(dayField, "startsAt (%d) is not before endsAt (%d)", weekendDay.StartsAt, weekendDay.EndsAt)
+		}
+	}
+}
+
+func (c *Configuration) validateTimezones(v *validator) {
+	if c.DefaultUserTimezone != "" {
+		if _, err := time.LoadLocation(c.DefaultUserTimezone); err != nil {
+			v.add("defaultUserTimezone", "invalid timezone '%s'", c.DefaultUserTimezone)
+		}
+	}
+
+	if _, err := c.ReportLocation(); err != nil {
+		v.add("reportTimezone", "invalid timezone '%s'", c.ReportTimezone)
+	}
+
+	for i, timezoneCalendar := range c.TimezoneCalendars {
+		if _, err := time.LoadLocation(timezoneCalendar.Timezone); err != nil || timezoneCalendar.Timezone == "" {
+			v.add(fmt.Sprintf("timezoneCalendars[%d].timezone", i), "invalid timezone '%s'", timezoneCalendar.Timezone)
+		}
+	}
+}
+
+func (c *Configuration) validateAuthToken(v *validator) {
+	if c.PdAuthTokenFile != "" {
+		if _, err := os.Stat(c.PdAuthTokenFile); err != nil {
+			v.add("pdAuthTokenFile", "cannot read token file: %s", err)
+		}
+	}
+	if c.PdAuthTokenFile != "" && c.PdAuthTokenCommand != "" {
+		v.add("pdAuthTokenCommand", "pdAuthTokenFile is also set and takes precedence")
+	}
+}
+
+func (c *Configuration) validatePdAPI(v *validator) {
+	if c.PdAPI.BaseURL != "" {
+		if baseURL, err := url.ParseRequestURI(c.PdAPI.BaseURL); err != nil || baseURL.Host == "" {
+			v.add("pdApi.baseUrl", "invalid URL '%s'", c.PdAPI.BaseURL)
+		}
+	}
+	if c.PdAPI.Proxy != "" {
+		if proxyURL, err := url.Parse(c.PdAPI.Proxy); err != nil || proxyURL.Host == "" {
+			v.add("pdApi.proxy", "invalid URL '%s'", c.PdAPI.Proxy)
+		}
+	}
+	if c.PdAPI.CABundle != "" {
+		if _, err := os.Stat(c.PdAPI.CABundle); err != nil {
+			v.add("pdApi.caBundle", "cannot read CA bundle: %s", err)
+		}
+	}
+	if c.PdAPI.Timeout < 0 {
+		v.add("pdApi.timeout", "timeout %s is negative", c.PdAPI.Timeout)
+	}
+	if c.PdAPI.ScheduleWindowDays < 0 {
+		v.add("pdApi.scheduleWindowDays", "window of %d days is negative", c.PdAPI.ScheduleWindowDays)
+	}
+	if c.PdAPI.ScheduleConcurrency < 0 {
+		v.add("pdApi.scheduleConcurrency", "concurrency %d is negative", c.PdAPI.ScheduleConcurrency)
+	}
+}
+
+func isDayType(day string) bool {
+	for _, dayType := range dayTypes {
+		if dayType == day {
+			return true
+		}
+	}
+	return false
+}
+
+func isWeekday(day string) bool {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.EqualFold(weekday.String(), day) {
+			return true
+		}
+	}
+	return false
+}
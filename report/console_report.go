@@ -1,6 +1,7 @@
 package report
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -14,7 +15,22 @@ type consoleReport struct {
 const (
 	blankLine = ""
 	separator = " ------------------------------------------------------------------------------------------------------------------------------------------"
-	rowFormat = "| %-35s || %7v | %7v | %12v | %13v | %13v | %18v | %9v |"
+	rowFormat = "| %-35s || %7v | %7v | %12v | %8v | %13v | %13v | %18v | %14v | %9v |"
+
+	payAdjustmentRowFormat    = "| %-35s || %113v | %9v |"
+	conflictRowFormat         = "| %-35s || %-30s | %-15s | %-15s | %-30s | %-15s | %-15s |"
+	inferredCalendarRowFormat = "| %-35s || %-30s | %-20s |"
+	overrideUserRowFormat     = "| %-35s || %14v | %-60s |"
+	overrideRowFormat         = "| %-35s || %-30s | %-19s | %-19s | %8v | %-40s |"
+	calloutRowFormat          = "| %-35s || %-30s | %-40s | %-7s | %-19s | %-11s | %10v |"
+	activeTimeRowFormat       = "| %-35s || %10v | %10v | %12v | %10v |"
+	stipendRowFormat          = "| %-35s || %-30s | %8v | %10v |"
+	metricsRowFormat          = "| %-35s || %7v | %12v | %12v | %12v | %11v | %11v |"
+	complianceRowFormat       = "| %-35s || %-19s | %-19s | %-19s | %-40s |"
+	coverageRowFormat         = "| %-35s || %10v | %14v | %6v | %10v | %8v | %10v |"
+	coveragePeriodRowFormat   = "| %-35s || %-8s | %-19s | %-19s | %10v | %-40s |"
+	thresholdRowFormat        = "| %-35s || %-8s | %-15s | %-13s | %12v | %12v | %8v |"
+	forecastRowFormat         = "| %-35s || %-8s | %-20s | %14v |"
 )
 
 func NewConsoleReport(currency string) Writer {
@@ -23,10 +39,16 @@ func NewConsoleReport(currency string) Writer {
 	}
 }
 
-func (r *consoleReport) GenerateReport(data *PrintableData) (string, error) {
+func (r *consoleReport) GenerateReport(ctx context.Context, data *PrintableData) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 
 	fmt.Println(separator)
 	fmt.Println(fmt.Sprintf("| Generating report(s) from '%s' to '%s'", data.Start.Format("Mon Jan _2 15:04:05 2006"), data.End.Add(time.Second*-1).Format("Mon Jan _2 15:04:05 2006")))
+	if data.Forecast != nil {
+		fmt.Println(fmt.Sprintf("| FORECAST for the next %d month(s), projected from the current schedules, configuration and calendars", data.Forecast.Months))
+	}
 	fmt.Println(separator)
 
 	for _, scheduleData := range data.SchedulesData {
@@ -35,9 +57,9 @@ func (r *consoleReport) GenerateReport(data *PrintableData) (string, error) {
 		fmt.Println(fmt.Sprintf("| Schedule: '%s' (%s)", scheduleData.Name, scheduleData.ID))
 		fmt.Println(fmt.Sprintf("| Time Range: %s to %s", scheduleData.StartDate.Format(time.RFC822), scheduleData.EndDate.Format(time.RFC822)))
 		fmt.Println(separator)
-		fmt.Println(fmt.Sprintf(rowFormat, "USER", "WEEKDAY", "WEEKEND", "BANK HOLIDAY", "TOTAL WEEKDAY", "TOTAL WEEKEND", "TOTAL BANK HOLIDAY", "TOTAL"))
-		fmt.Println(fmt.Sprintf(rowFormat, "EMAIL", "HOURS", "HOURS", "HOURS", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT"))
-		fmt.Println(fmt.Sprintf(rowFormat, "", "DAYS", "DAYS", "DAYS", "", "", "", ""))
+		fmt.Println(fmt.Sprintf(rowFormat, "USER", "WEEKDAY", "WEEKEND", "BANK HOLIDAY", "CALLOUTS", "TOTAL WEEKDAY", "TOTAL WEEKEND", "TOTAL BANK HOLIDAY", "TOTAL CALLOUTS", "TOTAL"))
+		fmt.Println(fmt.Sprintf(rowFormat, "EMAIL", "HOURS", "HOURS", "HOURS", "", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT"))
+		fmt.Println(fmt.Sprintf(rowFormat, "", "DAYS", "DAYS", "DAYS", "", "", "", "", "", ""))
 		fmt.Println(separator)
 
 		sort.Slice(scheduleData.RotaUsers, func(i, j int) bool {
@@ -49,15 +71,18 @@ func (r *consoleReport) GenerateReport(data *PrintableData) (string, error) {
 				fmt.Sprintf("%v h", userData.NumWorkHours),
 				fmt.Sprintf("%v h", userData.NumWeekendHours),
 				fmt.Sprintf("%v h", userData.NumBankHolidaysHours),
+				userData.NumCallouts,
 				fmt.Sprintf("%s%v", r.currency, userData.TotalAmountWorkHours),
 				fmt.Sprintf("%s%v", r.currency, userData.TotalAmountWeekendHours),
 				fmt.Sprintf("%s%v", r.currency, userData.TotalAmountBankHolidaysHours),
+				fmt.Sprintf("%s%v", r.currency, userData.TotalAmountCallouts),
 				fmt.Sprintf("%s%v", r.currency, userData.TotalAmount)))
 			fmt.Println(fmt.Sprintf(rowFormat, userData.EmailAddress,
 				fmt.Sprintf("%.1f d", userData.NumWorkDays),
 				fmt.Sprintf("%.1f d", userData.NumWeekendDays),
 				fmt.Sprintf("%.1f d", userData.NumBankHolidaysDays),
-				"_____________", "_____________", "__________________", "_________"))
+				"", "_____________", "_____________", "__________________", "______________", "_________"))
+			r.printPayAdjustment(userData)
 			fmt.Println(separator)
 		}
 	}
@@ -66,9 +91,9 @@ func (r *consoleReport) GenerateReport(data *PrintableData) (string, error) {
 	fmt.Println(separator)
 	fmt.Println("| Users summary")
 	fmt.Println(separator)
-	fmt.Println(fmt.Sprintf(rowFormat, "USER", "WEEKDAY", "WEEKEND", "BANK HOLIDAY", "TOTAL WEEKDAY", "TOTAL WEEKEND", "TOTAL BANK HOLIDAY", "TOTAL"))
-	fmt.Println(fmt.Sprintf(rowFormat, "EMAIL", "HOURS", "HOURS", "HOURS", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT"))
-	fmt.Println(fmt.Sprintf(rowFormat, "", "DAYS", "DAYS", "DAYS", "", "", "", ""))
+	fmt.Println(fmt.Sprintf(rowFormat, "USER", "WEEKDAY", "WEEKEND", "BANK HOLIDAY", "CALLOUTS", "TOTAL WEEKDAY", "TOTAL WEEKEND", "TOTAL BANK HOLIDAY", "TOTAL CALLOUTS", "TOTAL"))
+	fmt.Println(fmt.Sprintf(rowFormat, "EMAIL", "HOURS", "HOURS", "HOURS", "", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT"))
+	fmt.Println(fmt.Sprintf(rowFormat, "", "DAYS", "DAYS", "DAYS", "", "", "", "", "", ""))
 	fmt.Println(separator)
 
 	sort.Slice(data.UsersSchedulesSummary, func(i, j int) bool {
@@ -80,17 +105,311 @@ func (r *consoleReport) GenerateReport(data *PrintableData) (string, error) {
 			fmt.Sprintf("%v h", userData.NumWorkHours),
 			fmt.Sprintf("%v h", userData.NumWeekendHours),
 			fmt.Sprintf("%v h", userData.NumBankHolidaysHours),
+			userData.NumCallouts,
 			fmt.Sprintf("%s%v", r.currency, userData.TotalAmountWorkHours),
 			fmt.Sprintf("%s%v", r.currency, userData.TotalAmountWeekendHours),
 			fmt.Sprintf("%s%v", r.currency, userData.TotalAmountBankHolidaysHours),
+			fmt.Sprintf("%s%v", r.currency, userData.TotalAmountCallouts),
 			fmt.Sprintf("%s%v", r.currency, userData.TotalAmount)))
 		fmt.Println(fmt.Sprintf(rowFormat, userData.EmailAddress,
 			fmt.Sprintf("%.1f d", userData.NumWorkDays),
 			fmt.Sprintf("%.1f d", userData.NumWeekendDays),
 			fmt.Sprintf("%.1f d", userData.NumBankHolidaysDays),
-			"_____________", "_____________", "__________________", "_________"))
+			"", "_____________", "_____________", "__________________", "______________", "_________"))
+		r.printPayAdjustment(userData)
 		fmt.Println(separator)
 	}
 
+	r.printForecast(data)
+	r.printOverrides(data)
+	r.printCallouts(data)
+	r.printActiveTime(data)
+	r.printStipends(data)
+	r.printMetrics(data)
+	r.printComplianceViolations(data)
+	r.printThresholdWarnings(data)
+	r.printCoverage(data)
+	r.printConflicts(data)
+	r.printInferredCalendars(data)
+
 	return "", nil
 }
+
+// printPayAdjustment prints the pay policy adjustment of the user's raw total, and the adjusted total.
+func (r *consoleReport) printPayAdjustment(userData *ScheduleUser) {
+	if userData.TotalAmountAdjustment == 0 {
+		return
+	}
+
+	fmt.Println(fmt.Sprintf(payAdjustmentRowFormat, "PAY ADJUSTMENT",
+		fmt.Sprintf("%s%v on the raw total (minimums and caps), adjusted total", r.currency, userData.TotalAmountAdjustment),
+		fmt.Sprintf("%s%v", r.currency, userData.AdjustedTotalAmount())))
+}
+
+func (r *consoleReport) printForecast(data *PrintableData) {
+	if data.Forecast == nil {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Forecast (projected cost per schedule and team)")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(forecastRowFormat, "NAME", "KIND", "ID", "TOTAL AMOUNT"))
+	fmt.Println(separator)
+
+	for _, schedule := range data.Forecast.Schedules {
+		fmt.Println(fmt.Sprintf(forecastRowFormat, schedule.Name, "schedule", schedule.ID, fmt.Sprintf("%s%v", r.currency, schedule.TotalAmount)))
+	}
+	for _, team := range data.Forecast.Teams {
+		fmt.Println(fmt.Sprintf(forecastRowFormat, team.Name, "team", team.ID, fmt.Sprintf("%s%v", r.currency, team.TotalAmount)))
+	}
+	fmt.Println(separator)
+
+	for _, warning := range data.Forecast.Warnings {
+		fmt.Println(fmt.Sprintf("| WARNING: %s", warning))
+	}
+	if len(data.Forecast.Warnings) > 0 {
+		fmt.Println(separator)
+	}
+}
+
+func (r *consoleReport) printOverrides(data *PrintableData) {
+	if len(data.Overrides) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Overrides (extra cover)")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(overrideUserRowFormat, "USER", "OVERRIDE HOURS", "COVERED FOR"))
+	fmt.Println(separator)
+
+	for _, userData := range data.UsersSchedulesSummary {
+		if userData.NumOverrideHours == 0 {
+			continue
+		}
+		fmt.Println(fmt.Sprintf(overrideUserRowFormat, userData.Name,
+			fmt.Sprintf("%v h", userData.NumOverrideHours), strings.Join(userData.CoveredFor, ", ")))
+	}
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(overrideRowFormat, "USER", "SCHEDULE", "FROM", "TO", "DURATION", "COVERED FOR"))
+	fmt.Println(separator)
+
+	for _, override := range data.Overrides {
+		fmt.Println(fmt.Sprintf(overrideRowFormat, override.UserName, override.ScheduleName,
+			override.Start.Format(time.RFC822), override.End.Format(time.RFC822),
+			fmt.Sprintf("%v h", override.Hours), strings.Join(override.CoveredFor, ", ")))
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printCallouts(data *PrintableData) {
+	if len(data.Callouts) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Callouts (incidents paging the on-call user)")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(calloutRowFormat, "USER", "SCHEDULE", "INCIDENT", "URGENCY", "PAGED AT", "DAY TYPE", "AMOUNT"))
+	fmt.Println(separator)
+
+	for _, callout := range data.Callouts {
+		fmt.Println(fmt.Sprintf(calloutRowFormat, callout.UserName, callout.ScheduleName,
+			fmt.Sprintf("#%d %s", callout.IncidentNumber, callout.IncidentTitle), callout.Urgency,
+			callout.Time.Format(time.RFC822), callout.DayType,
+			fmt.Sprintf("%s%v", r.currency, callout.Amount)))
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printActiveTime(data *PrintableData) {
+	if !hasActiveTime(data) {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Active incident time")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(activeTimeRowFormat, "USER", "WEEKDAY", "WEEKEND", "BANK HOLIDAY", "TOTAL"))
+	fmt.Println(fmt.Sprintf(activeTimeRowFormat, "", "HOURS", "HOURS", "HOURS", "AMOUNT"))
+	fmt.Println(separator)
+
+	for _, userData := range data.UsersSchedulesSummary {
+		if !userData.hasActiveTime() {
+			continue
+		}
+		fmt.Println(fmt.Sprintf(activeTimeRowFormat, userData.Name,
+			fmt.Sprintf("%.2f h", userData.NumActiveWorkHours),
+			fmt.Sprintf("%.2f h", userData.NumActiveWeekendHours),
+			fmt.Sprintf("%.2f h", userData.NumActiveBankHolidaysHours),
+			fmt.Sprintf("%s%.2f", r.currency, userData.TotalAmountActive)))
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printStipends(data *PrintableData) {
+	if !hasStipends(data) {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Stipends (flat amounts per shift pattern)")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(stipendRowFormat, "USER", "SCHEDULE", "STIPENDS", "AMOUNT"))
+	fmt.Println(separator)
+
+	for _, scheduleData := range data.SchedulesData {
+		for _, userData := range scheduleData.RotaUsers {
+			if userData.NumStipends == 0 {
+				continue
+			}
+			fmt.Println(fmt.Sprintf(stipendRowFormat, userData.Name, scheduleData.Name, userData.NumStipends,
+				fmt.Sprintf("%s%v", r.currency, userData.TotalAmountStipends)))
+		}
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printMetrics(data *PrintableData) {
+	if len(data.Metrics) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| On-call responsiveness")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(metricsRowFormat, "USER", "PAGES", "ACKNOWLEDGED", "MEAN TO ACK", "P90 TO ACK", "ESCALATIONS", "NIGHT PAGES"))
+	fmt.Println(separator)
+
+	for _, metrics := range data.Metrics {
+		fmt.Println(fmt.Sprintf(metricsRowFormat, metrics.UserName, metrics.Pages, metrics.Acknowledged,
+			fmt.Sprintf("%.1f min", metrics.MeanTimeToAckMinutes), fmt.Sprintf("%.1f min", metrics.P90TimeToAckMinutes),
+			metrics.Escalations, metrics.NightPages))
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printComplianceViolations(data *PrintableData) {
+	if len(data.ComplianceViolations) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Compliance violations (working-time rules)")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(complianceRowFormat, "USER", "RULE", "FROM", "TO", "DETAIL"))
+	fmt.Println(separator)
+
+	for _, violation := range data.ComplianceViolations {
+		fmt.Println(fmt.Sprintf(complianceRowFormat, violation.UserName, violation.Rule,
+			violation.Start.Format(time.RFC822), violation.End.Format(time.RFC822), violation.Detail))
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printThresholdWarnings(data *PrintableData) {
+	if len(data.ThresholdWarnings) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Warnings (budgets and soft caps exceeded)")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(thresholdRowFormat, "NAME", "KIND", "ID", "THRESHOLD", "LIMIT", "ACTUAL", "OVERRUN"))
+	fmt.Println(separator)
+
+	for _, warning := range data.ThresholdWarnings {
+		fmt.Println(fmt.Sprintf(thresholdRowFormat, warning.Name, warning.Kind, warning.ID, warning.Threshold,
+			warning.value(warning.Limit, r.currency), warning.value(warning.Actual, r.currency), warning.overrun()))
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printCoverage(data *PrintableData) {
+	if len(data.Coverage) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Coverage (gaps with nobody on call, overlaps with several users)")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(coverageRowFormat, "SCHEDULE", "COVERAGE", "COVERED HOURS", "GAPS", "GAP HOURS", "OVERLAPS", "OVERLAP HOURS"))
+	fmt.Println(separator)
+
+	periods := 0
+	for _, coverage := range data.Coverage {
+		fmt.Println(fmt.Sprintf(coverageRowFormat, coverage.ScheduleName,
+			fmt.Sprintf("%.2f%%", coverage.Percentage), fmt.Sprintf("%v h", coverage.CoveredHours),
+			len(coverage.Gaps), fmt.Sprintf("%v h", coveragePeriodsHours(coverage.Gaps)),
+			len(coverage.Overlaps), fmt.Sprintf("%v h", coveragePeriodsHours(coverage.Overlaps))))
+		periods += len(coverage.Gaps) + len(coverage.Overlaps)
+	}
+	fmt.Println(separator)
+	if periods == 0 {
+		return
+	}
+
+	fmt.Println(fmt.Sprintf(coveragePeriodRowFormat, "SCHEDULE", "KIND", "FROM", "TO", "DURATION", "USERS"))
+	fmt.Println(separator)
+	for _, coverage := range data.Coverage {
+		for _, gap := range coverage.Gaps {
+			fmt.Println(fmt.Sprintf(coveragePeriodRowFormat, coverage.ScheduleName, "gap",
+				gap.Start.Format(time.RFC822), gap.End.Format(time.RFC822), fmt.Sprintf("%v h", gap.Hours), ""))
+		}
+		for _, overlap := range coverage.Overlaps {
+			fmt.Println(fmt.Sprintf(coveragePeriodRowFormat, coverage.ScheduleName, "overlap",
+				overlap.Start.Format(time.RFC822), overlap.End.Format(time.RFC822), fmt.Sprintf("%v h", overlap.Hours),
+				strings.Join(overlap.UserNames, ", ")))
+		}
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printConflicts(data *PrintableData) {
+	if len(data.Conflicts) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Conflicts (on call during leave)")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(conflictRowFormat, "USER", "SCHEDULE", "ON CALL FROM", "ON CALL TO", "LEAVE", "LEAVE FROM", "LEAVE TO"))
+	fmt.Println(separator)
+
+	for _, conflict := range data.Conflicts {
+		fmt.Println(fmt.Sprintf(conflictRowFormat, conflict.UserName, conflict.ScheduleName,
+			conflict.OnCallStart.Format(time.RFC822), conflict.OnCallEnd.Format(time.RFC822),
+			conflict.LeaveTitle,
+			conflict.LeaveStart.Format(time.RFC822), conflict.LeaveEnd.Format(time.RFC822)))
+	}
+	fmt.Println(separator)
+}
+
+func (r *consoleReport) printInferredCalendars(data *PrintableData) {
+	if len(data.InferredCalendars) == 0 {
+		return
+	}
+
+	fmt.Println("")
+	fmt.Println(separator)
+	fmt.Println("| Holiday calendars inferred from user timezone")
+	fmt.Println(separator)
+	fmt.Println(fmt.Sprintf(inferredCalendarRowFormat, "USER", "TIMEZONE", "CALENDAR"))
+	fmt.Println(separator)
+
+	for _, inferredCalendar := range data.InferredCalendars {
+		fmt.Println(fmt.Sprintf(inferredCalendarRowFormat, inferredCalendar.UserName, inferredCalendar.Timezone, inferredCalendar.Calendar))
+	}
+	fmt.Println(separator)
+}
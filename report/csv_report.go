@@ -1,10 +1,10 @@
 package report
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"log"
-	"os"
 	"sort"
 	"strings"
 	"time"
@@ -13,6 +13,8 @@ import (
 type csvReport struct {
 	currency string
 	outPath  string
+
+	files *outputFiles
 }
 
 func NewCsvReport(currency string, outPath string) Writer {
@@ -22,28 +24,44 @@ func NewCsvReport(currency string, outPath string) Writer {
 	}
 }
 
-func (r *csvReport) GenerateReport(data *PrintableData) (string, error) {
+func (r *csvReport) GenerateReport(ctx context.Context, data *PrintableData) (string, error) {
+	r.files = &outputFiles{}
+	message, err := r.generateReport(ctx, data)
+	if err != nil {
+		r.files.removeAll()
+		return "", err
+	}
+	return message, nil
+}
+
+func (r *csvReport) generateReport(ctx context.Context, data *PrintableData) (string, error) {
 
 	fmt.Println(separator)
 	fmt.Println(fmt.Sprintf("| Generating report(s) from '%s' to '%s'", data.Start.Format("Mon Jan _2 15:04:05 2006"), data.End.Add(time.Second*-1).Format("Mon Jan _2 15:04:05 2006")))
+	if data.Forecast != nil {
+		fmt.Println(fmt.Sprintf("| FORECAST for the next %d month(s), projected from the current schedules, configuration and calendars", data.Forecast.Months))
+	}
 	fmt.Println(separator)
 
 	header := []string{"User", "Email",
 		"Weekday Hours", "Weekday Days", "Weekend Hours", "Weekend Days", "Bank Holiday Hours", "Bank Holiday Days",
 		"Total Weekday Amount (" + r.currency + ")", "Total Weekend Amount (" + r.currency + ")",
-		"Total Bank Holiday Amount (" + r.currency + ")", "Total  Amount (" + r.currency + ")"}
+		"Total Bank Holiday Amount (" + r.currency + ")", "Total  Amount (" + r.currency + ")",
+		"Override Hours", "Covered For", "Callouts", "Total Callouts Amount (" + r.currency + ")",
+		"Active Weekday Hours", "Active Weekend Hours", "Active Bank Holiday Hours", "Total Active Amount (" + r.currency + ")",
+		"Stipends", "Total Stipends Amount (" + r.currency + ")",
+		"Pay Adjustment (" + r.currency + ")", "Adjusted Total Amount (" + r.currency + ")"}
 
 	for _, scheduleData := range data.SchedulesData {
-		err := r.writeSingleRotation(scheduleData, data, header)
+		err := r.writeSingleRotation(ctx, scheduleData, data, header)
 		if err != nil {
 			log.Println("Error creating report for rotation: ", scheduleData.Name, " ID: ", scheduleData.ID, err)
 			return "", err
 		}
 	}
 
-	filename := fmt.Sprintf("%s/pagerduty_oncall_report.%d-%d-Summary.csv", r.outPath, data.Start.Month(), data.Start.Year())
-	_ = os.Remove(filename)
-	file, err := os.Create(filename)
+	filename := fmt.Sprintf("%s/%s.%d-%d-Summary.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
 	if err != nil {
 		log.Println("Error creating report file: ", filename, err)
 		return "", err
@@ -74,19 +92,368 @@ func (r *csvReport) GenerateReport(data *PrintableData) (string, error) {
 		log.Fatal("Error flushing writr", err)
 		return "", err
 	}
+
+	if err := r.writeForecast(ctx, data); err != nil {
+		return "", err
+	}
+	if err := r.writeOverrides(ctx, data); err != nil {
+		return "", err
+	}
+	if err := r.writeCallouts(ctx, data); err != nil {
+		return "", err
+	}
+	if err := r.writeMetrics(ctx, data); err != nil {
+		return "", err
+	}
+	if err := r.writeComplianceViolations(ctx, data); err != nil {
+		return "", err
+	}
+	if err := r.writeThresholdWarnings(ctx, data); err != nil {
+		return "", err
+	}
+	if err := r.writeCoverage(ctx, data); err != nil {
+		return "", err
+	}
+	if err := r.writeConflicts(ctx, data); err != nil {
+		return "", err
+	}
+	if err := r.writeInferredCalendars(ctx, data); err != nil {
+		return "", err
+	}
 	return fmt.Sprintf("Report successfully generated: file://%s", filename), nil
 }
 
-func (r *csvReport) writeSingleRotation(scheduleData *ScheduleData, data *PrintableData, header []string) error {
+func (r *csvReport) writeForecast(ctx context.Context, data *PrintableData) error {
+	if data.Forecast == nil {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-Forecast.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	// a row per schedule and team with its projected cost, then a row per warning
+	header := []string{"Kind", "Name", "ID", "Total Amount (" + r.currency + ")"}
+	if err := w.Write(header); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	rows := make([][]string, 0, len(data.Forecast.Schedules)+len(data.Forecast.Teams)+len(data.Forecast.Warnings))
+	for _, schedule := range data.Forecast.Schedules {
+		rows = append(rows, []string{"schedule", schedule.Name, schedule.ID, fmt.Sprintf("%v", schedule.TotalAmount)})
+	}
+	for _, team := range data.Forecast.Teams {
+		rows = append(rows, []string{"team", team.Name, team.ID, fmt.Sprintf("%v", team.TotalAmount)})
+	}
+	for _, warning := range data.Forecast.Warnings {
+		rows = append(rows, []string{"warning", warning, "", ""})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		log.Println("error writing forecast record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
+
+func (r *csvReport) writeOverrides(ctx context.Context, data *PrintableData) error {
+	if len(data.Overrides) == 0 {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-Overrides.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	header := []string{"User", "Schedule", "Schedule ID", "From", "To", "Duration Hours", "Covered For"}
+	if err := w.Write(header); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	for _, override := range data.Overrides {
+		dat := []string{override.UserName, override.ScheduleName, override.ScheduleID,
+			override.Start.Format(time.RFC3339), override.End.Format(time.RFC3339),
+			fmt.Sprintf("%v", override.Hours), strings.Join(override.CoveredFor, "; ")}
+		if err := w.Write(dat); err != nil {
+			log.Println("error writing override record to csv: ", filename, " user: ", override.UserName, " err: ", err)
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
+
+func (r *csvReport) writeCallouts(ctx context.Context, data *PrintableData) error {
+	if len(data.Callouts) == 0 {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-Callouts.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	header := []string{"User", "Schedule", "Schedule ID", "Incident ID", "Incident Number", "Incident", "Urgency",
+		"Paged At", "Day Type", "Amount (" + r.currency + ")"}
+	if err := w.Write(header); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	for _, callout := range data.Callouts {
+		dat := []string{callout.UserName, callout.ScheduleName, callout.ScheduleID,
+			callout.IncidentID, fmt.Sprintf("%d", callout.IncidentNumber), callout.IncidentTitle, callout.Urgency,
+			callout.Time.Format(time.RFC3339), callout.DayType, fmt.Sprintf("%v", callout.Amount)}
+		if err := w.Write(dat); err != nil {
+			log.Println("error writing callout record to csv: ", filename, " user: ", callout.UserName, " err: ", err)
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
+
+func (r *csvReport) writeMetrics(ctx context.Context, data *PrintableData) error {
+	if len(data.Metrics) == 0 {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-Metrics.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	header := []string{"User", "Pages", "Acknowledged", "Mean Time To Acknowledge (min)", "P90 Time To Acknowledge (min)",
+		"Escalations", "Night Pages"}
+	if err := w.Write(header); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	for _, metrics := range data.Metrics {
+		dat := []string{metrics.UserName, fmt.Sprintf("%d", metrics.Pages), fmt.Sprintf("%d", metrics.Acknowledged),
+			fmt.Sprintf("%.1f", metrics.MeanTimeToAckMinutes), fmt.Sprintf("%.1f", metrics.P90TimeToAckMinutes),
+			fmt.Sprintf("%d", metrics.Escalations), fmt.Sprintf("%d", metrics.NightPages)}
+		if err := w.Write(dat); err != nil {
+			log.Println("error writing metrics record to csv: ", filename, " user: ", metrics.UserName, " err: ", err)
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
+
+func (r *csvReport) writeComplianceViolations(ctx context.Context, data *PrintableData) error {
+	if len(data.ComplianceViolations) == 0 {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-Compliance.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	header := []string{"User", "Rule", "From", "To", "Detail"}
+	if err := w.Write(header); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	for _, violation := range data.ComplianceViolations {
+		dat := []string{violation.UserName, violation.Rule,
+			violation.Start.Format(time.RFC3339), violation.End.Format(time.RFC3339), violation.Detail}
+		if err := w.Write(dat); err != nil {
+			log.Println("error writing compliance record to csv: ", filename, " user: ", violation.UserName, " err: ", err)
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
+
+func (r *csvReport) writeThresholdWarnings(ctx context.Context, data *PrintableData) error {
+	if len(data.ThresholdWarnings) == 0 {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-Warnings.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	// limits and actuals are in hours or in the currency, depending on the threshold
+	header := []string{"Kind", "ID", "Name", "Threshold", "Limit", "Actual"}
+	if err := w.Write(header); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	for _, warning := range data.ThresholdWarnings {
+		dat := []string{warning.Kind, warning.ID, warning.Name, warning.Threshold,
+			fmt.Sprintf("%.2f", warning.Limit), fmt.Sprintf("%.2f", warning.Actual)}
+		if err := w.Write(dat); err != nil {
+			log.Println("error writing warning record to csv: ", filename, " name: ", warning.Name, " err: ", err)
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
+
+func (r *csvReport) writeCoverage(ctx context.Context, data *PrintableData) error {
+	if len(data.Coverage) == 0 {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-Coverage.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	// a row per schedule with the whole range, then a row per gap and overlap
+	header := []string{"Schedule", "Schedule ID", "Kind", "From", "To", "Hours", "Coverage (%)", "Users"}
+	if err := w.Write(header); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	for _, coverage := range data.Coverage {
+		rows := [][]string{{coverage.ScheduleName, coverage.ScheduleID, "coverage",
+			coverage.Start.Format(time.RFC3339), coverage.End.Format(time.RFC3339),
+			fmt.Sprintf("%v", coverage.CoveredHours), fmt.Sprintf("%.2f", coverage.Percentage), ""}}
+		for _, gap := range coverage.Gaps {
+			rows = append(rows, []string{coverage.ScheduleName, coverage.ScheduleID, "gap",
+				gap.Start.Format(time.RFC3339), gap.End.Format(time.RFC3339), fmt.Sprintf("%v", gap.Hours), "", ""})
+		}
+		for _, overlap := range coverage.Overlaps {
+			rows = append(rows, []string{coverage.ScheduleName, coverage.ScheduleID, "overlap",
+				overlap.Start.Format(time.RFC3339), overlap.End.Format(time.RFC3339), fmt.Sprintf("%v", overlap.Hours), "",
+				strings.Join(overlap.UserNames, "; ")})
+		}
+		if err := w.WriteAll(rows); err != nil {
+			log.Println("error writing coverage record to csv: ", filename, " schedule: ", coverage.ScheduleID, " err: ", err)
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
+
+func (r *csvReport) writeConflicts(ctx context.Context, data *PrintableData) error {
+	if len(data.Conflicts) == 0 {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-Conflicts.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	header := []string{"User", "Schedule", "Schedule ID", "On Call From", "On Call To", "Leave", "Leave From", "Leave To"}
+	if err := w.Write(header); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	for _, conflict := range data.Conflicts {
+		dat := []string{conflict.UserName, conflict.ScheduleName, conflict.ScheduleID,
+			conflict.OnCallStart.Format(time.RFC3339), conflict.OnCallEnd.Format(time.RFC3339),
+			conflict.LeaveTitle,
+			conflict.LeaveStart.Format(time.RFC3339), conflict.LeaveEnd.Format(time.RFC3339)}
+		if err := w.Write(dat); err != nil {
+			log.Println("error writing conflict record to csv: ", filename, " user: ", conflict.UserName, " err: ", err)
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
+
+func (r *csvReport) writeSingleRotation(ctx context.Context, scheduleData *ScheduleData, data *PrintableData, header []string) error {
 	fmt.Println(separator)
 	fmt.Println(fmt.Sprintf("| Writing Schedule: '%s' (%s)", scheduleData.Name, scheduleData.ID))
 	fmt.Println(fmt.Sprintf("| Time Range: %s to %s", scheduleData.StartDate.Format(time.RFC822), scheduleData.EndDate.Format(time.RFC822)))
 	fmt.Println(separator)
 	noSpaceName := strings.Replace(scheduleData.Name, " ", "_", -1)
 
-	filename := fmt.Sprintf("%s/pagerduty_oncall_report.%d-%d-%s-%s.csv", r.outPath, data.Start.Month(), data.Start.Year(), noSpaceName, scheduleData.ID)
-	_ = os.Remove(filename)
-	file, err := os.Create(filename)
+	filename := fmt.Sprintf("%s/%s.%d-%d-%s-%s.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year(), noSpaceName, scheduleData.ID)
+	file, err := r.files.create(ctx, filename)
 	if err != nil {
 		log.Println("Error creating report file: ", filename, err)
 		return err
@@ -130,10 +497,56 @@ func writeUser(userData *ScheduleUser, w *csv.Writer) error {
 		fmt.Sprintf("%v", userData.TotalAmountWorkHours),
 		fmt.Sprintf("%v", userData.TotalAmountWeekendHours),
 		fmt.Sprintf("%v", userData.TotalAmountBankHolidaysHours),
-		fmt.Sprintf("%v", userData.TotalAmount)}
+		fmt.Sprintf("%v", userData.TotalAmount),
+		fmt.Sprintf("%v", userData.NumOverrideHours),
+		strings.Join(userData.CoveredFor, "; "),
+		fmt.Sprintf("%d", userData.NumCallouts),
+		fmt.Sprintf("%v", userData.TotalAmountCallouts),
+		fmt.Sprintf("%.2f", userData.NumActiveWorkHours),
+		fmt.Sprintf("%.2f", userData.NumActiveWeekendHours),
+		fmt.Sprintf("%.2f", userData.NumActiveBankHolidaysHours),
+		fmt.Sprintf("%.2f", userData.TotalAmountActive),
+		fmt.Sprintf("%d", userData.NumStipends),
+		fmt.Sprintf("%v", userData.TotalAmountStipends),
+		fmt.Sprintf("%v", userData.TotalAmountAdjustment),
+		fmt.Sprintf("%v", userData.AdjustedTotalAmount())}
 	if err := w.Write(dat); err != nil {
 		log.Println("error writing record to csv:", err)
 		return err
 	}
 	return nil
 }
+
+func (r *csvReport) writeInferredCalendars(ctx context.Context, data *PrintableData) error {
+	if len(data.InferredCalendars) == 0 {
+		return nil
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d-InferredCalendars.csv", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	file, err := r.files.create(ctx, filename)
+	if err != nil {
+		log.Println("Error creating report file: ", filename, err)
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+
+	if err := w.Write([]string{"User", "Timezone", "Calendar"}); err != nil {
+		log.Println("error writing record to csv: ", filename, " err: ", err)
+		return err
+	}
+
+	for _, inferredCalendar := range data.InferredCalendars {
+		if err := w.Write([]string{inferredCalendar.UserName, inferredCalendar.Timezone, inferredCalendar.Calendar}); err != nil {
+			log.Println("error writing inferred calendar record to csv: ", filename, " user: ", inferredCalendar.UserName, " err: ", err)
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	log.Println(fmt.Sprintf("Report successfully generated: file://%s", filename))
+	return nil
+}
@@ -0,0 +1,47 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type jsonReport struct {
+	currency string
+	outPath  string
+}
+
+// jsonData is the report data with the currency of its amounts.
+type jsonData struct {
+	Currency string
+	*PrintableData
+}
+
+func NewJSONReport(currency string, outPath string) Writer {
+	return &jsonReport{
+		currency: currency,
+		outPath:  outPath,
+	}
+}
+
+func (r *jsonReport) GenerateReport(ctx context.Context, data *PrintableData) (string, error) {
+	content, err := json.MarshalIndent(jsonData{Currency: r.currency, PrintableData: data}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	filename := fmt.Sprintf("%s/%s.%d-%d.json", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	files := &outputFiles{}
+	file, err := files.create(ctx, filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(content, '\n')); err != nil {
+		files.removeAll()
+		return "", err
+	}
+
+	return fmt.Sprintf("Report successfully generated: file://%s", filename), nil
+}
@@ -0,0 +1,36 @@
+package report
+
+import (
+	"context"
+	"log"
+	"os"
+)
+
+// outputFiles keeps track of the files written for a report, to remove them when it fails or is cancelled.
+type outputFiles struct {
+	paths []string
+}
+
+// create replaces the file unless the context is done.
+func (o *outputFiles) create(ctx context.Context, path string) (*os.File, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_ = os.Remove(path)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	o.paths = append(o.paths, path)
+	return file, nil
+}
+
+func (o *outputFiles) removeAll() {
+	for _, path := range o.paths {
+		if err := os.Remove(path); err == nil {
+			log.Println("Removed partially written report file:", path)
+		}
+	}
+	o.paths = nil
+}
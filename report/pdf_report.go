@@ -1,9 +1,9 @@
 package report
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"os"
 	"time"
 
 	"sort"
@@ -14,7 +14,21 @@ import (
 )
 
 const (
-	matrixRowFormat = "%-40s %8v %8v %10v %8v %8v %12v %10v"
+	matrixRowFormat      = "%-30s %8v %8v %10v %8v %8v %8v %10v %8v %10v"
+	payAdjustmentFormat  = "%-30s %74v %10v"
+	conflictMatrixFormat = "%-30s %-30s %-15s %-15s %-25s"
+	inferredMatrixFormat = "%-40s %-30s %-20s"
+	overrideUserFormat   = "%-40s %14v %-50s"
+	overrideMatrixFormat = "%-25s %-25s %-15s %-15s %8v %-25s"
+	calloutMatrixFormat  = "%-25s %-25s %-30s %-7s %-14s %-11s %8v"
+	activeTimeFormat     = "%-40s %10v %10v %12v %10v"
+	stipendFormat        = "%-40s %-30s %8v %10v"
+	metricsMatrixFormat  = "%-40s %7v %12v %12v %12v %11v %11v"
+	complianceFormat     = "%-25s %-20s %-14s %-14s %-45s"
+	coverageFormat       = "%-30s %10v %14v %6v %10v %8v %13v"
+	coveragePeriodFormat = "%-30s %-8s %-14s %-14s %10v %-35s"
+	thresholdFormat      = "%-30s %-8s %-12s %-13s %12v %12v %8v"
+	forecastFormat       = "%-40s %-8s %-20s %14v"
 )
 
 type pdfReport struct {
@@ -29,7 +43,7 @@ func NewPDFReport(currency string, outPath string) Writer {
 	}
 }
 
-func (r *pdfReport) GenerateReport(data *PrintableData) (string, error) {
+func (r *pdfReport) GenerateReport(ctx context.Context, data *PrintableData) (string, error) {
 
 	log.Println("Generating pdf report...")
 	log.Println("  -> Schedules:")
@@ -45,8 +59,12 @@ func (r *pdfReport) GenerateReport(data *PrintableData) (string, error) {
 		//pdf.Image(example.ImageFile("logo.png"), 10, 6, 30, 0, false, "", 0, "")
 		pdf.SetY(5)
 		pdf.SetFont("Arial", "B", 15)
+		title := "PagerDuty oncall report(s)"
+		if data.Forecast != nil {
+			title = "PagerDuty oncall FORECAST"
+		}
 		pdf.CellFormat(0, 10,
-			fmt.Sprintf("PagerDuty oncall report(s) from %s to %s ", data.Start.Format("02/01/2006"), data.End.Add(time.Second*-1).Format("02/01/2006")),
+			fmt.Sprintf("%s from %s to %s ", title, data.Start.Format("02/01/2006"), data.End.Add(time.Second*-1).Format("02/01/2006")),
 			"R", 0, "R", false, 0, "")
 		pdf.Ln(20)
 	})
@@ -72,15 +90,15 @@ func (r *pdfReport) GenerateReport(data *PrintableData) (string, error) {
 
 		pdf.SetFont("Courier", "B", 8)
 		pdf.CellFormat(0, 5,
-			fmt.Sprintf(matrixRowFormat, "USER", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "TOTAL"),
+			fmt.Sprintf(matrixRowFormat, "USER", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "CALLOUTS", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "CALLOUTS", "TOTAL"),
 			"", 0, "L", false, 0, "")
 		pdf.Ln(3)
 		pdf.CellFormat(0, 5,
-			fmt.Sprintf(matrixRowFormat, "EMAIL", "HOURS", "HOURS", "HOURS", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT"),
+			fmt.Sprintf(matrixRowFormat, "EMAIL", "HOURS", "HOURS", "HOURS", "", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT"),
 			"", 0, "L", false, 0, "")
 		pdf.Ln(3)
 		pdf.CellFormat(0, 5,
-			fmt.Sprintf(matrixRowFormat, "", "DAYS", "DAYS", "DAYS", "", "", "", ""),
+			fmt.Sprintf(matrixRowFormat, "", "DAYS", "DAYS", "DAYS", "", "", "", "", "", ""),
 			"B", 0, "L", false, 0, "")
 		pdf.Ln(5)
 
@@ -96,9 +114,11 @@ func (r *pdfReport) GenerateReport(data *PrintableData) (string, error) {
 					fmt.Sprintf("%v h", userData.NumWorkHours),
 					fmt.Sprintf("%v h", userData.NumWeekendHours),
 					fmt.Sprintf("%v h", userData.NumBankHolidaysHours),
+					userData.NumCallouts,
 					tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountWorkHours)),
 					tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountWeekendHours)),
 					tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountBankHolidaysHours)),
+					tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountCallouts)),
 					tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmount))),
 				"", 0, "L", false, 0, "")
 			pdf.Ln(3)
@@ -107,9 +127,10 @@ func (r *pdfReport) GenerateReport(data *PrintableData) (string, error) {
 					fmt.Sprintf("%.1f d", userData.NumWorkDays),
 					fmt.Sprintf("%.1f d", userData.NumWeekendDays),
 					fmt.Sprintf("%.1f d", userData.NumBankHolidaysDays),
-					"", "", "", ""),
+					"", "", "", "", "", ""),
 				"B", 0, "L", false, 0, "")
 			pdf.Ln(5)
+			r.writePayAdjustment(pdf, tr, userData)
 		}
 
 		pdf.Ln(10)
@@ -124,15 +145,15 @@ func (r *pdfReport) GenerateReport(data *PrintableData) (string, error) {
 
 	pdf.SetFont("Courier", "B", 8)
 	pdf.CellFormat(0, 5,
-		fmt.Sprintf(matrixRowFormat, "USER", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "TOTAL"),
+		fmt.Sprintf(matrixRowFormat, "USER", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "CALLOUTS", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "CALLOUTS", "TOTAL"),
 		"", 0, "L", false, 0, "")
 	pdf.Ln(3)
 	pdf.CellFormat(0, 5,
-		fmt.Sprintf(matrixRowFormat, "EMAIL", "HOURS", "HOURS", "HOURS", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT"),
+		fmt.Sprintf(matrixRowFormat, "EMAIL", "HOURS", "HOURS", "HOURS", "", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT", "AMOUNT"),
 		"", 0, "L", false, 0, "")
 	pdf.Ln(3)
 	pdf.CellFormat(0, 5,
-		fmt.Sprintf(matrixRowFormat, "", "DAYS", "DAYS", "DAYS", "", "", "", ""),
+		fmt.Sprintf(matrixRowFormat, "", "DAYS", "DAYS", "DAYS", "", "", "", "", "", ""),
 		"B", 0, "L", false, 0, "")
 	pdf.Ln(5)
 
@@ -147,9 +168,11 @@ func (r *pdfReport) GenerateReport(data *PrintableData) (string, error) {
 				fmt.Sprintf("%v h", userData.NumWorkHours),
 				fmt.Sprintf("%v h", userData.NumWeekendHours),
 				fmt.Sprintf("%v h", userData.NumBankHolidaysHours),
+				userData.NumCallouts,
 				tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountWorkHours)),
 				tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountWeekendHours)),
 				tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountBankHolidaysHours)),
+				tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountCallouts)),
 				tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmount))),
 			"", 0, "L", false, 0, "")
 		pdf.Ln(3)
@@ -158,18 +181,437 @@ func (r *pdfReport) GenerateReport(data *PrintableData) (string, error) {
 				fmt.Sprintf("%.1f d", userData.NumWorkDays),
 				fmt.Sprintf("%.1f d", userData.NumWeekendDays),
 				fmt.Sprintf("%.1f d", userData.NumBankHolidaysDays),
-				"", "", "", ""),
+				"", "", "", "", "", ""),
 			"B", 0, "L", false, 0, "")
 		pdf.Ln(5)
+		r.writePayAdjustment(pdf, tr, userData)
 	}
 
-	filename := fmt.Sprintf("%s/pagerduty_oncall_report.%d-%d.pdf", r.outPath, data.Start.Month(), data.Start.Year())
-	_ = os.Remove(filename)
+	r.writeForecast(pdf, tr, data)
+	r.writeOverrides(pdf, tr, data)
+	r.writeCallouts(pdf, tr, data)
+	r.writeActiveTime(pdf, tr, data)
+	r.writeStipends(pdf, tr, data)
+	r.writeMetrics(pdf, tr, data)
+	r.writeComplianceViolations(pdf, tr, data)
+	r.writeThresholdWarnings(pdf, tr, data)
+	r.writeCoverage(pdf, tr, data)
+	r.writeConflicts(pdf, tr, data)
+	r.writeInferredCalendars(pdf, tr, data)
 
-	err := pdf.OutputFileAndClose(filename)
+	filename := fmt.Sprintf("%s/%s.%d-%d.pdf", r.outPath, reportName(data), data.Start.Month(), data.Start.Year())
+	files := &outputFiles{}
+	file, err := files.create(ctx, filename)
 	if err != nil {
 		return "", err
 	}
 
+	if err := pdf.OutputAndClose(file); err != nil {
+		files.removeAll()
+		return "", err
+	}
+
 	return fmt.Sprintf("Report successfully generated: file://%s", filename), nil
 }
+
+// writePayAdjustment writes the pay policy adjustment of the user's raw total, and the adjusted total.
+func (r *pdfReport) writePayAdjustment(pdf *gofpdf.Fpdf, tr func(string) string, userData *ScheduleUser) {
+	if userData.TotalAmountAdjustment == 0 {
+		return
+	}
+
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(payAdjustmentFormat, "PAY ADJUSTMENT",
+			tr(fmt.Sprintf("%s%v on the raw total (minimums and caps), adjusted total", r.currency, userData.TotalAmountAdjustment)),
+			tr(fmt.Sprintf("%s%v", r.currency, userData.AdjustedTotalAmount()))),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+}
+
+func (r *pdfReport) writeForecast(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if data.Forecast == nil {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf("  Forecast for the next %d month(s), projected from the current schedules, configuration and calendars", data.Forecast.Months),
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5, fmt.Sprintf(forecastFormat, "NAME", "KIND", "ID", "TOTAL AMOUNT"), "B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, schedule := range data.Forecast.Schedules {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(forecastFormat, tr(schedule.Name), "schedule", schedule.ID, tr(fmt.Sprintf("%s%v", r.currency, schedule.TotalAmount))),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+	for _, team := range data.Forecast.Teams {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(forecastFormat, tr(team.Name), "team", team.ID, tr(fmt.Sprintf("%s%v", r.currency, team.TotalAmount))),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+
+	for _, warning := range data.Forecast.Warnings {
+		pdf.Ln(5)
+		pdf.CellFormat(0, 5, tr(fmt.Sprintf("WARNING: %s", warning)), "", 0, "L", false, 0, "")
+	}
+}
+
+func (r *pdfReport) writeOverrides(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if len(data.Overrides) == 0 {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Overrides (extra cover)",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(overrideUserFormat, "USER", "OVERRIDE HOURS", "COVERED FOR"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, userData := range data.UsersSchedulesSummary {
+		if userData.NumOverrideHours == 0 {
+			continue
+		}
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(overrideUserFormat, tr(userData.Name),
+				fmt.Sprintf("%v h", userData.NumOverrideHours), tr(strings.Join(userData.CoveredFor, ", "))),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+	pdf.Ln(10)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(overrideMatrixFormat, "USER", "SCHEDULE", "FROM", "TO", "DURATION", "COVERED FOR"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, override := range data.Overrides {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(overrideMatrixFormat, tr(override.UserName), tr(override.ScheduleName),
+				override.Start.Format("02/01/06 15:04"), override.End.Format("02/01/06 15:04"),
+				fmt.Sprintf("%v h", override.Hours), tr(strings.Join(override.CoveredFor, ", "))),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+}
+
+func (r *pdfReport) writeActiveTime(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if !hasActiveTime(data) {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Active incident time",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(activeTimeFormat, "USER", "WEEKDAY", "WEEKEND", "B. HOLIDAY", "TOTAL"),
+		"", 0, "L", false, 0, "")
+	pdf.Ln(3)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(activeTimeFormat, "", "HOURS", "HOURS", "HOURS", "AMOUNT"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, userData := range data.UsersSchedulesSummary {
+		if !userData.hasActiveTime() {
+			continue
+		}
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(activeTimeFormat, tr(userData.Name),
+				fmt.Sprintf("%.2f h", userData.NumActiveWorkHours),
+				fmt.Sprintf("%.2f h", userData.NumActiveWeekendHours),
+				fmt.Sprintf("%.2f h", userData.NumActiveBankHolidaysHours),
+				tr(fmt.Sprintf("%s%.2f", r.currency, userData.TotalAmountActive))),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+}
+
+func (r *pdfReport) writeStipends(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if !hasStipends(data) {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Stipends (flat amounts per shift pattern)",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(stipendFormat, "USER", "SCHEDULE", "STIPENDS", "AMOUNT"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, scheduleData := range data.SchedulesData {
+		for _, userData := range scheduleData.RotaUsers {
+			if userData.NumStipends == 0 {
+				continue
+			}
+			pdf.CellFormat(0, 5,
+				fmt.Sprintf(stipendFormat, tr(userData.Name), tr(scheduleData.Name), userData.NumStipends,
+					tr(fmt.Sprintf("%s%v", r.currency, userData.TotalAmountStipends))),
+				"B", 0, "L", false, 0, "")
+			pdf.Ln(5)
+		}
+	}
+}
+
+func (r *pdfReport) writeCallouts(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if len(data.Callouts) == 0 {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Callouts (incidents paging the on-call user)",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(calloutMatrixFormat, "USER", "SCHEDULE", "INCIDENT", "URGENCY", "PAGED AT", "DAY TYPE", "AMOUNT"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, callout := range data.Callouts {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(calloutMatrixFormat, tr(callout.UserName), tr(callout.ScheduleName),
+				tr(fmt.Sprintf("#%d %s", callout.IncidentNumber, callout.IncidentTitle)), callout.Urgency,
+				callout.Time.Format("02/01/06 15:04"), callout.DayType,
+				tr(fmt.Sprintf("%s%v", r.currency, callout.Amount))),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+}
+
+func (r *pdfReport) writeMetrics(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if len(data.Metrics) == 0 {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  On-call responsiveness",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(metricsMatrixFormat, "USER", "PAGES", "ACKNOWLEDGED", "MEAN TO ACK", "P90 TO ACK", "ESCALATIONS", "NIGHT PAGES"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, metrics := range data.Metrics {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(metricsMatrixFormat, tr(metrics.UserName), metrics.Pages, metrics.Acknowledged,
+				fmt.Sprintf("%.1f min", metrics.MeanTimeToAckMinutes), fmt.Sprintf("%.1f min", metrics.P90TimeToAckMinutes),
+				metrics.Escalations, metrics.NightPages),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+}
+
+func (r *pdfReport) writeComplianceViolations(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if len(data.ComplianceViolations) == 0 {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Compliance violations (working-time rules)",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(complianceFormat, "USER", "RULE", "FROM", "TO", "DETAIL"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, violation := range data.ComplianceViolations {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(complianceFormat, tr(violation.UserName), violation.Rule,
+				violation.Start.Format("02/01/06 15:04"), violation.End.Format("02/01/06 15:04"), violation.Detail),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+}
+
+func (r *pdfReport) writeThresholdWarnings(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if len(data.ThresholdWarnings) == 0 {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Warnings (budgets and soft caps exceeded)",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(thresholdFormat, "NAME", "KIND", "ID", "THRESHOLD", "LIMIT", "ACTUAL", "OVERRUN"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, warning := range data.ThresholdWarnings {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(thresholdFormat, tr(warning.Name), warning.Kind, warning.ID, warning.Threshold,
+				tr(warning.value(warning.Limit, r.currency)), tr(warning.value(warning.Actual, r.currency)), warning.overrun()),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+}
+
+func (r *pdfReport) writeCoverage(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if len(data.Coverage) == 0 {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Coverage (gaps with nobody on call, overlaps with several users)",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(coverageFormat, "SCHEDULE", "COVERAGE", "COVERED HOURS", "GAPS", "GAP HOURS", "OVERLAPS", "OVERLAP HOURS"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	periods := 0
+	for _, coverage := range data.Coverage {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(coverageFormat, tr(coverage.ScheduleName),
+				fmt.Sprintf("%.2f%%", coverage.Percentage), fmt.Sprintf("%v h", coverage.CoveredHours),
+				len(coverage.Gaps), fmt.Sprintf("%v h", coveragePeriodsHours(coverage.Gaps)),
+				len(coverage.Overlaps), fmt.Sprintf("%v h", coveragePeriodsHours(coverage.Overlaps))),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+		periods += len(coverage.Gaps) + len(coverage.Overlaps)
+	}
+	if periods == 0 {
+		return
+	}
+	pdf.Ln(10)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(coveragePeriodFormat, "SCHEDULE", "KIND", "FROM", "TO", "DURATION", "USERS"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, coverage := range data.Coverage {
+		for _, gap := range coverage.Gaps {
+			pdf.CellFormat(0, 5,
+				fmt.Sprintf(coveragePeriodFormat, tr(coverage.ScheduleName), "gap",
+					gap.Start.Format("02/01/06 15:04"), gap.End.Format("02/01/06 15:04"), fmt.Sprintf("%v h", gap.Hours), ""),
+				"B", 0, "L", false, 0, "")
+			pdf.Ln(5)
+		}
+		for _, overlap := range coverage.Overlaps {
+			pdf.CellFormat(0, 5,
+				fmt.Sprintf(coveragePeriodFormat, tr(coverage.ScheduleName), "overlap",
+					overlap.Start.Format("02/01/06 15:04"), overlap.End.Format("02/01/06 15:04"), fmt.Sprintf("%v h", overlap.Hours),
+					tr(strings.Join(overlap.UserNames, ", "))),
+				"B", 0, "L", false, 0, "")
+			pdf.Ln(5)
+		}
+	}
+}
+
+func (r *pdfReport) writeConflicts(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if len(data.Conflicts) == 0 {
+		return
+	}
+
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Conflicts (on call during leave)",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(conflictMatrixFormat, "USER", "SCHEDULE", "ON CALL FROM", "ON CALL TO", "LEAVE"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, conflict := range data.Conflicts {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(conflictMatrixFormat, tr(conflict.UserName), tr(conflict.ScheduleName),
+				conflict.OnCallStart.Format("02/01/06 15:04"), conflict.OnCallEnd.Format("02/01/06 15:04"),
+				tr(fmt.Sprintf("%s (%s - %s)", conflict.LeaveTitle,
+					conflict.LeaveStart.Format("02/01/06"), conflict.LeaveEnd.Add(time.Second*-1).Format("02/01/06")))),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+}
+
+func (r *pdfReport) writeInferredCalendars(pdf *gofpdf.Fpdf, tr func(string) string, data *PrintableData) {
+	if len(data.InferredCalendars) == 0 {
+		return
+	}
+
+	pdf.Ln(10)
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 5, "  Holiday calendars inferred from user timezone",
+		"L", 0, "L", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.SetFont("Courier", "B", 8)
+	pdf.CellFormat(0, 5,
+		fmt.Sprintf(inferredMatrixFormat, "USER", "TIMEZONE", "CALENDAR"),
+		"B", 0, "L", false, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetFont("Courier", "", 8)
+	for _, inferredCalendar := range data.InferredCalendars {
+		pdf.CellFormat(0, 5,
+			fmt.Sprintf(inferredMatrixFormat, tr(inferredCalendar.UserName), inferredCalendar.Timezone, inferredCalendar.Calendar),
+			"B", 0, "L", false, 0, "")
+		pdf.Ln(5)
+	}
+}
@@ -1,6 +1,8 @@
 package report
 
 import (
+	"context"
+	"fmt"
 	"time"
 )
 
@@ -9,6 +11,15 @@ type PrintableData struct {
 	End                   time.Time
 	SchedulesData         []*ScheduleData
 	UsersSchedulesSummary []*ScheduleUser
+	Conflicts             []*Conflict
+	InferredCalendars     []*InferredCalendar
+	Overrides             []*Override
+	Callouts              []*Callout
+	Metrics               []*UserMetrics
+	ComplianceViolations  []*ComplianceViolation
+	ThresholdWarnings     []*ThresholdWarning
+	Coverage              []*Coverage
+	Forecast              *Forecast
 }
 
 type ScheduleData struct {
@@ -32,8 +43,193 @@ type ScheduleUser struct {
 	NumBankHolidaysDays          float32
 	TotalAmountBankHolidaysHours float32
 	TotalAmount                  float32
+	// NumOverrideHours is the wall-clock time taken from schedule overrides, CoveredFor the users replaced
+	NumOverrideHours float32
+	CoveredFor       []string
+	// NumCallouts are the incidents attributed to the user, paid TotalAmountCallouts (included in TotalAmount)
+	NumCallouts         int
+	TotalAmountCallouts float32
+	// NumActive*Hours are the hours engaged in incidents by day type, paid TotalAmountActive (included in TotalAmount)
+	NumActiveWorkHours         float32
+	NumActiveWeekendHours      float32
+	NumActiveBankHolidaysHours float32
+	TotalAmountActive          float32
+	// NumStipends are the shift patterns matched by the user, paid TotalAmountStipends (included in TotalAmount)
+	NumStipends         int
+	TotalAmountStipends float32
+	// TotalAmountAdjustment is the pay policy adjustment, minimums top-ups and caps reductions, not included
+	// in the raw TotalAmount
+	TotalAmountAdjustment float32
+}
+
+// AdjustedTotalAmount is the pay of the user after the pay policy adjustment.
+func (u *ScheduleUser) AdjustedTotalAmount() float32 {
+	return u.TotalAmount + u.TotalAmountAdjustment
+}
+
+func (u *ScheduleUser) hasActiveTime() bool {
+	return u.NumActiveWorkHours+u.NumActiveWeekendHours+u.NumActiveBankHolidaysHours > 0
+}
+
+func hasActiveTime(data *PrintableData) bool {
+	for _, userData := range data.UsersSchedulesSummary {
+		if userData.hasActiveTime() {
+			return true
+		}
+	}
+	return false
+}
+
+func hasStipends(data *PrintableData) bool {
+	for _, userData := range data.UsersSchedulesSummary {
+		if userData.NumStipends > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// reportName is the prefix of the report files, forecasts never overwrite the reports of the same months.
+func reportName(data *PrintableData) string {
+	if data.Forecast != nil {
+		return "pagerduty_oncall_forecast"
+	}
+	return "pagerduty_oncall_report"
+}
+
+func coveragePeriodsHours(periods []*CoveragePeriod) float32 {
+	var hours float32
+	for _, period := range periods {
+		hours += period.Hours
+	}
+	return hours
+}
+
+// Conflict is an on-call period overlapping a leave of the user on call.
+type Conflict struct {
+	ScheduleID   string
+	ScheduleName string
+	UserName     string
+	OnCallStart  time.Time
+	OnCallEnd    time.Time
+	LeaveTitle   string
+	LeaveStart   time.Time
+	LeaveEnd     time.Time
+}
+
+// Override is an on-call period taken by a user over the schedule's regular rota.
+type Override struct {
+	ScheduleID   string
+	ScheduleName string
+	UserName     string
+	Start        time.Time
+	End          time.Time
+	Hours        float32
+	CoveredFor   []string
+}
+
+// Callout is an incident paging a user on call, priced by the day type and incident urgency.
+type Callout struct {
+	ScheduleID     string
+	ScheduleName   string
+	UserName       string
+	IncidentID     string
+	IncidentNumber uint
+	IncidentTitle  string
+	Urgency        string
+	Time           time.Time
+	DayType        string
+	Amount         float32
+}
+
+// ComplianceViolation is a working-time rule broken by a user's on-call periods across all the schedules,
+// between Start and End.
+type ComplianceViolation struct {
+	UserName string
+	Rule     string
+	Start    time.Time
+	End      time.Time
+	Detail   string
+}
+
+// ThresholdWarning is a budget or a soft cap of a schedule, a team or a user exceeded over the report range,
+// Limit is the monthly threshold prorated to the range.
+type ThresholdWarning struct {
+	Kind      string
+	ID        string
+	Name      string
+	Threshold string
+	Limit     float32
+	Actual    float32
+}
+
+// value formats an hours or amount value of the threshold.
+func (w *ThresholdWarning) value(value float32, currency string) string {
+	if w.Threshold == "monthlyHours" {
+		return fmt.Sprintf("%.2f h", value)
+	}
+	return fmt.Sprintf("%s%.2f", currency, value)
+}
+
+// overrun is the percentage of the actual value above the limit.
+func (w *ThresholdWarning) overrun() string {
+	return fmt.Sprintf("%.1f%%", (w.Actual/w.Limit-1)*100)
+}
+
+// Coverage is how the rendered entries of a schedule cover its report range.
+type Coverage struct {
+	ScheduleID   string
+	ScheduleName string
+	Start        time.Time
+	End          time.Time
+	CoveredHours float32
+	Percentage   float32
+	Gaps         []*CoveragePeriod
+	Overlaps     []*CoveragePeriod
+}
+
+// CoveragePeriod is a time of a schedule with nobody on call, or several users on call at once.
+type CoveragePeriod struct {
+	Start     time.Time
+	End       time.Time
+	Hours     float32
+	UserNames []string
+}
+
+// InferredCalendar is a holidays calendar assigned to a user from the user's timezone.
+type InferredCalendar struct {
+	UserName string
+	Timezone string
+	Calendar string
+}
+
+// UserMetrics is how an on-call user responded to the incidents paging them. The times to acknowledge are
+// in minutes, over the acknowledged pages.
+type UserMetrics struct {
+	UserName             string
+	Pages                int
+	Acknowledged         int
+	MeanTimeToAckMinutes float32
+	P90TimeToAckMinutes  float32
+	Escalations          int
+	NightPages           int
 }
 
 type Writer interface {
-	GenerateReport(data *PrintableData) (string, error)
+	GenerateReport(ctx context.Context, data *PrintableData) (string, error)
+}
+
+// Forecast marks the report data as projected: future schedules priced with the current configuration and calendars.
+type Forecast struct {
+	Months    int
+	Schedules []*ProjectedCost
+	Teams     []*ProjectedCost
+	Warnings  []string
+}
+
+// ProjectedCost is the forecast cost of a schedule or a team.
+type ProjectedCost struct {
+	ID          string
+	Name        string
+	TotalAmount float32
 }
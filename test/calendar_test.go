@@ -0,0 +1,40 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/test/stages"
+)
+
+func TestPartialDayBankHolidays(t *testing.T) {
+	given, when, then := stages.CalendarTest(t)
+
+	given.
+		ACalendarWithPartialDayHolidays()
+
+	when.
+		ItIsParsed()
+
+	then.
+		TheDateIsNotABankHoliday("24 Dec 26 13:30 UTC").And().
+		TheDateIsABankHoliday("24 Dec 26 14:00 UTC").And().
+		TheDateIsABankHoliday("24 Dec 26 23:30 UTC").And().
+		TheDateIsABankHoliday("25 Dec 26 00:00 UTC").And().
+		TheDateIsNotABankHoliday("28 Dec 26 08:00 UTC").And().
+		TheDateIsABankHoliday("28 Dec 26 08:30 UTC").And().
+		TheDateIsNotABankHoliday("28 Dec 26 12:00 UTC").And().
+		TheDateIsNotABankHoliday("29 Dec 26 10:00 UTC")
+}
+
+func TestMalformedPartialDayBankHoliday(t *testing.T) {
+	given, when, then := stages.CalendarTest(t)
+
+	given.
+		AMalformedCalendar()
+
+	when.
+		ItIsParsed()
+
+	then.
+		CalendarErrorIsCreated()
+}
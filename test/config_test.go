@@ -3,6 +3,7 @@ package test
 import (
 	"testing"
 
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/test/stages"
 )
 
@@ -32,6 +33,136 @@ func TestFindNonExistingPriceByDay(t *testing.T) {
 		ValueIsNotFound()
 }
 
+func TestCalloutPriceForIncidentUrgency(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		TheCalloutPriceIsRequested("weekday", "low")
+
+	then.
+		TheValueIs(20)
+}
+
+func TestCalloutPriceWithoutUrgency(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		TheCalloutPriceIsRequested("weekend", "high")
+
+	then.
+		TheValueIs(75)
+}
+
+func TestMissingCalloutPrice(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		TheCalloutPriceIsRequested("bankholiday", "high")
+
+	then.
+		ValueIsNotFound()
+}
+
+func TestActiveRateByDay(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		TheActiveRateIsRequested("weekend")
+
+	then.
+		TheValueIs(45)
+}
+
+func TestMissingActiveRate(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		TheActiveRateIsRequested("bankholiday")
+
+	then.
+		ValueIsNotFound()
+}
+
+func TestUserCapById(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		TheUserCapIsRequested("ABCDEF2")
+
+	then.
+		TheValueIs(configuration.UserCap{UserID: "ABCDEF2", MonthlyHours: 300, MonthlyAmount: 900})
+}
+
+func TestDefaultUserCap(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		TheUserCapIsRequested("ABCDEF1")
+
+	then.
+		TheValueIs(configuration.UserCap{MonthlyHours: 240})
+}
+
+func TestPayCapByIdAndPeriod(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		ThePayCapIsRequested("ABCDEF2", "week")
+
+	then.
+		TheValueIs(configuration.PayCap{UserID: "ABCDEF2", Period: "week", MaxAmount: 600})
+}
+
+func TestDefaultPayCap(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		ThePayCapIsRequested("ABCDEF1", "month")
+
+	then.
+		TheValueIs(configuration.PayCap{Period: "month", MaxAmount: 1500})
+}
+
+func TestMissingPayCap(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		ThePayCapIsRequested("ABCDEF1", "week")
+
+	then.
+		ValueIsNotFound()
+}
+
 func TestFindExistingRotationUserInfoById(t *testing.T) {
 	given, when, then := stages.ConfigTest(t)
 
@@ -70,3 +201,227 @@ func TestConfigurationMalformed(t *testing.T) {
 	then.
 		ConfigErrorIsCreated()
 }
+
+func TestWeekendDaysFromCalendar(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithCustomWeekends().And().ItIsLoaded()
+
+	when.
+		TheWeekendDaysAreRequestedForUser("ABCDEF1")
+
+	then.
+		TheDateIsAWeekend("04 Sep 26 10:00 UTC").And().
+		TheDateIsAWeekend("05 Sep 26 10:00 UTC").And().
+		TheDateIsNotAWeekend("06 Sep 26 10:00 UTC")
+}
+
+func TestWeekendDaysFromRotationUser(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithCustomWeekends().And().ItIsLoaded()
+
+	when.
+		TheWeekendDaysAreRequestedForUser("ABCDEF2")
+
+	then.
+		TheDateIsNotAWeekend("05 Sep 26 10:00 UTC").And().
+		TheDateIsAWeekend("05 Sep 26 12:00 UTC").And().
+		TheDateIsAWeekend("06 Sep 26 10:00 UTC")
+}
+
+func TestDefaultWeekendDays(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithCustomWeekends().And().ItIsLoaded()
+
+	when.
+		TheWeekendDaysAreRequestedForUser("ABCDEF3")
+
+	then.
+		TheDateIsNotAWeekend("04 Sep 26 10:00 UTC").And().
+		TheDateIsAWeekend("05 Sep 26 10:00 UTC").And().
+		TheDateIsAWeekend("06 Sep 26 10:00 UTC")
+}
+
+func TestHolidaysCalendarInferredFromTimezone(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithTimezoneCalendars().And().ItIsLoaded()
+
+	when.
+		TheRotationUserIsRequestedWithTimezone("NOT_CONFIGURED", "Australia/Sydney")
+
+	then.
+		TheHolidaysCalendarIs("au_nsw").And().
+		TheHolidaysCalendarIsInferredFrom("Australia/Sydney")
+}
+
+func TestConfiguredHolidaysCalendarIsNotInferred(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithTimezoneCalendars().And().ItIsLoaded()
+
+	when.
+		TheRotationUserIsRequestedWithTimezone("ABCDEF1", "Europe/Madrid")
+
+	then.
+		TheHolidaysCalendarIs("sp_premia").And().
+		TheHolidaysCalendarIsInferredFrom("")
+}
+
+func TestUnmappedTimezoneFallsBackToDefaultHolidaysCalendar(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithTimezoneCalendars().And().ItIsLoaded()
+
+	when.
+		TheRotationUserIsRequestedWithTimezone("NOT_CONFIGURED", "America/New_York")
+
+	then.
+		TheHolidaysCalendarIs("uk").And().
+		TheHolidaysCalendarIsInferredFrom("")
+}
+
+func TestValidConfigurationHasNoProblems(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AValidConfigurationCorrectlyLoaded()
+
+	when.
+		ItIsValidated()
+
+	then.
+		NoProblemsAreReported()
+}
+
+func TestConfigurationValidationReportsEveryProblem(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithSeveralProblems().And().ItIsLoaded()
+
+	when.
+		ItIsValidated()
+
+	then.
+		TheProblemsAreReportedFor(
+			"rotationPrices.daysInfo",
+			"rotationExcludedHours[0]",
+			"rotationExcludedHours[1].excludedEndsAt",
+			"reportTimeRange",
+			"scheduleTimeRangeOverrides[0].end",
+			"rotationUsers[1].userId",
+			"rotationUsers[1].holidaysCalendar",
+			"defaultUserTimezone",
+			"pdApi.baseUrl",
+			"pdApi.caBundle",
+			"calloutPrices[1].urgency",
+			"calloutPrices[2].day",
+			"calloutPrices[3]",
+			"activeRates[0].price",
+			"activeRates[1].day",
+			"compliance.minRestHours",
+			"compliance.maxDaysPer7Days",
+			"thresholds.budgets[0]",
+			"thresholds.budgets[0].monthlyAmount",
+			"thresholds.userCaps[0].monthlyHours",
+			"payAdjustments.minimums[0].per",
+			"payAdjustments.minimums[1].minHours",
+			"payAdjustments.caps[1]",
+			"stipends[0].pattern",
+			"stipends[1].minHours",
+		)
+}
+
+func TestReportTimeRangeInReportTimezone(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithReportTimezone().And().ItIsLoaded()
+
+	when.
+		TheReportStartIsParsed()
+
+	then.
+		TheParsedTimeIs("2026-08-31T22:00:00Z")
+
+	when.
+		TheReportEndIsParsed()
+
+	then.
+		TheParsedTimeIs("2026-09-30T22:00:00Z")
+}
+
+func TestReportTimeFormats(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithReportTimezone().And().ItIsLoaded()
+
+	when.
+		TheReportTimeIsParsed("01 Sep 26 08:00 UTC")
+	then.
+		TheParsedTimeIs("2026-09-01T08:00:00Z")
+
+	when.
+		TheReportTimeIsParsed("2026-09-01T08:00:00+01:00")
+	then.
+		TheParsedTimeIs("2026-09-01T07:00:00Z")
+
+	when.
+		TheReportTimeIsParsed("2026-09-01T08:00:00")
+	then.
+		TheParsedTimeIs("2026-09-01T06:00:00Z")
+
+	when.
+		TheReportTimeIsParsed("01/09/2026")
+	then.
+		TheParsedTimeIsInvalid()
+}
+
+func TestAuthTokenFromFile(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithTokenFile().And().ItIsLoaded()
+
+	when.
+		TheAuthTokenIsRequested()
+
+	then.
+		TheAuthTokenIs("token-from-file")
+}
+
+func TestAuthTokenFromCommand(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithTokenCommand().And().ItIsLoaded()
+
+	when.
+		TheAuthTokenIsRequested()
+
+	then.
+		TheAuthTokenIs("token-from-command")
+}
+
+func TestAuthTokenIsMissing(t *testing.T) {
+	given, when, then := stages.ConfigTest(t)
+
+	given.
+		AConfigurationWithoutToken().And().ItIsLoaded()
+
+	when.
+		TheAuthTokenIsRequested()
+
+	then.
+		TheAuthTokenIsNotFound()
+}
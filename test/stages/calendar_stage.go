@@ -0,0 +1,90 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+type CalendarStage struct {
+	t *testing.T
+
+	calendarRaw   []byte
+	calendar      *configuration.BHCalendar
+	calendarError error
+}
+
+func CalendarTest(t *testing.T) (*CalendarStage, *CalendarStage, *CalendarStage) {
+	stage := &CalendarStage{
+		t: t,
+	}
+
+	return stage, stage, stage
+}
+
+func (s *CalendarStage) And() *CalendarStage {
+	return s
+}
+
+func (s *CalendarStage) ACalendarWithPartialDayHolidays() *CalendarStage {
+	s.calendarRaw = []byte(`
+- title: "Christmas Eve"
+  date: 24/12/2026
+  start: "14:00"
+- title: "Christmas Day"
+  date: 25/12/2026
+- title: "Morning holiday"
+  date: 28/12/2026
+  start: "08:30"
+  end: "12:00"
+`)
+	return s
+}
+
+func (s *CalendarStage) AMalformedCalendar() *CalendarStage {
+	s.calendarRaw = []byte(`
+- title: "Christmas Eve"
+  date: 24/12/2026
+  start: "2pm"
+`)
+	return s
+}
+
+func (s *CalendarStage) ItIsParsed() *CalendarStage {
+	var bankHolidays []configuration.BankHoliday
+	s.calendarError = yaml.Unmarshal(s.calendarRaw, &bankHolidays)
+	if s.calendarError != nil {
+		return s
+	}
+
+	s.calendar = &configuration.BHCalendar{DaysMaps: map[string]configuration.BankHoliday{}}
+	for _, bh := range bankHolidays {
+		s.calendar.DaysMaps[bh.Date.ToHashKey()] = bh
+	}
+	return s
+}
+
+func (s *CalendarStage) TheDateIsABankHoliday(date string) *CalendarStage {
+	assert.True(s.t, s.isBankHoliday(date), "%s should be a bank holiday", date)
+	return s
+}
+
+func (s *CalendarStage) TheDateIsNotABankHoliday(date string) *CalendarStage {
+	assert.False(s.t, s.isBankHoliday(date), "%s should not be a bank holiday", date)
+	return s
+}
+
+func (s *CalendarStage) CalendarErrorIsCreated() *CalendarStage {
+	assert.NotNil(s.t, s.calendarError)
+	return s
+}
+
+func (s *CalendarStage) isBankHoliday(date string) bool {
+	parsedDate, err := time.Parse(time.RFC822, date)
+	assert.Nil(s.t, err)
+	return s.calendar.IsDateBankHoliday(parsedDate)
+}
@@ -2,7 +2,11 @@ package stages
 
 import (
 	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/form3tech-oss/go-pagerduty-oncall-report/configuration"
 
@@ -20,6 +24,16 @@ type ConfigStage struct {
 
 	mapValue interface{}
 	mapError error
+
+	weekendDays []configuration.WeekendDay
+
+	validationErrors []configuration.ValidationError
+
+	parsedTime      time.Time
+	parsedTimeError error
+
+	authToken      string
+	authTokenError error
 }
 
 func ConfigTest(t *testing.T) (*ConfigStage, *ConfigStage, *ConfigStage) {
@@ -49,6 +63,56 @@ rotationPrices:
     price: 1
   - day: bankholiday
     price: 2
+calloutPrices:
+  - day: weekday
+    price: 50
+  - day: weekday
+    urgency: low
+    price: 20
+  - day: weekend
+    price: 75
+activeRates:
+  - day: weekday
+    price: 30
+  - day: weekend
+    price: 45
+compliance:
+  maxConsecutiveHours: 168
+  minRestHours: 12
+  maxDaysPer7Days: 7
+  maxDaysPer30Days: 16
+thresholds:
+  budgets:
+    - scheduleId: SCHED_4
+      monthlyAmount: 2000
+    - teamId: TEAM_1
+      monthlyAmount: 5000
+  userCaps:
+    - monthlyHours: 240
+    - userId: ABCDEF2
+      monthlyHours: 300
+      monthlyAmount: 900
+payAdjustments:
+  minimums:
+    - per: shift
+      minHours: 168
+      amount: 150
+  caps:
+    - period: month
+      maxAmount: 1500
+    - userId: ABCDEF2
+      period: month
+      maxAmount: 2000
+    - userId: ABCDEF2
+      period: week
+      maxAmount: 600
+stipends:
+  - pattern: fullWeekend
+    amount: 100
+    scheduleIds: [SCHED_4]
+  - pattern: isoWeek
+    minHours: 120
+    amount: 250
 rotationUsers:
   - name: "User 1"
     holidaysCalendar: uk
@@ -64,6 +128,175 @@ schedulesToIgnore:
 	return s
 }
 
+func (s *ConfigStage) AConfigurationWithCustomWeekends() *ConfigStage {
+	s.configRaw = []byte(`
+rotationPrices:
+  currency: £
+  daysInfo:
+  - day: weekday
+    price: 1
+  - day: weekend
+    price: 1
+  - day: bankholiday
+    price: 2
+calendarWeekends:
+  - calendar: ae
+    weekendDays:
+    - day: friday
+    - day: saturday
+rotationUsers:
+  - name: "User 1"
+    holidaysCalendar: ae
+    userId: ABCDEF1
+  - name: "User 2"
+    holidaysCalendar: uk
+    userId: ABCDEF2
+    weekendDays:
+    - day: saturday
+      startsAt: 12
+    - day: sunday
+  - name: "User 3"
+    holidaysCalendar: uk
+    userId: ABCDEF3
+`)
+	return s
+}
+
+func (s *ConfigStage) AConfigurationWithTimezoneCalendars() *ConfigStage {
+	s.configRaw = []byte(`
+defaultHolidayCalendar: uk
+rotationUsers:
+  - name: "User 1"
+    holidaysCalendar: sp_premia
+    userId: ABCDEF1
+timezoneCalendars:
+  - timezone: Australia/Sydney
+    calendar: au_nsw
+  - timezone: Europe/Madrid
+    calendar: sp
+`)
+	return s
+}
+
+func (s *ConfigStage) AConfigurationWithTokenFile() *ConfigStage {
+	tokenFile := filepath.Join(s.t.TempDir(), "token")
+	assert.Nil(s.t, os.WriteFile(tokenFile, []byte("token-from-file\n"), 0600))
+
+	s.configRaw = []byte(fmt.Sprintf(`
+pdAuthTokenFile: %s
+pdAuthTokenCommand: echo token-from-command
+`, tokenFile))
+	return s
+}
+
+func (s *ConfigStage) AConfigurationWithTokenCommand() *ConfigStage {
+	s.configRaw = []byte(`
+pdAuthTokenCommand: echo token-from-command
+`)
+	return s
+}
+
+func (s *ConfigStage) AConfigurationWithoutToken() *ConfigStage {
+	s.configRaw = []byte(`
+defaultHolidayCalendar: uk
+`)
+	return s
+}
+
+func (s *ConfigStage) AConfigurationWithSeveralProblems() *ConfigStage {
+	s.configRaw = []byte(`
+reportTimeRange:
+  start: 01 Feb 26 00:00 UTC
+  end: 01 Jan 26 00:00 UTC
+rotationInfo:
+  dailyRotationStartsAt: 8
+  checkRotationChangeEvery: 30
+defaultUserTimezone: Europe/Londres
+rotationExcludedHours:
+  - day: weekday
+    excludedStartsAt: 17
+    excludedEndsAt: 9
+  - day: weekend
+    excludedStartsAt: 0
+    excludedEndsAt: 25
+rotationPrices:
+  currency: £
+  daysInfo:
+  - day: weekday
+    price: 1
+  - day: weekend
+    price: 1
+calloutPrices:
+  - day: weekday
+    price: 50
+  - day: weekday
+    urgency: urgent
+    price: 20
+  - day: holiday
+    price: 75
+  - day: weekday
+    price: 60
+activeRates:
+  - day: weekday
+    price: -30
+  - day: weekday
+    price: 30
+compliance:
+  minRestHours: -12
+  maxDaysPer7Days: 8
+thresholds:
+  budgets:
+    - scheduleId: SCHED_4
+      teamId: TEAM_1
+      monthlyAmount: 0
+  userCaps:
+    - userId: ABCDEF1
+      monthlyHours: -10
+payAdjustments:
+  minimums:
+    - per: week
+      amount: 100
+    - per: shift
+      amount: 150
+  caps:
+    - period: month
+      maxAmount: 1500
+    - period: month
+      maxAmount: 1200
+stipends:
+  - pattern: weekend
+    amount: 100
+  - pattern: isoWeek
+    minHours: 200
+    amount: 250
+rotationUsers:
+  - name: "User 1"
+    holidaysCalendar: uk
+    userId: ABCDEF1
+  - name: "User 2"
+    holidaysCalendar: narnia
+    userId: ABCDEF1
+scheduleTimeRangeOverrides:
+  - id: SCHED_1
+    start: 01 Jan 26 00:00 UTC
+    end: tomorrow
+pdApi:
+  baseUrl: api.eu.pagerduty.com
+  caBundle: /nonexistent/ca.pem
+`)
+	return s
+}
+
+func (s *ConfigStage) AConfigurationWithReportTimezone() *ConfigStage {
+	s.configRaw = []byte(`
+reportTimezone: Europe/Madrid
+reportTimeRange:
+  start: 2026-09-01
+  end: 2026-10
+`)
+	return s
+}
+
 func (s *ConfigStage) AMalformedConfiguration() *ConfigStage {
 	s.configRaw = []byte(`
 pdAuthToken: abcdefghijklm
@@ -105,7 +338,7 @@ func (s *ConfigStage) ItIsLoaded() *ConfigStage {
 	s.configError = viper.ReadConfig(bytes.NewBuffer(s.configRaw))
 	if s.configError == nil {
 		s.config = configuration.New()
-		s.configUnmarshalError = viper.Unmarshal(s.config)
+		s.configUnmarshalError = viper.Unmarshal(s.config, viper.DecodeHook(configuration.TimeToStringHookFunc()))
 	}
 	return s
 }
@@ -115,6 +348,52 @@ func (s *ConfigStage) AnExistingPriceIsRequested() *ConfigStage {
 	return s
 }
 
+func (s *ConfigStage) TheCalloutPriceIsRequested(dayType, urgency string) *ConfigStage {
+	price, found := s.config.FindCalloutPrice(dayType, urgency)
+	if found {
+		s.mapValue = price
+	} else {
+		s.mapError = fmt.Errorf("no callout price for %s %s", dayType, urgency)
+	}
+	return s
+}
+
+func (s *ConfigStage) TheActiveRateIsRequested(dayType string) *ConfigStage {
+	price, found := s.config.FindActiveRate(dayType)
+	if found {
+		s.mapValue = price
+	} else {
+		s.mapError = fmt.Errorf("no active rate for %s", dayType)
+	}
+	return s
+}
+
+func (s *ConfigStage) TheUserCapIsRequested(userID string) *ConfigStage {
+	userCap, found := s.config.FindUserCap(userID)
+	if found {
+		s.mapValue = *userCap
+	} else {
+		s.mapError = fmt.Errorf("no cap for user %s", userID)
+	}
+	return s
+}
+
+func (s *ConfigStage) ThePayCapIsRequested(userID, period string) *ConfigStage {
+	payCap, found := s.config.FindPayCap(userID, period)
+	if found {
+		s.mapValue = *payCap
+	} else {
+		s.mapError = fmt.Errorf("no %s pay cap for user %s", period, userID)
+	}
+	return s
+}
+
+func (s *ConfigStage) TheValueIs(expected interface{}) *ConfigStage {
+	assert.Nil(s.t, s.mapError)
+	assert.Equal(s.t, expected, s.mapValue)
+	return s
+}
+
 func (s *ConfigStage) ANonExistingPriceIsRequested() *ConfigStage {
 	s.mapValue, s.mapError = s.config.FindPriceByDay("wokday")
 	return s
@@ -130,6 +409,107 @@ func (s *ConfigStage) ANonExistingRotationInfoIsRequested() *ConfigStage {
 	return s
 }
 
+func (s *ConfigStage) TheWeekendDaysAreRequestedForUser(userID string) *ConfigStage {
+	rotationUser, err := s.config.FindRotationUserInfoByID(userID)
+	assert.Nil(s.t, err)
+	s.weekendDays = s.config.FindWeekendDays(rotationUser)
+	return s
+}
+
+func (s *ConfigStage) TheDateIsAWeekend(date string) *ConfigStage {
+	assert.True(s.t, s.isWeekend(date), "%s should be a weekend", date)
+	return s
+}
+
+func (s *ConfigStage) TheDateIsNotAWeekend(date string) *ConfigStage {
+	assert.False(s.t, s.isWeekend(date), "%s should not be a weekend", date)
+	return s
+}
+
+func (s *ConfigStage) isWeekend(date string) bool {
+	parsedDate, err := time.Parse(time.RFC822, date)
+	assert.Nil(s.t, err)
+	calendar := configuration.BHCalendar{WeekendDays: s.weekendDays}
+	return calendar.IsWeekend(parsedDate)
+}
+
+func (s *ConfigStage) TheRotationUserIsRequestedWithTimezone(userID, timezone string) *ConfigStage {
+	s.mapValue, s.mapError = s.config.FindRotationUserInfoByIDAndTimezone(userID, timezone)
+	return s
+}
+
+func (s *ConfigStage) TheHolidaysCalendarIs(calendar string) *ConfigStage {
+	assert.Nil(s.t, s.mapError)
+	assert.Equal(s.t, calendar, s.mapValue.(*configuration.RotationUser).HolidaysCalendar)
+	return s
+}
+
+func (s *ConfigStage) TheHolidaysCalendarIsInferredFrom(timezone string) *ConfigStage {
+	assert.Equal(s.t, timezone, s.mapValue.(*configuration.RotationUser).InferredFromTimezone)
+	return s
+}
+
+func (s *ConfigStage) ItIsValidated() *ConfigStage {
+	s.validationErrors = s.config.Validate([]string{"uk", "sp"})
+	return s
+}
+
+func (s *ConfigStage) NoProblemsAreReported() *ConfigStage {
+	assert.Empty(s.t, s.validationErrors)
+	return s
+}
+
+func (s *ConfigStage) TheProblemsAreReportedFor(fields ...string) *ConfigStage {
+	reportedFields := make([]string, 0, len(s.validationErrors))
+	for _, validationError := range s.validationErrors {
+		reportedFields = append(reportedFields, validationError.Field)
+	}
+	assert.ElementsMatch(s.t, fields, reportedFields)
+	return s
+}
+
+func (s *ConfigStage) TheReportTimeIsParsed(value string) *ConfigStage {
+	s.parsedTime, s.parsedTimeError = s.config.ParseReportTime(value)
+	return s
+}
+
+func (s *ConfigStage) TheReportStartIsParsed() *ConfigStage {
+	return s.TheReportTimeIsParsed(s.config.ReportTimeRange.Start)
+}
+
+func (s *ConfigStage) TheReportEndIsParsed() *ConfigStage {
+	return s.TheReportTimeIsParsed(s.config.ReportTimeRange.End)
+}
+
+func (s *ConfigStage) TheParsedTimeIs(expected string) *ConfigStage {
+	assert.Nil(s.t, s.parsedTimeError)
+	expectedTime, err := time.Parse(time.RFC3339, expected)
+	assert.Nil(s.t, err)
+	assert.True(s.t, expectedTime.Equal(s.parsedTime), "expected %s, got %s", expectedTime, s.parsedTime)
+	return s
+}
+
+func (s *ConfigStage) TheParsedTimeIsInvalid() *ConfigStage {
+	assert.NotNil(s.t, s.parsedTimeError)
+	return s
+}
+
+func (s *ConfigStage) TheAuthTokenIsRequested() *ConfigStage {
+	s.authToken, s.authTokenError = s.config.AuthToken()
+	return s
+}
+
+func (s *ConfigStage) TheAuthTokenIs(expected string) *ConfigStage {
+	assert.Nil(s.t, s.authTokenError)
+	assert.Equal(s.t, expected, s.authToken)
+	return s
+}
+
+func (s *ConfigStage) TheAuthTokenIsNotFound() *ConfigStage {
+	assert.NotNil(s.t, s.authTokenError)
+	return s
+}
+
 func (s *ConfigStage) ValueIsFound() *ConfigStage {
 	assert.Nil(s.t, s.mapError)
 	assert.NotNil(s.t, s.mapValue)